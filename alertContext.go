@@ -0,0 +1,317 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"go.uber.org/zap"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	envPrefix                 = "OPENFERO_"
+	annotationEnvPrefix       = envPrefix + "ANNOTATION_"
+	groupLabelEnvPrefix       = envPrefix + "GROUP_LABEL_"
+	commonLabelEnvPrefix      = envPrefix + "COMMON_LABEL_"
+	commonAnnotationEnvPrefix = envPrefix + "COMMON_ANNOTATION_"
+	alertFileEnv              = envPrefix + "ALERT_FILE"
+	alertsFileEnv             = envPrefix + "ALERTS_FILE"
+	groupFileEnv              = envPrefix + "GROUP_FILE"
+	alertCountEnv             = envPrefix + "ALERT_COUNT"
+	alertFileVolume           = "openfero-alert"
+	alertFileKey              = "alert.json"
+	alertsFileKey             = "alerts.json"
+	groupFileKey              = "group.json"
+	traceparentEnv            = envPrefix + "TRACEPARENT"
+)
+
+// builtinEnvNames are the variables set by OpenFero, labels must not override them
+var builtinEnvNames = []string{
+	envPrefix + "ALERT_STATUS", envPrefix + "STARTS_AT", envPrefix + "ENDS_AT", envPrefix + "GENERATOR_URL",
+	envPrefix + "FINGERPRINT", envPrefix + "GROUP_KEY", envPrefix + "GROUP_STATUS", envPrefix + "RECEIVER",
+	envPrefix + "EXTERNAL_URL", envPrefix + "TRUNCATED_ALERTS", alertCountEnv, alertFileEnv, alertsFileEnv,
+	groupFileEnv, traceparentEnv,
+}
+
+// builtinEnvPrefixes are the prefixes of variables set by OpenFero, labels must not shadow them
+var builtinEnvPrefixes = []string{annotationEnvPrefix, groupLabelEnvPrefix, commonLabelEnvPrefix, commonAnnotationEnvPrefix, stepEnvPrefix}
+
+// alertContext is the alert data passed to a job: the alert and the Alertmanager notification it was received with.
+// Jobs per group receive all alerts of the notification instead of a single alert.
+type alertContext struct {
+	group  *hookMessage
+	alert  alert
+	alerts []alert
+}
+
+// perGroup reports whether the job is created for all alerts of the notification
+func (data alertContext) perGroup() bool {
+	return data.alerts != nil
+}
+
+// alertFile is the alert JSON delivered to a job via a generated ConfigMap or Secret named like the job
+type alertFile struct {
+	kind string
+	data map[string]string
+}
+
+// envName converts a label or annotation key into an environment variable name,
+// e.g. app.kubernetes.io/name becomes APP_KUBERNETES_IO_NAME
+func envName(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		default:
+			return '_'
+		}
+	}, key)
+}
+
+// alertEnvVars returns the alert and its notification group as environment variables.
+// Jobs per group receive the common labels and annotations and the number of alerts instead of a single alert.
+func alertEnvVars(data alertContext) []v1.EnvVar {
+	var env []v1.EnvVar
+	if data.perGroup() {
+		if data.group != nil {
+			env = labelEnvVars(env, data.group.CommonLabels)
+			env = mapEnvVars(env, annotationEnvPrefix, data.group.CommonAnnotations)
+		}
+		env = append(env, v1.EnvVar{Name: alertCountEnv, Value: strconv.Itoa(len(data.alerts))})
+	} else {
+		alert := data.alert
+		env = labelEnvVars(env, alert.Labels)
+		env = mapEnvVars(env, annotationEnvPrefix, alert.Annotations)
+		env = appendEnvVars(env,
+			v1.EnvVar{Name: envPrefix + "ALERT_STATUS", Value: alert.Status},
+			v1.EnvVar{Name: envPrefix + "STARTS_AT", Value: alert.StartsAt},
+			v1.EnvVar{Name: envPrefix + "ENDS_AT", Value: alert.EndsAt},
+			v1.EnvVar{Name: envPrefix + "GENERATOR_URL", Value: alert.GeneratorURL},
+			v1.EnvVar{Name: envPrefix + "FINGERPRINT", Value: alert.Fingerprint},
+		)
+	}
+
+	group := data.group
+	if group == nil {
+		return env
+	}
+	env = appendEnvVars(env,
+		v1.EnvVar{Name: envPrefix + "GROUP_KEY", Value: group.GroupKey},
+		v1.EnvVar{Name: envPrefix + "GROUP_STATUS", Value: group.Status},
+		v1.EnvVar{Name: envPrefix + "RECEIVER", Value: group.Receiver},
+		v1.EnvVar{Name: envPrefix + "EXTERNAL_URL", Value: group.ExternalURL},
+		v1.EnvVar{Name: envPrefix + "TRUNCATED_ALERTS", Value: strconv.Itoa(group.TruncatedAlerts)},
+	)
+	env = mapEnvVars(env, groupLabelEnvPrefix, group.GroupLabels)
+	env = mapEnvVars(env, commonLabelEnvPrefix, group.CommonLabels)
+	return mapEnvVars(env, commonAnnotationEnvPrefix, group.CommonAnnotations)
+}
+
+// labelEnvVars appends a variable OPENFERO_<LABEL> for every label. Labels whose variable would override or
+// shadow a variable of OPENFERO, e.g. alert_status or annotation_summary, are left out.
+func labelEnvVars(env []v1.EnvVar, labels map[string]string) []v1.EnvVar {
+	for _, key := range sortedKeys(labels) {
+		name := envPrefix + envName(key)
+		if isBuiltinEnvName(name) {
+			log.Subsystem(log.SubsystemJobs).Warn("Label is not passed as environment variable, its name is used by OpenFero",
+				zap.String("label", key), zap.String("variable", name))
+			continue
+		}
+		env = append(env, v1.EnvVar{Name: name, Value: labels[key]})
+	}
+	return env
+}
+
+func isBuiltinEnvName(name string) bool {
+	if slices.Contains(builtinEnvNames, name) {
+		return true
+	}
+	return slices.ContainsFunc(builtinEnvPrefixes, func(prefix string) bool { return strings.HasPrefix(name, prefix) })
+}
+
+// mapEnvVars appends an environment variable for every entry of values, sorted by key
+func mapEnvVars(env []v1.EnvVar, prefix string, values map[string]string) []v1.EnvVar {
+	for _, key := range sortedKeys(values) {
+		env = append(env, v1.EnvVar{Name: prefix + envName(key), Value: values[key]})
+	}
+	return env
+}
+
+// appendEnvVars appends all variables with a value
+func appendEnvVars(env []v1.EnvVar, vars ...v1.EnvVar) []v1.EnvVar {
+	for _, envVar := range vars {
+		if envVar.Value != "" {
+			env = append(env, envVar)
+		}
+	}
+	return env
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// injectAlertContext adds the alert context to the containers selected by the injection settings.
+// If an alert file is configured, the generated ConfigMap or Secret is mounted into these containers.
+func injectAlertContext(jobObject *batchv1.Job, data alertContext, injection config.Injection) {
+	env := alertEnvVars(data)
+	var mount *v1.VolumeMount
+	if hasAlertFile(injection) {
+		mount = &v1.VolumeMount{Name: alertFileVolume, MountPath: injection.MountPath, ReadOnly: true}
+		if data.perGroup() {
+			env = append(env, v1.EnvVar{Name: alertsFileEnv, Value: path.Join(injection.MountPath, alertsFileKey)})
+		} else {
+			env = append(env, v1.EnvVar{Name: alertFileEnv, Value: path.Join(injection.MountPath, alertFileKey)})
+		}
+		env = append(env, v1.EnvVar{Name: groupFileEnv, Value: path.Join(injection.MountPath, groupFileKey)})
+	}
+
+	podSpec := &jobObject.Spec.Template.Spec
+	inject := func(container *v1.Container) {
+		container.Env = append(container.Env, env...)
+		if mount != nil {
+			container.VolumeMounts = append(container.VolumeMounts, *mount)
+		}
+	}
+	for i := range podSpec.Containers {
+		if len(injection.Containers) == 0 || slices.Contains(injection.Containers, podSpec.Containers[i].Name) {
+			inject(&podSpec.Containers[i])
+		}
+	}
+	if injection.InitContainers != nil && *injection.InitContainers {
+		for i := range podSpec.InitContainers {
+			inject(&podSpec.InitContainers[i])
+		}
+	}
+
+	if mount != nil {
+		podSpec.Volumes = append(podSpec.Volumes, alertFileVolumeSource(jobObject.Name, injection.AlertFile))
+	}
+}
+
+func hasAlertFile(injection config.Injection) bool {
+	return injection.AlertFile == config.AlertFileConfigMap || injection.AlertFile == config.AlertFileSecret
+}
+
+// alertFileVolumeSource projects the generated ConfigMap or Secret named like the job into a volume
+func alertFileVolumeSource(name string, kind string) v1.Volume {
+	projection := v1.VolumeProjection{}
+	if kind == config.AlertFileSecret {
+		projection.Secret = &v1.SecretProjection{LocalObjectReference: v1.LocalObjectReference{Name: name}}
+	} else {
+		projection.ConfigMap = &v1.ConfigMapProjection{LocalObjectReference: v1.LocalObjectReference{Name: name}}
+	}
+	return v1.Volume{
+		Name: alertFileVolume,
+		VolumeSource: v1.VolumeSource{
+			Projected: &v1.ProjectedVolumeSource{Sources: []v1.VolumeProjection{projection}},
+		},
+	}
+}
+
+// webhookAlert is an alert in the format of the Alertmanager webhook, which the alert file follows.
+// alert keeps the key EndsAt of the responses of /alerts and /alertStore instead.
+type webhookAlert struct {
+	Status       string            `json:"status,omitempty"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     string            `json:"startsAt,omitempty"`
+	EndsAt       string            `json:"endsAt,omitempty"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+	Fingerprint  string            `json:"fingerprint,omitempty"`
+}
+
+// newAlertFile returns the content of the alert file, or nil if no alert file is configured.
+// It holds the alert, or all alerts for jobs per group, and the notification group without its alerts.
+func newAlertFile(data alertContext, injection config.Injection) (*alertFile, error) {
+	if !hasAlertFile(injection) {
+		return nil, nil
+	}
+	alertKey, alertValue := alertFileKey, interface{}(webhookAlert(data.alert))
+	if data.perGroup() {
+		alerts := make([]webhookAlert, 0, len(data.alerts))
+		for _, alert := range data.alerts {
+			alerts = append(alerts, webhookAlert(alert))
+		}
+		alertKey, alertValue = alertsFileKey, alerts
+	}
+	alertJSON, err := json.Marshal(alertValue)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding alert file: %w", err)
+	}
+	group := hookMessage{}
+	if data.group != nil {
+		group = *data.group
+		group.Alerts = nil
+	}
+	groupJSON, err := json.Marshal(group)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding group file: %w", err)
+	}
+	return &alertFile{kind: injection.AlertFile, data: map[string]string{
+		alertKey:     string(alertJSON),
+		groupFileKey: string(groupJSON),
+	}}, nil
+}
+
+// createAlertFile creates the ConfigMap or Secret of the alert file. It is owned by the job,
+// so Kubernetes deletes it together with the job. The pod waits until the volume can be mounted.
+func (server *clientsetStruct) createAlertFile(ctx context.Context, job *batchv1.Job, file *alertFile) error {
+	objectMeta := metav1.ObjectMeta{
+		Name:      job.Name,
+		Namespace: server.jobDestinationNamespace,
+		Labels:    map[string]string{"app": "openfero"},
+		OwnerReferences: []metav1.OwnerReference{{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+			Name:       job.Name,
+			UID:        job.UID,
+		}},
+	}
+
+	var err error
+	if file.kind == config.AlertFileSecret {
+		_, err = server.clientset.CoreV1().Secrets(server.jobDestinationNamespace).Create(ctx, &v1.Secret{ObjectMeta: objectMeta, StringData: file.data}, metav1.CreateOptions{})
+	} else {
+		_, err = server.clientset.CoreV1().ConfigMaps(server.jobDestinationNamespace).Create(ctx, &v1.ConfigMap{ObjectMeta: objectMeta, Data: file.data}, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("error creating alert file %s: %w", file.kind, err)
+	}
+	log.FromContext(ctx).Debug("Alert file created", zap.String("kind", file.kind))
+	return nil
+}
+
+// groupAlerts returns the index of the first alert with the alertname and status and all alerts with them
+func groupAlerts(group *hookMessage, alertname string, status string) (int, []alert) {
+	first := -1
+	var alerts []alert
+	for i, alert := range group.Alerts {
+		if sanitizeInput(alert.Labels["alertname"]) != alertname || alertStatus(group, alert, status) != status {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		alerts = append(alerts, alert)
+	}
+	return first, alerts
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/OpenFero/openfero/pkg/config"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+)
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{name: "Simple", key: "alertname", want: "ALERTNAME"},
+		{name: "Dots and slashes", key: "app.kubernetes.io/name", want: "APP_KUBERNETES_IO_NAME"},
+		{name: "Dashes", key: "runbook-url", want: "RUNBOOK_URL"},
+		{name: "Unicode", key: "größe", want: "GR__E"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := envName(tt.key); got != tt.want {
+				t.Errorf("envName(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func newInjectionTestJob() *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "cleanup-abcde"},
+		Spec: batchv1.JobSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+			InitContainers: []v1.Container{{Name: "prepare"}},
+			Containers:     []v1.Container{{Name: "main"}, {Name: "sidecar"}},
+		}}},
+	}
+}
+
+func envValue(container v1.Container, name string) (string, bool) {
+	for _, env := range container.Env {
+		if env.Name == name {
+			return env.Value, true
+		}
+	}
+	return "", false
+}
+
+func TestInjectAlertContext(t *testing.T) {
+	testAlert := alert{
+		Labels:      map[string]string{"alertname": "TestAlert", "app.kubernetes.io/name": "web"},
+		Annotations: map[string]string{"runbook_url": "https://runbooks/test"},
+	}
+
+	tests := []struct {
+		name          string
+		job           *batchv1.Job
+		injection     config.Injection
+		wantInjected  []string
+		wantAlertFile bool
+	}{
+		{
+			name:         "All containers",
+			job:          newInjectionTestJob(),
+			injection:    config.Injection{},
+			wantInjected: []string{"main", "sidecar"},
+		},
+		{
+			name:         "Named containers and init containers",
+			job:          newInjectionTestJob(),
+			injection:    config.Injection{Containers: []string{"sidecar"}, InitContainers: ptr.To(true)},
+			wantInjected: []string{"prepare", "sidecar"},
+		},
+		{
+			name:          "Alert file",
+			job:           newInjectionTestJob(),
+			injection:     config.Injection{Containers: []string{"main"}, AlertFile: config.AlertFileSecret, MountPath: "/etc/openfero"},
+			wantInjected:  []string{"main"},
+			wantAlertFile: true,
+		},
+		{
+			name:      "Job without containers",
+			job:       &batchv1.Job{},
+			injection: config.Injection{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			injectAlertContext(tt.job, alertContext{alert: testAlert}, tt.injection)
+
+			podSpec := tt.job.Spec.Template.Spec
+			containers := append(append([]v1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+			for _, container := range containers {
+				injected := false
+				for _, name := range tt.wantInjected {
+					injected = injected || name == container.Name
+				}
+				value, found := envValue(container, "OPENFERO_APP_KUBERNETES_IO_NAME")
+				if found != injected || (found && value != "web") {
+					t.Errorf("container %s: label env found = %v, want %v", container.Name, found, injected)
+				}
+				if value, found := envValue(container, "OPENFERO_ANNOTATION_RUNBOOK_URL"); found != injected || (found && value != "https://runbooks/test") {
+					t.Errorf("container %s: annotation env found = %v, want %v", container.Name, found, injected)
+				}
+				value, found = envValue(container, alertFileEnv)
+				if wantFile := injected && tt.wantAlertFile; found != wantFile || (found && value != "/etc/openfero/alert.json") {
+					t.Errorf("container %s: %s = %q, found = %v, want %v", container.Name, alertFileEnv, value, found, wantFile)
+				}
+			}
+
+			if tt.wantAlertFile {
+				if len(podSpec.Volumes) != 1 || podSpec.Volumes[0].Projected == nil || podSpec.Volumes[0].Projected.Sources[0].Secret.Name != tt.job.Name {
+					t.Errorf("alert file volume = %+v", podSpec.Volumes)
+				}
+			} else if len(podSpec.Volumes) != 0 {
+				t.Errorf("unexpected volumes: %+v", podSpec.Volumes)
+			}
+		})
+	}
+}
+
+func TestCreateRemediationJobWithAlertFile(t *testing.T) {
+	file, err := newAlertFile(alertContext{alert: alert{Labels: map[string]string{"alertname": "TestAlert"}}}, config.Injection{AlertFile: config.AlertFileConfigMap})
+	if err != nil {
+		t.Fatalf("newAlertFile() returned error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		failCreate bool
+	}{
+		{name: "Alert file owned by job"},
+		{name: "Job is deleted if the alert file cannot be created", failCreate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewClientset()
+			clientset.PrependReactor("create", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				job := action.(k8stesting.CreateAction).GetObject().(*batchv1.Job)
+				job.UID = "job-uid"
+				return false, nil, nil
+			})
+			if tt.failCreate {
+				clientset.PrependReactor("create", "configmaps", func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, errors.New("forbidden")
+				})
+			}
+			server := &clientsetStruct{
+				clientset:               clientset,
+				jobDestinationNamespace: "default",
+				jobStore:                cache.NewStore(cache.MetaNamespaceKeyFunc),
+			}
+
+			job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "cleanup-abcde", Namespace: "default"}}
+			_, err := server.createRemediationJob(context.Background(), job, file)
+			if (err != nil) != tt.failCreate {
+				t.Fatalf("createRemediationJob() error = %v, wantErr %v", err, tt.failCreate)
+			}
+
+			_, jobErr := clientset.BatchV1().Jobs("default").Get(context.Background(), job.Name, metav1.GetOptions{})
+			if tt.failCreate {
+				if jobErr == nil {
+					t.Error("job was not deleted after the alert file failed")
+				}
+				return
+			}
+			if jobErr != nil {
+				t.Fatalf("job was not created: %v", jobErr)
+			}
+			configMap, err := clientset.CoreV1().ConfigMaps("default").Get(context.Background(), job.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("alert file was not created: %v", err)
+			}
+			if configMap.Data[alertFileKey] == "" {
+				t.Error("alert file is empty")
+			}
+			if len(configMap.OwnerReferences) != 1 || configMap.OwnerReferences[0].UID != "job-uid" {
+				t.Errorf("alert file owner references = %+v", configMap.OwnerReferences)
+			}
+		})
+	}
+}
+
+func TestAlertEnvVarsLabelCollision(t *testing.T) {
+	data := alertContext{
+		group: &hookMessage{GroupKey: "{}:{alertname=\"TestAlert\"}", Receiver: "openfero"},
+		alert: alert{
+			Status: "firing",
+			Labels: map[string]string{
+				"alertname":          "TestAlert",
+				"alert_status":       "label",
+				"receiver":           "label",
+				"annotation_summary": "label",
+				"group_label_team":   "label",
+				"step_diagnose":      "label",
+			},
+			Annotations: map[string]string{"summary": "Test"},
+		},
+	}
+
+	got := make(map[string]string)
+	for _, env := range alertEnvVars(data) {
+		if _, ok := got[env.Name]; ok {
+			t.Errorf("duplicate variable %s", env.Name)
+		}
+		got[env.Name] = env.Value
+	}
+	want := map[string]string{
+		"OPENFERO_ALERTNAME":          "TestAlert",
+		"OPENFERO_ALERT_STATUS":       "firing",
+		"OPENFERO_RECEIVER":           "openfero",
+		"OPENFERO_ANNOTATION_SUMMARY": "Test",
+		"OPENFERO_GROUP_KEY":          "{}:{alertname=\"TestAlert\"}",
+		"OPENFERO_TRUNCATED_ALERTS":   "0",
+	}
+	if len(got) != len(want) {
+		t.Errorf("alertEnvVars() returned %d variables, want %d: %v", len(got), len(want), got)
+	}
+	for name, value := range want {
+		if got[name] != value {
+			t.Errorf("%s = %q, want %q", name, got[name], value)
+		}
+	}
+}
+
+func TestAlertEnvVarsWithGroup(t *testing.T) {
+	data := alertContext{
+		group: &hookMessage{
+			GroupKey:          "{}:{alertname=\"TestAlert\"}",
+			Status:            "firing",
+			Receiver:          "openfero",
+			ExternalURL:       "http://alertmanager.example.com",
+			TruncatedAlerts:   2,
+			GroupLabels:       map[string]string{"alertname": "TestAlert"},
+			CommonLabels:      map[string]string{"severity": "critical"},
+			CommonAnnotations: map[string]string{"summary": "Test"},
+			Alerts:            []alert{{}, {}},
+		},
+		alert: alert{
+			Status:       "firing",
+			Labels:       map[string]string{"alertname": "TestAlert"},
+			StartsAt:     "2024-01-01T00:00:00Z",
+			EndsAt:       "0001-01-01T00:00:00Z",
+			GeneratorURL: "http://prometheus.example.com/graph",
+			Fingerprint:  "abc123",
+		},
+	}
+
+	want := map[string]string{
+		"OPENFERO_ALERTNAME":                 "TestAlert",
+		"OPENFERO_ALERT_STATUS":              "firing",
+		"OPENFERO_STARTS_AT":                 "2024-01-01T00:00:00Z",
+		"OPENFERO_ENDS_AT":                   "0001-01-01T00:00:00Z",
+		"OPENFERO_GENERATOR_URL":             "http://prometheus.example.com/graph",
+		"OPENFERO_FINGERPRINT":               "abc123",
+		"OPENFERO_GROUP_KEY":                 "{}:{alertname=\"TestAlert\"}",
+		"OPENFERO_GROUP_STATUS":              "firing",
+		"OPENFERO_RECEIVER":                  "openfero",
+		"OPENFERO_EXTERNAL_URL":              "http://alertmanager.example.com",
+		"OPENFERO_TRUNCATED_ALERTS":          "2",
+		"OPENFERO_GROUP_LABEL_ALERTNAME":     "TestAlert",
+		"OPENFERO_COMMON_LABEL_SEVERITY":     "critical",
+		"OPENFERO_COMMON_ANNOTATION_SUMMARY": "Test",
+	}
+	got := make(map[string]string)
+	for _, env := range alertEnvVars(data) {
+		got[env.Name] = env.Value
+	}
+	if len(got) != len(want) {
+		t.Errorf("alertEnvVars() returned %d variables, want %d: %v", len(got), len(want), got)
+	}
+	for name, value := range want {
+		if got[name] != value {
+			t.Errorf("%s = %q, want %q", name, got[name], value)
+		}
+	}
+
+	file, err := newAlertFile(data, config.Injection{AlertFile: config.AlertFileConfigMap})
+	if err != nil {
+		t.Fatalf("newAlertFile() returned error: %v", err)
+	}
+	group := hookMessage{}
+	if err := json.Unmarshal([]byte(file.data[groupFileKey]), &group); err != nil {
+		t.Fatalf("could not decode group file: %v", err)
+	}
+	if group.Receiver != "openfero" || group.TruncatedAlerts != 2 || len(group.Alerts) != 0 {
+		t.Errorf("group file = %s", file.data[groupFileKey])
+	}
+
+	// The alert file follows the webhook format of Alertmanager
+	keys := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(file.data[alertFileKey]), &keys); err != nil {
+		t.Fatalf("could not decode alert file: %v", err)
+	}
+	for _, key := range []string{"status", "labels", "annotations", "startsAt", "endsAt", "generatorURL", "fingerprint"} {
+		if _, ok := keys[key]; !ok {
+			t.Errorf("alert file %s has no key %s", file.data[alertFileKey], key)
+		}
+	}
+	if len(keys) != 7 {
+		t.Errorf("alert file %s has unexpected keys", file.data[alertFileKey])
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/alertmanager"
+	"github.com/OpenFero/openfero/pkg/config"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"go.uber.org/zap"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// remediationFailedAlertname is the alertname of the alert fired when a job fails
+const remediationFailedAlertname = "OpenFeroRemediationFailed"
+
+// alertmanagerTimeout limits a single request to Alertmanager
+const alertmanagerTimeout = 10 * time.Second
+
+// alertmanagerWriteBack silences the alert of a job while the job runs and fires an alert when the job fails.
+// The URL and settings are read on every call, so they can be changed without restart.
+type alertmanagerWriteBack struct {
+	config     func() *config.Config
+	httpClient *http.Client
+
+	mu sync.Mutex
+	// silences maps unfinished jobs to the ID of their silence, which is empty while the silence is created
+	silences map[types.UID]string
+}
+
+func newAlertmanagerWriteBack(config func() *config.Config) *alertmanagerWriteBack {
+	return &alertmanagerWriteBack{
+		config:     config,
+		httpClient: &http.Client{Timeout: alertmanagerTimeout},
+		silences:   make(map[types.UID]string),
+	}
+}
+
+// settings returns the write back settings of the run, the global settings for jobs created before a restart
+func (writeBack *alertmanagerWriteBack) settings(cfg *config.Config, run *jobRun) config.WriteBack {
+	if run.settings != nil {
+		return run.settings.Alertmanager
+	}
+	return cfg.Alertmanager.WriteBack
+}
+
+// jobCreated silences the alert until the job finishes or its deadline is exceeded.
+// The silence is created in the background, so Alertmanager does not delay the processing of alerts.
+func (writeBack *alertmanagerWriteBack) jobCreated(ctx context.Context, run *jobRun) {
+	cfg := writeBack.config()
+	settings := writeBack.settings(cfg, run)
+	if cfg.Alertmanager.URL == "" || settings.Silence == nil || !*settings.Silence {
+		return
+	}
+	logger := log.FromContext(ctx)
+	labels := silencedLabels(run.data)
+	if len(labels) == 0 {
+		logger.Warn("Alert has no labels to silence")
+		return
+	}
+
+	duration := cfg.Alertmanager.SilenceDuration.Duration()
+	if deadline := run.job.Spec.ActiveDeadlineSeconds; deadline != nil {
+		duration = time.Duration(*deadline) * time.Second
+	}
+	now := time.Now()
+	silence := alertmanager.Silence{
+		Matchers:  alertmanager.MatchersFromLabels(labels),
+		StartsAt:  now,
+		EndsAt:    now.Add(duration),
+		CreatedBy: "openfero",
+		Comment:   fmt.Sprintf("Remediation job %s/%s is running", run.job.Namespace, run.job.Name),
+	}
+
+	uid := run.job.UID
+	writeBack.mu.Lock()
+	writeBack.silences[uid] = ""
+	writeBack.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), alertmanagerTimeout)
+		defer cancel()
+		client := alertmanager.New(cfg.Alertmanager.URL, writeBack.httpClient)
+		id, err := client.CreateSilence(ctx, silence)
+
+		writeBack.mu.Lock()
+		_, running := writeBack.silences[uid]
+		if err != nil || !running {
+			delete(writeBack.silences, uid)
+		} else {
+			writeBack.silences[uid] = id
+		}
+		writeBack.mu.Unlock()
+
+		switch {
+		case err != nil:
+			logger.Error("error silencing alert", zap.String("error", err.Error()))
+		case !running:
+			// The job finished or was deleted while the silence was created
+			writeBack.expireSilence(ctx, client, logger, id)
+		default:
+			logger.Info("Alert silenced while the job runs", zap.String("silence", id), zap.Duration("duration", duration))
+		}
+	}()
+}
+
+// jobFinished expires the silence of the job and fires an alert if the job failed.
+// The requests are sent in the background, so the job informer is not blocked.
+func (writeBack *alertmanagerWriteBack) jobFinished(run *jobRun, outcome jobOutcome, reason string) {
+	id := writeBack.forgetSilence(run.job.UID)
+	silenced := id != ""
+
+	cfg := writeBack.config()
+	settings := writeBack.settings(cfg, run)
+	failureAlert := outcome == jobOutcomeFailed && settings.FailureAlert != nil && *settings.FailureAlert
+	if cfg.Alertmanager.URL == "" || (!silenced && !failureAlert) {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), alertmanagerTimeout)
+		defer cancel()
+		client := alertmanager.New(cfg.Alertmanager.URL, writeBack.httpClient)
+		logger := log.Subsystem(log.SubsystemJobs).With(zap.String(log.JobKey, run.job.Name))
+
+		if silenced {
+			writeBack.expireSilence(ctx, client, logger, id)
+		}
+		if failureAlert {
+			if err := client.PostAlerts(ctx, remediationFailedAlert(run, reason, cfg.Alertmanager.FailureAlertDuration.Duration())); err != nil {
+				logger.Error("error firing remediation failed alert", zap.String("error", err.Error()))
+			} else {
+				logger.Info("Remediation failed alert fired")
+			}
+		}
+	}()
+}
+
+// jobDeleted expires the silence of a job deleted before it finished
+func (writeBack *alertmanagerWriteBack) jobDeleted(uid types.UID) {
+	id := writeBack.forgetSilence(uid)
+	cfg := writeBack.config()
+	if id == "" || cfg.Alertmanager.URL == "" {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), alertmanagerTimeout)
+		defer cancel()
+		client := alertmanager.New(cfg.Alertmanager.URL, writeBack.httpClient)
+		writeBack.expireSilence(ctx, client, log.Subsystem(log.SubsystemJobs), id)
+	}()
+}
+
+// forgetSilence removes the silence of a job and returns its ID, which is empty while the silence is created
+func (writeBack *alertmanagerWriteBack) forgetSilence(uid types.UID) string {
+	writeBack.mu.Lock()
+	defer writeBack.mu.Unlock()
+	id := writeBack.silences[uid]
+	delete(writeBack.silences, uid)
+	return id
+}
+
+func (writeBack *alertmanagerWriteBack) expireSilence(ctx context.Context, client *alertmanager.Client, logger *zap.Logger, id string) {
+	if err := client.ExpireSilence(ctx, id); err != nil {
+		logger.Error("error expiring silence", zap.String("error", err.Error()))
+		return
+	}
+	logger.Debug("Silence expired", zap.String("silence", id))
+}
+
+// silencedLabels returns the labels of the alert, or the labels the group is built by for jobs per group
+func silencedLabels(data alertContext) map[string]string {
+	if !data.perGroup() {
+		return data.alert.Labels
+	}
+	if data.group == nil {
+		return nil
+	}
+	if len(data.group.GroupLabels) > 0 {
+		return data.group.GroupLabels
+	}
+	return data.group.CommonLabels
+}
+
+// remediationFailedAlert returns the alert fired for a failed job. It carries the labels of the remediated alert,
+// so it is routed like that alert, and identifies the job by the labels openfero_job and openfero_definition.
+func remediationFailedAlert(run *jobRun, reason string, duration time.Duration) alertmanager.Alert {
+	job := run.job
+	alertname := job.Annotations[alertnameAnnotation]
+	labels := make(map[string]string)
+	maps.Copy(labels, silencedLabels(run.data))
+	labels["alertname"] = remediationFailedAlertname
+	labels["remediated_alertname"] = alertname
+	labels["openfero_job"] = job.Name
+	labels["openfero_definition"] = job.Annotations[definitionAnnotation]
+
+	now := time.Now()
+	return alertmanager.Alert{
+		Labels: labels,
+		Annotations: map[string]string{
+			"summary": fmt.Sprintf("Remediation job %s/%s for alert %s failed", job.Namespace, job.Name, alertname),
+			"reason":  reason,
+		},
+		StartsAt: now,
+		EndsAt:   now.Add(duration),
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/alertmanager"
+	"github.com/OpenFero/openfero/pkg/config"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+type alertmanagerRequest struct {
+	method string
+	path   string
+	body   []byte
+}
+
+// newTestAlertmanager starts an Alertmanager stand-in recording all requests
+func newTestAlertmanager(t *testing.T) (*httptest.Server, chan alertmanagerRequest) {
+	t.Helper()
+	requests := make(chan alertmanagerRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- alertmanagerRequest{method: r.Method, path: r.URL.Path, body: body}
+		if r.URL.Path == "/api/v2/silences" {
+			_, _ = io.WriteString(w, `{"silenceID":"silence-1"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func nextAlertmanagerRequest(t *testing.T, requests chan alertmanagerRequest) alertmanagerRequest {
+	t.Helper()
+	select {
+	case request := <-requests:
+		return request
+	case <-time.After(5 * time.Second):
+		t.Fatal("Alertmanager did not receive a request")
+	}
+	return alertmanagerRequest{}
+}
+
+// waitForSilence waits until the silence created in the background is recorded for the job
+func waitForSilence(t *testing.T, writeBack *alertmanagerWriteBack, uid types.UID) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		writeBack.mu.Lock()
+		id := writeBack.silences[uid]
+		writeBack.mu.Unlock()
+		if id != "" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("silence not recorded after 5s")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAlertmanagerWriteBack(t *testing.T) {
+	server, requests := newTestAlertmanager(t)
+	cfg := config.Default()
+	cfg.Alertmanager.URL = server.URL
+	writeBack := newAlertmanagerWriteBack(func() *config.Config { return cfg })
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "quota-abc12",
+			Namespace: "default",
+			UID:       "uid-1",
+			Annotations: map[string]string{
+				definitionAnnotation: "openfero-kubequotaalmostfull-firing",
+				alertnameAnnotation:  "KubeQuotaAlmostFull",
+			},
+		},
+		Spec: batchv1.JobSpec{ActiveDeadlineSeconds: ptr.To[int64](600)},
+	}
+	run := &jobRun{
+		job:  job,
+		data: alertContext{alert: alert{Labels: map[string]string{"alertname": "KubeQuotaAlmostFull", "namespace": "shop"}}},
+		settings: &definitionSettings{Alertmanager: config.WriteBack{
+			Silence:      ptr.To(true),
+			FailureAlert: ptr.To(true),
+		}},
+	}
+
+	writeBack.jobCreated(context.Background(), run)
+	request := nextAlertmanagerRequest(t, requests)
+	if request.method != http.MethodPost || request.path != "/api/v2/silences" {
+		t.Fatalf("request = %s %s, want the creation of a silence", request.method, request.path)
+	}
+	silence := alertmanager.Silence{}
+	if err := json.Unmarshal(request.body, &silence); err != nil {
+		t.Fatal(err)
+	}
+	if len(silence.Matchers) != 2 || silence.Matchers[0].Name != "alertname" || silence.Matchers[1].Value != "shop" {
+		t.Errorf("unexpected matchers: %+v", silence.Matchers)
+	}
+	if duration := silence.EndsAt.Sub(silence.StartsAt); duration != 10*time.Minute {
+		t.Errorf("silence lasts %s, want the active deadline of the job", duration)
+	}
+	waitForSilence(t, writeBack, job.UID)
+
+	writeBack.jobFinished(run, jobOutcomeFailed, "BackoffLimitExceeded")
+	request = nextAlertmanagerRequest(t, requests)
+	if request.method != http.MethodDelete || request.path != "/api/v2/silence/silence-1" {
+		t.Fatalf("request = %s %s, want the silence to expire", request.method, request.path)
+	}
+	request = nextAlertmanagerRequest(t, requests)
+	if request.method != http.MethodPost || request.path != "/api/v2/alerts" {
+		t.Fatalf("request = %s %s, want a failure alert", request.method, request.path)
+	}
+	alerts := []alertmanager.Alert{}
+	if err := json.Unmarshal(request.body, &alerts); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("posted %d alerts, want 1", len(alerts))
+	}
+	wantLabels := map[string]string{
+		"alertname":            remediationFailedAlertname,
+		"remediated_alertname": "KubeQuotaAlmostFull",
+		"namespace":            "shop",
+		"openfero_job":         "quota-abc12",
+		"openfero_definition":  "openfero-kubequotaalmostfull-firing",
+	}
+	for key, want := range wantLabels {
+		if got := alerts[0].Labels[key]; got != want {
+			t.Errorf("label %s = %q, want %q", key, got, want)
+		}
+	}
+	if alerts[0].Annotations["reason"] != "BackoffLimitExceeded" {
+		t.Errorf("unexpected annotations: %v", alerts[0].Annotations)
+	}
+}
+
+func TestAlertmanagerWriteBackJobDeleted(t *testing.T) {
+	server, requests := newTestAlertmanager(t)
+	cfg := config.Default()
+	cfg.Alertmanager.URL = server.URL
+	writeBack := newAlertmanagerWriteBack(func() *config.Config { return cfg })
+
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "quota-abc12", Namespace: "default", UID: "uid-1"}}
+	run := &jobRun{
+		job:      job,
+		data:     alertContext{alert: alert{Labels: map[string]string{"alertname": "KubeQuotaAlmostFull"}}},
+		settings: &definitionSettings{Alertmanager: config.WriteBack{Silence: ptr.To(true)}},
+	}
+
+	tests := []struct {
+		name          string
+		waitForCreate bool
+	}{
+		{name: "After the silence was created", waitForCreate: true},
+		{name: "While the silence is created"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeBack.jobCreated(context.Background(), run)
+			if tt.waitForCreate {
+				waitForSilence(t, writeBack, job.UID)
+			}
+			writeBack.jobDeleted(job.UID)
+
+			request := nextAlertmanagerRequest(t, requests)
+			if request.method != http.MethodPost || request.path != "/api/v2/silences" {
+				t.Fatalf("request = %s %s, want the creation of a silence", request.method, request.path)
+			}
+			request = nextAlertmanagerRequest(t, requests)
+			if request.method != http.MethodDelete || request.path != "/api/v2/silence/silence-1" {
+				t.Fatalf("request = %s %s, want the silence to expire", request.method, request.path)
+			}
+			writeBack.mu.Lock()
+			defer writeBack.mu.Unlock()
+			if len(writeBack.silences) != 0 {
+				t.Errorf("silences = %v, want none", writeBack.silences)
+			}
+		})
+	}
+}
+
+func TestAlertmanagerWriteBackDisabled(t *testing.T) {
+	server, requests := newTestAlertmanager(t)
+	cfg := config.Default()
+	cfg.Alertmanager.URL = server.URL
+	writeBack := newAlertmanagerWriteBack(func() *config.Config { return cfg })
+
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "quota-abc12", Namespace: "default", UID: "uid-1"}}
+	run := &jobRun{
+		job:      job,
+		data:     alertContext{alert: alert{Labels: map[string]string{"alertname": "KubeQuotaAlmostFull"}}},
+		settings: &definitionSettings{Alertmanager: config.WriteBack{Silence: ptr.To(false), FailureAlert: ptr.To(false)}},
+	}
+	writeBack.jobCreated(context.Background(), run)
+	writeBack.jobFinished(run, jobOutcomeFailed, "BackoffLimitExceeded")
+
+	select {
+	case request := <-requests:
+		t.Errorf("unexpected request %s %s", request.method, request.path)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestSilencedLabels(t *testing.T) {
+	group := &hookMessage{
+		GroupLabels:  map[string]string{"alertname": "KubeQuotaAlmostFull"},
+		CommonLabels: map[string]string{"alertname": "KubeQuotaAlmostFull", "namespace": "shop"},
+	}
+	tests := []struct {
+		name string
+		data alertContext
+		want map[string]string
+	}{
+		{
+			name: "Per alert",
+			data: alertContext{group: group, alert: alert{Labels: map[string]string{"alertname": "KubeQuotaAlmostFull", "pod": "web-0"}}},
+			want: map[string]string{"alertname": "KubeQuotaAlmostFull", "pod": "web-0"},
+		},
+		{
+			name: "Per group",
+			data: alertContext{group: group, alerts: []alert{}},
+			want: group.GroupLabels,
+		},
+		{
+			name: "Per group without group labels",
+			data: alertContext{group: &hookMessage{CommonLabels: group.CommonLabels}, alerts: []alert{}},
+			want: group.CommonLabels,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := silencedLabels(tt.data)
+			if len(got) != len(tt.want) {
+				t.Fatalf("silencedLabels() = %v, want %v", got, tt.want)
+			}
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("silencedLabels() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -1,11 +1,19 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
 )
 
 func TestGetAlertsHandler(t *testing.T) {
@@ -62,3 +70,132 @@ func MalformedJSONPostAlertsHandler(t *testing.T) {
 		Implement test with malformed json
 	*/
 }
+
+func TestDecodeHookMessage(t *testing.T) {
+	jsonFile, err := os.Open("test/alerts.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer jsonFile.Close()
+
+	message := hookMessage{}
+	if err := json.NewDecoder(jsonFile).Decode(&message); err != nil {
+		t.Fatalf("could not decode message: %v", err)
+	}
+
+	if message.Version != "4" || message.Receiver != "namespace-resizer" || message.ExternalURL != "http://alertmanager.example.com" {
+		t.Errorf("unexpected group context: %+v", message)
+	}
+	if message.GroupLabels["alertname"] != "KubeQuotaAlmostFull" || message.CommonAnnotations["summary"] == "" {
+		t.Errorf("unexpected group labels or common annotations: %+v", message)
+	}
+	if len(message.Alerts) != 3 {
+		t.Fatalf("decoded %d alerts, want 3", len(message.Alerts))
+	}
+	for _, alert := range message.Alerts {
+		if alert.Status != "firing" || alert.Fingerprint == "" || alert.GeneratorURL == "" || alert.EndsAt == "" {
+			t.Errorf("alert fields not decoded: %+v", alert)
+		}
+	}
+	encoded, err := json.Marshal(message.Alerts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(encoded), `"EndsAt":`) {
+		t.Errorf("encoded alert %s, want the key EndsAt of earlier responses", encoded)
+	}
+}
+
+func TestCreateResponseJobModes(t *testing.T) {
+	jobDefinition := `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: quota
+spec:
+  template:
+    spec:
+      containers:
+        - name: main
+          image: busybox
+      restartPolicy: Never
+`
+	tests := []struct {
+		name          string
+		settings      string
+		wantJobs      int
+		wantAlertFile string
+	}{
+		{name: "Per alert", wantJobs: 3},
+		{name: "Per group", settings: "mode: perGroup\n", wantJobs: 1, wantAlertFile: alertsFileKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alertStore = make([]alertStoreEntry, 0, 10)
+			data := map[string]string{"KubeQuotaAlmostFull": jobDefinition}
+			if tt.settings != "" {
+				data[definitionSettingsKey] = tt.settings
+			}
+			configMapStore := cache.NewStore(cache.MetaNamespaceKeyFunc)
+			if err := configMapStore.Add(&v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "openfero-kubequotaalmostfull-firing", Namespace: "default"},
+				Data:       data,
+			}); err != nil {
+				t.Fatal(err)
+			}
+			clientset := fake.NewClientset()
+			listener := &recordingListener{}
+			server := &clientsetStruct{
+				clientset:               clientset,
+				configmapNamespace:      "default",
+				jobDestinationNamespace: "default",
+				configMapStore:          configMapStore,
+				jobStore:                cache.NewStore(cache.MetaNamespaceKeyFunc),
+				lifecycle:               newJobLifecycle(listener),
+			}
+
+			jsonFile, err := os.Open("test/alerts.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer jsonFile.Close()
+			message := hookMessage{}
+			if err := json.NewDecoder(jsonFile).Decode(&message); err != nil {
+				t.Fatal(err)
+			}
+			for index := range message.Alerts {
+				server.createResponseJob(context.Background(), &message, index, "firing")
+			}
+
+			jobs, err := clientset.BatchV1().Jobs("default").List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(jobs.Items) != tt.wantJobs {
+				t.Fatalf("created %d jobs, want %d", len(jobs.Items), tt.wantJobs)
+			}
+			if len(listener.created) != tt.wantJobs {
+				t.Errorf("listener was notified about %d jobs, want %d", len(listener.created), tt.wantJobs)
+			}
+			if jobs.Items[0].Annotations[groupKeyAnnotation] != message.GroupKey {
+				t.Errorf("group key annotation = %q", jobs.Items[0].Annotations[groupKeyAnnotation])
+			}
+			if tt.wantAlertFile == "" {
+				return
+			}
+
+			env, _ := envValue(jobs.Items[0].Spec.Template.Spec.Containers[0], alertCountEnv)
+			if env != "3" {
+				t.Errorf("%s = %q, want 3", alertCountEnv, env)
+			}
+			configMap, err := clientset.CoreV1().ConfigMaps("default").Get(context.Background(), jobs.Items[0].Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("alert file was not created: %v", err)
+			}
+			var alerts []alert
+			if err := json.Unmarshal([]byte(configMap.Data[tt.wantAlertFile]), &alerts); err != nil || len(alerts) != 3 {
+				t.Errorf("alerts file contains %d alerts, error: %v", len(alerts), err)
+			}
+		})
+	}
+}
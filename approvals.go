@@ -0,0 +1,518 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"go.uber.org/zap"
+
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+// approvalState is the state of a job of a definition which requires approval
+type approvalState string
+
+const (
+	approvalPending  approvalState = "pending"
+	approvalApproved approvalState = "approved"
+	approvalRejected approvalState = "rejected"
+	approvalExpired  approvalState = "expired"
+)
+
+var (
+	errApprovalNotFound   = errors.New("approval not found")
+	errApprovalDecided    = errors.New("approval is no longer pending")
+	errApprovalInProgress = errors.New("approval is being decided")
+)
+
+// maxApprovalDecisionBytes limits the body of a decision, which only holds a comment
+const maxApprovalDecisionBytes = 64 << 10
+
+// @Description Job waiting for approval, or the decision about it
+type approval struct {
+	// @Description ID of the approval
+	ID string `json:"id"`
+	// @Description Name of the ConfigMap of the job definition
+	Definition string `json:"definition"`
+	// @Description Alertname of the definition
+	Alertname string `json:"alertname"`
+	// @Description Name of the job created on approval
+	Job string `json:"job,omitempty"`
+	// @Description ID of the workflow started on approval
+	Workflow string `json:"workflow,omitempty"`
+	// @Description Alert the job was rendered for, the first alert for jobs per group
+	Alert alert `json:"alert"`
+	// @Description Number of alerts handled by the job
+	AlertCount int `json:"alertCount"`
+	// @Description State of the approval
+	State approvalState `json:"state" enum:"pending,approved,rejected,expired" example:"pending"`
+	// @Description Time when the job was parked
+	CreatedAt time.Time `json:"createdAt"`
+	// @Description Time after which a pending job is dropped
+	ExpiresAt time.Time `json:"expiresAt"`
+	// @Description User who approved or rejected the job
+	DecidedBy string `json:"decidedBy,omitempty"`
+	// @Description Time of the decision or expiry
+	DecidedAt *time.Time `json:"decidedAt,omitempty"`
+	// @Description Comment of the decision
+	Comment string `json:"comment,omitempty"`
+	// @Description Error of the last attempt to create the job after approval
+	Error string `json:"error,omitempty"`
+
+	// key identifies the alert, so repeated notifications do not park the job again
+	key     string
+	pending *pendingJob
+	timer   *time.Timer
+	// claimed is set while a decision is being made, so the approval is neither decided twice nor expires meanwhile
+	claimed bool
+}
+
+// pendingJob is everything needed to create the job, or to start the workflow, once it is approved
+type pendingJob struct {
+	job      *batchv1.Job
+	file     *alertFile
+	data     alertContext
+	settings definitionSettings
+	workflow *workflowRun
+}
+
+// @Description Decision about a pending job
+type approvalDecision struct {
+	// @Description Reason of the decision, recorded with the approval
+	Comment string `json:"comment,omitempty"`
+}
+
+// approvalStore keeps pending jobs until they are approved, rejected or expire,
+// and the latest decisions as audit trail
+type approvalStore struct {
+	mu          sync.Mutex
+	approvals   []*approval
+	historySize int
+}
+
+func newApprovalStore(historySize int) *approvalStore {
+	return &approvalStore{historySize: historySize}
+}
+
+// park adds a pending job which expires after timeout.
+// It returns false if a job for the same alert is already pending.
+func (store *approvalStore) park(entry *approval, pending *pendingJob, timeout time.Duration) bool {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	for _, existing := range store.approvals {
+		if existing.State == approvalPending && existing.key == entry.key {
+			return false
+		}
+	}
+	entry.ID = newID()
+	entry.State = approvalPending
+	entry.CreatedAt = time.Now()
+	entry.ExpiresAt = entry.CreatedAt.Add(timeout)
+	entry.pending = pending
+	id := entry.ID
+	entry.timer = time.AfterFunc(timeout, func() { store.expire(id) })
+	store.approvals = append(store.approvals, entry)
+	return true
+}
+
+// claim reserves a pending job for a decision. The claim ends with decide, or with release if the job could not be created.
+func (store *approvalStore) claim(id string) (approval, *pendingJob, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	entry := store.find(id)
+	switch {
+	case entry == nil:
+		return approval{}, nil, errApprovalNotFound
+	case entry.State != approvalPending:
+		return *entry, nil, errApprovalDecided
+	case entry.claimed:
+		return *entry, nil, errApprovalInProgress
+	}
+	entry.claimed = true
+	return *entry, entry.pending, nil
+}
+
+// release ends a claim without decision and records the error of the job creation, if any.
+// The job stays pending, unless it expired meanwhile.
+func (store *approvalStore) release(id string, err error) approval {
+	store.mu.Lock()
+	entry := store.find(id)
+	if entry == nil {
+		store.mu.Unlock()
+		return approval{}
+	}
+	entry.claimed = false
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	expired := entry.State == approvalPending && time.Now().After(entry.ExpiresAt)
+	if expired {
+		store.close(entry, approvalExpired)
+	}
+	released := *entry
+	store.mu.Unlock()
+
+	if expired {
+		logExpired(released)
+	}
+	return released
+}
+
+// decide approves or rejects a pending job and returns the job to create on approval
+func (store *approvalStore) decide(id string, state approvalState, user string, comment string) (approval, *pendingJob, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	entry := store.find(id)
+	if entry == nil {
+		return approval{}, nil, errApprovalNotFound
+	}
+	if entry.State != approvalPending {
+		return *entry, nil, errApprovalDecided
+	}
+	entry.timer.Stop()
+	pending := entry.pending
+	store.close(entry, state)
+	entry.DecidedBy = user
+	entry.Comment = comment
+	entry.Error = ""
+	updateAlertApproval(*entry)
+	return *entry, pending, nil
+}
+
+// expire drops a job which is still pending
+func (store *approvalStore) expire(id string) {
+	store.mu.Lock()
+	entry := store.find(id)
+	if entry == nil || entry.State != approvalPending || entry.claimed {
+		store.mu.Unlock()
+		return
+	}
+	store.close(entry, approvalExpired)
+	expired := *entry
+	store.mu.Unlock()
+	logExpired(expired)
+}
+
+// logExpired records that the job of the approval was dropped
+func logExpired(expired approval) {
+	updateAlertApproval(expired)
+	log.Subsystem(log.SubsystemJobs).Info("Approval expired, dropping job",
+		zap.String("approval", expired.ID), zap.String("definition", expired.Definition), zap.String(log.JobKey, expired.Job))
+	metadata.JobsSkippedTotal.WithLabelValues(expired.Alertname, expired.Definition, metadata.SkipReasonExpired).Inc()
+}
+
+// list returns the pending approvals and then the decisions, the newest first
+// withoutIdentities returns the approvals without the users and comments of the decisions, which are only served
+// with an API token
+func withoutIdentities(approvals []approval) []approval {
+	for i := range approvals {
+		approvals[i].DecidedBy = ""
+		approvals[i].Comment = ""
+	}
+	return approvals
+}
+
+func (store *approvalStore) list() []approval {
+	store.mu.Lock()
+	approvals := make([]approval, 0, len(store.approvals))
+	for _, entry := range store.approvals {
+		approvals = append(approvals, *entry)
+	}
+	store.mu.Unlock()
+
+	sort.SliceStable(approvals, func(i, j int) bool {
+		if pendingI, pendingJ := approvals[i].State == approvalPending, approvals[j].State == approvalPending; pendingI != pendingJ {
+			return pendingI
+		}
+		if approvals[i].DecidedAt != nil && approvals[j].DecidedAt != nil {
+			return approvals[i].DecidedAt.After(*approvals[j].DecidedAt)
+		}
+		return approvals[i].CreatedAt.After(approvals[j].CreatedAt)
+	})
+	return approvals
+}
+
+// close ends a pending approval and drops the oldest decisions beyond the history size, must be called with the lock held
+func (store *approvalStore) close(entry *approval, state approvalState) {
+	now := time.Now()
+	entry.State = state
+	entry.DecidedAt = &now
+	entry.pending = nil
+	entry.claimed = false
+
+	// Approvals are kept in the order of their last change, so the oldest decisions come first
+	store.approvals = slices.DeleteFunc(store.approvals, func(candidate *approval) bool { return candidate == entry })
+	store.approvals = append(store.approvals, entry)
+	decided := 0
+	for i := len(store.approvals) - 1; i >= 0; i-- {
+		if store.approvals[i].State == approvalPending {
+			continue
+		}
+		decided++
+		if decided > store.historySize {
+			store.approvals = slices.Delete(store.approvals, i, i+1)
+		}
+	}
+}
+
+func (store *approvalStore) find(id string) *approval {
+	for _, entry := range store.approvals {
+		if entry.ID == id {
+			return entry
+		}
+	}
+	return nil
+}
+
+// newID returns a random ID for approvals and workflow runs
+func newID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return stringWithCharset(16, charset)
+	}
+	return hex.EncodeToString(id)
+}
+
+// approvalKey identifies the alert or group of a job, so the same alert does not wait twice for approval
+func approvalKey(definition string, data alertContext) string {
+	if data.perGroup() && data.group != nil {
+		return definition + "/" + data.group.GroupKey
+	}
+	if data.alert.Fingerprint != "" {
+		return definition + "/" + data.alert.Fingerprint
+	}
+	names := make([]string, 0, len(data.alert.Labels))
+	for name := range data.alert.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var key strings.Builder
+	key.WriteString(definition)
+	for _, name := range names {
+		fmt.Fprintf(&key, "/%s=%s", name, data.alert.Labels[name])
+	}
+	return key.String()
+}
+
+// parkForApproval keeps the rendered job, or the workflow, until a user approves or rejects it, and links the alert
+// in the alert store to the approval. It returns the ID of the approval, or false if the job of the alert is already
+// waiting for approval.
+func (server *clientsetStruct) parkForApproval(ctx context.Context, pending *pendingJob, definition string, alertname string, status string) (string, bool) {
+	logger := log.FromContext(ctx)
+	data := pending.data
+	alertCount := 1
+	if data.perGroup() {
+		alertCount = len(data.alerts)
+	}
+	entry := &approval{
+		Definition: definition,
+		Alertname:  alertname,
+		Alert:      data.alert,
+		AlertCount: alertCount,
+		key:        approvalKey(definition, data),
+	}
+	if pending.workflow != nil {
+		entry.Workflow = pending.workflow.status.ID
+	} else {
+		entry.Job = pending.job.Name
+	}
+	if !server.approvals.park(entry, pending, pending.settings.ApprovalTimeout.Duration()) {
+		logger.Info("Job for the alert is already waiting for approval, skipping job creation")
+		metadata.JobsSkippedTotal.WithLabelValues(entry.Alertname, definition, metadata.SkipReasonDeduplicated).Inc()
+		return "", false
+	}
+	logger.Info("Job requires approval, waiting for a decision", zap.String("approval", entry.ID), zap.Time("expiresAt", entry.ExpiresAt))
+	markAlertApproval(data.alert, status, entry.ID)
+	return entry.ID, true
+}
+
+// @Summary List approvals
+// @Description List the jobs waiting for approval and the latest decisions
+// @Tags approvals
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} approval
+// @Failure 401 {string} string "Unauthorized"
+// @Router /api/approvals [get]
+func (server *clientsetStruct) approvalsGetHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, server.approvals.list())
+}
+
+// @Summary Approve a job
+// @Description Approve a job waiting for approval, the job is created immediately. If it can not be created, the job stays pending and can be approved again. During a pause or maintenance window the approval is answered with 409 and the job stays pending.
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID of the approval"
+// @Param decision body approvalDecision false "Comment of the decision"
+// @Success 200 {object} approval
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Not Found"
+// @Failure 409 {string} string "Conflict"
+// @Failure 413 {string} string "Request Entity Too Large"
+// @Failure 500 {object} approval "Job could not be created, the approval stays pending"
+// @Router /api/approvals/{id}/approve [post]
+func (server *clientsetStruct) approvalApproveHandler(w http.ResponseWriter, r *http.Request) {
+	server.decideApproval(w, r, approvalApproved)
+}
+
+// @Summary Reject a job
+// @Description Reject a job waiting for approval, the job is dropped
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID of the approval"
+// @Param decision body approvalDecision false "Comment of the decision"
+// @Success 200 {object} approval
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Not Found"
+// @Failure 409 {string} string "Conflict"
+// @Failure 413 {string} string "Request Entity Too Large"
+// @Router /api/approvals/{id}/reject [post]
+func (server *clientsetStruct) approvalRejectHandler(w http.ResponseWriter, r *http.Request) {
+	server.decideApproval(w, r, approvalRejected)
+}
+
+// decideApproval records the decision of the authenticated user and creates the job on approval.
+// The approval is only closed once the job was created, so a failed creation can be approved again.
+func (server *clientsetStruct) decideApproval(w http.ResponseWriter, r *http.Request, state approvalState) {
+	decision := approvalDecision{}
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxApprovalDecisionBytes)).Decode(&decision)
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	case err != nil && !errors.Is(err, io.EOF):
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	user := userFromContext(r.Context())
+	id := r.PathValue("id")
+	claimed, pending, err := server.approvals.claim(id)
+	switch {
+	case errors.Is(err, errApprovalNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, errApprovalDecided):
+		http.Error(w, fmt.Sprintf("%s: %s", err.Error(), claimed.State), http.StatusConflict)
+		return
+	case errors.Is(err, errApprovalInProgress):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	ctx := log.WithSubsystem(r.Context(), log.SubsystemJobs)
+	ctx = log.WithFields(ctx, zap.String("approval", id), zap.String(log.AlertnameKey, claimed.Alertname), zap.String(log.JobKey, claimed.Job))
+	logger := log.FromContext(ctx)
+	if state == approvalApproved {
+		// No jobs are created during a pause or maintenance window, the job can be approved once it ends
+		if skipReason, _ := server.maintenanceSkipReason(server.currentConfig(), pending.data.alert.Labels, time.Now()); skipReason != "" {
+			logger.Info("Job can not be approved now, it stays pending", zap.String("reason", skipReason))
+			server.approvals.release(id, nil)
+			http.Error(w, "no jobs are created now: "+skipReason, http.StatusConflict)
+			return
+		}
+		if err := server.createApprovedJob(context.WithoutCancel(ctx), pending, user); err != nil {
+			logger.Error("error creating approved job, the job stays pending", zap.String("error", err.Error()))
+			writeJSON(w, http.StatusInternalServerError, server.approvals.release(id, err))
+			return
+		}
+	}
+
+	decided, _, err := server.approvals.decide(id, state, user, decision.Comment)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if state == approvalRejected {
+		logger.Info("Job rejected", zap.String("definition", decided.Definition), zap.String("comment", decision.Comment))
+		metadata.JobsSkippedTotal.WithLabelValues(decided.Alertname, decided.Definition, metadata.SkipReasonRejected).Inc()
+	} else {
+		logger.Info("Job approved", zap.String("definition", decided.Definition), zap.String("comment", decision.Comment))
+	}
+	writeJSON(w, http.StatusOK, decided)
+}
+
+// createApprovedJob creates the job of an approval, or starts its workflow, and records the approving user on the jobs
+func (server *clientsetStruct) createApprovedJob(ctx context.Context, pending *pendingJob, user string) error {
+	if pending.workflow != nil {
+		pending.workflow.approvedBy = user
+		server.workflows.start(ctx, pending.workflow)
+		return nil
+	}
+	jobObject := pending.job
+	if jobObject.Annotations == nil {
+		jobObject.Annotations = make(map[string]string)
+	}
+	jobObject.Annotations[approvedByAnnotation] = user
+	created, err := server.createRemediationJob(ctx, jobObject, pending.file)
+	if err != nil {
+		return err
+	}
+	server.lifecycle.created(ctx, &jobRun{job: created, data: pending.data, settings: &pending.settings})
+	return nil
+}
+
+// @Summary Get approvals UI page
+// @Description Get the page to approve or reject jobs waiting for approval, without the users and comments of the decisions
+// @Tags ui
+// @Produce html
+// @Success 200 {string} string "HTML page"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /ui/approvals [get]
+func (server *clientsetStruct) approvalsUIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(contentType, "text/html")
+
+	tmpl, err := template.ParseFiles(
+		"web/templates/approvals.html.templ",
+		"web/templates/navbar.html.templ",
+	)
+	if err != nil {
+		log.Error("error parsing template", zap.String("error", err.Error()))
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Title      string
+		ShowSearch bool
+		Approvals  []approval
+	}{
+		Title:      "Approvals",
+		ShowSearch: false,
+		Approvals:  withoutIdentities(server.approvals.list()),
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		log.Error("error executing template", zap.String("error", err.Error()))
+		http.Error(w, "", http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set(contentType, applicationJSON)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Error("error encoding response", zap.String("error", err.Error()))
+	}
+}
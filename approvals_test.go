@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestApprovalStore(t *testing.T) {
+	store := newApprovalStore(2)
+	first := &approval{Definition: "openfero-test-firing", key: "a"}
+	if !store.park(first, &pendingJob{}, time.Hour) {
+		t.Fatal("park() = false for a new alert")
+	}
+	if store.park(&approval{key: "a"}, &pendingJob{}, time.Hour) {
+		t.Error("park() = true for an alert which is already pending")
+	}
+
+	decided, pending, err := store.decide(first.ID, approvalApproved, "alice", "looks good")
+	if err != nil || pending == nil {
+		t.Fatalf("decide() error = %v, pending = %v", err, pending)
+	}
+	if decided.State != approvalApproved || decided.DecidedBy != "alice" || decided.Comment != "looks good" || decided.DecidedAt == nil {
+		t.Errorf("unexpected decision: %+v", decided)
+	}
+	if _, _, err := store.decide(first.ID, approvalRejected, "bob", ""); err != errApprovalDecided {
+		t.Errorf("second decide() error = %v, want %v", err, errApprovalDecided)
+	}
+	if _, _, err := store.decide("unknown", approvalApproved, "alice", ""); err != errApprovalNotFound {
+		t.Errorf("decide() of unknown approval error = %v, want %v", err, errApprovalNotFound)
+	}
+
+	// The alert can wait for approval again once decided
+	expiring := &approval{key: "a"}
+	if !store.park(expiring, &pendingJob{}, 10*time.Millisecond) {
+		t.Fatal("park() = false for a decided alert")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for store.list()[0].State != approvalExpired {
+		if time.Now().After(deadline) {
+			t.Fatal("approval did not expire")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, _, err := store.decide(expiring.ID, approvalApproved, "alice", ""); err != errApprovalDecided {
+		t.Errorf("decide() of expired approval error = %v, want %v", err, errApprovalDecided)
+	}
+
+	// A claimed approval is neither claimed again nor expires, a released one stays pending with its error
+	claimed := &approval{key: "c"}
+	store.park(claimed, &pendingJob{}, 10*time.Millisecond)
+	if _, pending, err := store.claim(claimed.ID); err != nil || pending == nil {
+		t.Fatalf("claim() error = %v, pending = %v", err, pending)
+	}
+	if _, _, err := store.claim(claimed.ID); err != errApprovalInProgress {
+		t.Errorf("second claim() error = %v, want %v", err, errApprovalInProgress)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if released := store.release(claimed.ID, errors.New("quota exceeded")); released.State != approvalExpired || released.Error != "quota exceeded" {
+		t.Errorf("release() after the timeout = %+v, want an expired approval with the error", released)
+	}
+	retried := &approval{key: "c"}
+	store.park(retried, &pendingJob{}, time.Hour)
+	store.claim(retried.ID)
+	if released := store.release(retried.ID, errors.New("quota exceeded")); released.State != approvalPending || released.Error != "quota exceeded" {
+		t.Errorf("release() = %+v, want a pending approval with the error", released)
+	}
+	if _, _, err := store.claim(retried.ID); err != nil {
+		t.Errorf("claim() after release error = %v", err)
+	}
+	if decided, _, _ := store.decide(retried.ID, approvalApproved, "alice", ""); decided.Error != "" {
+		t.Errorf("decide() kept the error of the failed attempt: %+v", decided)
+	}
+
+	// Only the latest decisions are kept
+	third := &approval{key: "b"}
+	store.park(third, &pendingJob{}, time.Hour)
+	if _, _, err := store.decide(third.ID, approvalRejected, "bob", ""); err != nil {
+		t.Fatal(err)
+	}
+	approvals := store.list()
+	if len(approvals) != 2 || approvals[0].ID != third.ID || approvals[1].ID != retried.ID {
+		t.Errorf("list() = %+v, want the two latest decisions", approvals)
+	}
+}
+
+func TestApprovalKey(t *testing.T) {
+	group := &hookMessage{GroupKey: "{}:{alertname=\"KubeQuotaAlmostFull\"}"}
+	tests := []struct {
+		name string
+		data alertContext
+		want string
+	}{
+		{name: "Fingerprint", data: alertContext{alert: alert{Fingerprint: "abc", Labels: map[string]string{"a": "b"}}}, want: "def/abc"},
+		{name: "Labels", data: alertContext{alert: alert{Labels: map[string]string{"b": "2", "a": "1"}}}, want: "def/a=1/b=2"},
+		{name: "Group", data: alertContext{group: group, alerts: []alert{}}, want: "def/" + group.GroupKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := approvalKey("def", tt.data); got != tt.want {
+				t.Errorf("approvalKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApprovalHandlers(t *testing.T) {
+	jobDefinition := `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: restart-db
+spec:
+  template:
+    spec:
+      containers:
+        - name: main
+          image: busybox
+      restartPolicy: Never
+`
+	alertStore = make([]alertStoreEntry, 0, 10)
+	configMapStore := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	if err := configMapStore.Add(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "openfero-kubequotaalmostfull-firing", Namespace: "default"},
+		Data: map[string]string{
+			"KubeQuotaAlmostFull": jobDefinition,
+			definitionSettingsKey: "requiresApproval: true\napprovalTimeout: 1h\n",
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	clientset := fake.NewClientset()
+	failCreate := false
+	clientset.PrependReactor("create", "jobs", func(k8stesting.Action) (bool, runtime.Object, error) {
+		if failCreate {
+			return true, nil, errors.New("quota exceeded")
+		}
+		return false, nil, nil
+	})
+	listener := &recordingListener{}
+	server := &clientsetStruct{
+		clientset:               clientset,
+		configmapNamespace:      "default",
+		jobDestinationNamespace: "default",
+		configMapStore:          configMapStore,
+		jobStore:                cache.NewStore(cache.MetaNamespaceKeyFunc),
+		auth:                    newAPIAuthenticator(map[string]string{"secret": "alice"}),
+		lifecycle:               newJobLifecycle(listener),
+		approvals:               newApprovalStore(10),
+	}
+
+	jsonFile, err := os.Open("test/alerts.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer jsonFile.Close()
+	message := hookMessage{}
+	if err := json.NewDecoder(jsonFile).Decode(&message); err != nil {
+		t.Fatal(err)
+	}
+	for index := range message.Alerts {
+		server.createResponseJob(context.Background(), &message, index, "firing")
+	}
+	// Repeated notifications do not park the jobs again
+	server.createResponseJob(context.Background(), &message, 0, "firing")
+
+	jobs, err := clientset.BatchV1().Jobs("default").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs.Items) != 0 {
+		t.Fatalf("created %d jobs before approval, want 0", len(jobs.Items))
+	}
+	approvals := server.approvals.list()
+	if len(approvals) != len(message.Alerts) {
+		t.Fatalf("%d jobs wait for approval, want %d", len(approvals), len(message.Alerts))
+	}
+
+	decide := func(handler http.HandlerFunc, id string, token string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/approvals/"+id, strings.NewReader(body))
+		req.SetPathValue("id", id)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		server.auth.requireAuth(handler)(recorder, req)
+		return recorder
+	}
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		id         string
+		token      string
+		body       string
+		failCreate bool
+		paused     bool
+		wantCode   int
+		wantState  approvalState
+	}{
+		{name: "Unauthenticated", handler: server.approvalApproveHandler, id: approvals[0].ID, wantCode: http.StatusUnauthorized},
+		{name: "Unknown approval", handler: server.approvalApproveHandler, id: "unknown", token: "secret", wantCode: http.StatusNotFound},
+		{name: "Body too large", handler: server.approvalApproveHandler, id: approvals[0].ID, token: "secret", body: `{"comment":"` + strings.Repeat("x", maxApprovalDecisionBytes) + `"}`, wantCode: http.StatusRequestEntityTooLarge},
+		{name: "Approve during pause", handler: server.approvalApproveHandler, id: approvals[0].ID, token: "secret", paused: true, wantCode: http.StatusConflict},
+		{name: "Job creation fails", handler: server.approvalApproveHandler, id: approvals[0].ID, token: "secret", failCreate: true, wantCode: http.StatusInternalServerError, wantState: approvalPending},
+		{name: "Approve", handler: server.approvalApproveHandler, id: approvals[0].ID, token: "secret", body: `{"comment":"db is stuck"}`, wantCode: http.StatusOK, wantState: approvalApproved},
+		{name: "Approve twice", handler: server.approvalApproveHandler, id: approvals[0].ID, token: "secret", wantCode: http.StatusConflict},
+		{name: "Reject", handler: server.approvalRejectHandler, id: approvals[1].ID, token: "secret", wantCode: http.StatusOK, wantState: approvalRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failCreate = tt.failCreate
+			cfg := config.Default()
+			cfg.Maintenance.Paused = tt.paused
+			server.config.Store(cfg)
+			recorder := decide(tt.handler, tt.id, tt.token, tt.body)
+			if recorder.Code != tt.wantCode {
+				t.Fatalf("handler returned %d, want %d: %s", recorder.Code, tt.wantCode, recorder.Body.String())
+			}
+			if tt.paused {
+				for _, entry := range server.approvals.list() {
+					if entry.ID == tt.id && entry.State != approvalPending {
+						t.Errorf("approval during pause = %+v, want it pending", entry)
+					}
+				}
+			}
+			if tt.wantState == "" {
+				return
+			}
+			decided := approval{}
+			if err := json.NewDecoder(recorder.Body).Decode(&decided); err != nil {
+				t.Fatal(err)
+			}
+			if tt.failCreate {
+				if decided.State != tt.wantState || decided.Error == "" {
+					t.Errorf("approval after a failed job creation = %+v, want it pending with the error", decided)
+				}
+				return
+			}
+			if decided.State != tt.wantState || decided.DecidedBy != "alice" {
+				t.Errorf("unexpected decision: %+v", decided)
+			}
+		})
+	}
+
+	jobs, err = clientset.BatchV1().Jobs("default").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs.Items) != 1 {
+		t.Fatalf("created %d jobs, want only the approved one", len(jobs.Items))
+	}
+	if jobs.Items[0].Name != approvals[0].Job || jobs.Items[0].Annotations[approvedByAnnotation] != "alice" {
+		t.Errorf("unexpected job %s with annotations %v", jobs.Items[0].Name, jobs.Items[0].Annotations)
+	}
+	if len(listener.created) != 1 {
+		t.Errorf("listener was notified about %d jobs, want 1", len(listener.created))
+	}
+
+	// The alert store shows the state of the approvals
+	wantSkipReasons := map[string]string{
+		approvals[0].ID: "",
+		approvals[1].ID: "approval rejected",
+		approvals[2].ID: "pending approval " + approvals[2].ID,
+	}
+	linked := 0
+	for _, entry := range storedAlerts() {
+		// The repeated notification is not linked, its job was already waiting
+		if entry.Approval == "" {
+			continue
+		}
+		linked++
+		if want := wantSkipReasons[entry.Approval]; entry.SkipReason != want {
+			t.Errorf("alert store entry of approval %s has skip reason %q, want %q", entry.Approval, entry.SkipReason, want)
+		}
+	}
+	if linked != len(wantSkipReasons) {
+		t.Errorf("%d alert store entries are linked to approvals, want %d", linked, len(wantSkipReasons))
+	}
+
+	// The pending job and the decisions are listed
+	recorder := httptest.NewRecorder()
+	server.approvalsUIHandler(recorder, httptest.NewRequest(http.MethodGet, "/ui/approvals", nil))
+	page := recorder.Body.String()
+	if !strings.Contains(page, "/api/approvals/"+approvals[2].ID+"/approve") || !strings.Contains(page, approvals[0].Job) {
+		t.Errorf("approvals page does not show the pending job and the decisions:\n%s", page)
+	}
+	// The page is public, the audit trail with users and comments requires an API token
+	if strings.Contains(page, "alice") || strings.Contains(page, "db is stuck") {
+		t.Errorf("approvals page shows the users or comments of the decisions:\n%s", page)
+	}
+}
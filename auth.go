@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"go.uber.org/zap"
+)
+
+const userKey = "user"
+
+type userContextKey struct{}
+
+// apiAuthenticator checks the bearer tokens of requests to the administrative API
+type apiAuthenticator struct {
+	mu sync.RWMutex
+	// tokens maps a bearer token to the identity of its user
+	tokens map[string]string
+}
+
+func newAPIAuthenticator(tokens map[string]string) *apiAuthenticator {
+	auth := &apiAuthenticator{}
+	auth.setTokens(tokens)
+	return auth
+}
+
+// setTokens replaces the accepted tokens
+func (auth *apiAuthenticator) setTokens(tokens map[string]string) {
+	copied := make(map[string]string, len(tokens))
+	for token, user := range tokens {
+		if token != "" {
+			copied[token] = user
+		}
+	}
+	auth.mu.Lock()
+	defer auth.mu.Unlock()
+	auth.tokens = copied
+}
+
+// enabled reports whether any token is configured
+func (auth *apiAuthenticator) enabled() bool {
+	auth.mu.RLock()
+	defer auth.mu.RUnlock()
+	return len(auth.tokens) > 0
+}
+
+// authenticate returns the user of the bearer token sent with the request
+func (auth *apiAuthenticator) authenticate(r *http.Request) (string, bool) {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		return "", false
+	}
+
+	auth.mu.RLock()
+	defer auth.mu.RUnlock()
+	user, matched := "", false
+	// Compare against every token to not leak which tokens exist via timing
+	for candidate, candidateUser := range auth.tokens {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			user, matched = candidateUser, true
+		}
+	}
+	return user, matched
+}
+
+// requireAuth only passes authenticated requests to the handler and adds the user to the request context
+func (auth *apiAuthenticator) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !auth.enabled() {
+			http.Error(w, "API authentication is not configured", http.StatusForbidden)
+			return
+		}
+		user, ok := auth.authenticate(r)
+		if !ok {
+			log.FromContext(r.Context()).Warn("Unauthorized API request", zap.String("path", r.URL.Path))
+			w.Header().Set("WWW-Authenticate", `Bearer realm="openfero"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		ctx := context.WithValue(r.Context(), userContextKey{}, user)
+		ctx = log.WithFields(ctx, zap.String(userKey, user))
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// userFromContext returns the authenticated user of a request
+func userFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(userContextKey{}).(string)
+	return user
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireAuth(t *testing.T) {
+	tests := []struct {
+		name          string
+		tokens        map[string]string
+		authorization string
+		wantStatus    int
+		wantUser      string
+	}{
+		{
+			name:       "No tokens configured",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Missing token",
+			tokens:     map[string]string{"secret": "admin"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:          "Wrong token",
+			tokens:        map[string]string{"secret": "admin"},
+			authorization: "Bearer wrong",
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "Wrong scheme",
+			tokens:        map[string]string{"secret": "admin"},
+			authorization: "Basic secret",
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "Valid token",
+			tokens:        map[string]string{"secret": "admin", "other": "operator"},
+			authorization: "Bearer other",
+			wantStatus:    http.StatusOK,
+			wantUser:      "operator",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := ""
+			handler := newAPIAuthenticator(tt.tokens).requireAuth(func(w http.ResponseWriter, r *http.Request) {
+				user = userFromContext(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/loglevel", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rr := httptest.NewRecorder()
+			handler(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if user != tt.wantUser {
+				t.Errorf("user = %q, want %q", user, tt.wantUser)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"go.uber.org/zap"
+
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+// Types of the job lifecycle events
+const (
+	eventTypeJobCreated   = "openfero.job.created"
+	eventTypeJobSucceeded = "openfero.job.succeeded"
+	eventTypeJobFailed    = "openfero.job.failed"
+)
+
+// cloudEventTimeout limits the delivery of a single event to the sink
+const cloudEventTimeout = 10 * time.Second
+
+// Annotations the attributes of a received event are mapped to
+const (
+	cloudEventIDAnnotation      = "cloudevents_id"
+	cloudEventSourceAnnotation  = "cloudevents_source"
+	cloudEventTypeAnnotation    = "cloudevents_type"
+	cloudEventSubjectAnnotation = "cloudevents_subject"
+)
+
+// defaultCloudEventMapping maps events whose type has no mapping, the alertname is taken from the extension attribute alertname
+var defaultCloudEventMapping = config.HookSource{Alertname: "{.alertname}"}
+
+// jobEventData is the data of the job lifecycle events
+type jobEventData struct {
+	Job        string `json:"job"`
+	Namespace  string `json:"namespace"`
+	Definition string `json:"definition,omitempty"`
+	Alertname  string `json:"alertname,omitempty"`
+	GroupKey   string `json:"groupKey,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// cloudEventEmitter sends the lifecycle of jobs as CloudEvents to a sink
+type cloudEventEmitter struct {
+	client cloudevents.Client
+	sink   string
+	source string
+}
+
+func newCloudEventEmitter(cfg config.CloudEvents) (*cloudEventEmitter, error) {
+	client, err := cloudevents.NewClientHTTP()
+	if err != nil {
+		return nil, err
+	}
+	return &cloudEventEmitter{client: client, sink: cfg.Sink, source: cfg.Source}, nil
+}
+
+// jobCreated sends openfero.job.created, the delivery does not delay the creation of further jobs
+func (emitter *cloudEventEmitter) jobCreated(ctx context.Context, run *jobRun) {
+	event, err := emitter.newJobEvent(eventTypeJobCreated, run.job, "")
+	if err != nil {
+		log.FromContext(ctx).Error("error creating CloudEvent", zap.String("error", err.Error()))
+		return
+	}
+	go emitter.send(context.WithoutCancel(ctx), event)
+}
+
+// jobFinished sends openfero.job.succeeded or openfero.job.failed
+func (emitter *cloudEventEmitter) jobFinished(run *jobRun, outcome jobOutcome, reason string) {
+	eventType := eventTypeJobSucceeded
+	if outcome == jobOutcomeFailed {
+		eventType = eventTypeJobFailed
+	}
+	event, err := emitter.newJobEvent(eventType, run.job, reason)
+	if err != nil {
+		log.Error("error creating CloudEvent", zap.String("error", err.Error()))
+		return
+	}
+	go emitter.send(context.Background(), event)
+}
+
+// newJobEvent creates a lifecycle event of the job. The ID is derived from the job UID,
+// so the sink can detect duplicates. The trace context of the job is passed as distributed tracing extension.
+func (emitter *cloudEventEmitter) newJobEvent(eventType string, job *batchv1.Job, reason string) (cloudevents.Event, error) {
+	event := cloudevents.NewEvent()
+	event.SetID(fmt.Sprintf("%s-%s", job.UID, eventType))
+	event.SetSource(emitter.source)
+	event.SetType(eventType)
+	event.SetSubject(job.Name)
+	event.SetTime(time.Now())
+	if traceparent := job.Annotations[traceparentAnnotation]; traceparent != "" {
+		event.SetExtension("traceparent", traceparent)
+	}
+	err := event.SetData(cloudevents.ApplicationJSON, jobEventData{
+		Job:        job.Name,
+		Namespace:  job.Namespace,
+		Definition: job.Annotations[definitionAnnotation],
+		Alertname:  job.Annotations[alertnameAnnotation],
+		GroupKey:   job.Annotations[groupKeyAnnotation],
+		Reason:     reason,
+	})
+	return event, err
+}
+
+func (emitter *cloudEventEmitter) send(ctx context.Context, event cloudevents.Event) {
+	ctx, cancel := context.WithTimeout(ctx, cloudEventTimeout)
+	defer cancel()
+
+	logger := log.FromContext(ctx).With(zap.String("type", event.Type()), zap.String("job", event.Subject()))
+	if result := emitter.client.Send(cloudevents.ContextWithTarget(ctx, emitter.sink), event); !cloudevents.IsACK(result) {
+		logger.Error("error sending CloudEvent", zap.String("error", result.Error()))
+		return
+	}
+	logger.Debug("CloudEvent sent")
+}
+
+// mapCloudEvent maps a received event to an Alertmanager message by the mapping of its type.
+// The mappings are evaluated against the event in structured JSON format.
+func mapCloudEvent(cfg config.CloudEvents, event *cloudevents.Event) (hookMessage, error) {
+	source, ok := cfg.Types[event.Type()]
+	if !ok {
+		source = defaultCloudEventMapping
+	}
+	structured, err := json.Marshal(event)
+	if err != nil {
+		return hookMessage{}, fmt.Errorf("error encoding event: %w", err)
+	}
+	payload, err := decodeHookPayload(structured)
+	if err != nil {
+		return hookMessage{}, fmt.Errorf("error decoding event: %w", err)
+	}
+	alerts, err := mapHookAlerts(source, payload)
+	if err != nil {
+		return hookMessage{}, err
+	}
+
+	attributes := nonEmpty(map[string]string{
+		cloudEventIDAnnotation:      event.ID(),
+		cloudEventSourceAnnotation:  event.Source(),
+		cloudEventTypeAnnotation:    event.Type(),
+		cloudEventSubjectAnnotation: event.Subject(),
+	})
+	for i := range alerts {
+		alerts[i].Annotations = addMissing(alerts[i].Annotations, attributes)
+		if !event.Time().IsZero() {
+			alerts[i].StartsAt = event.Time().UTC().Format(time.RFC3339)
+		}
+	}
+	return newHookMessage("cloudevents/"+event.Type(), "cloudevents", map[string]string{"type": event.Type()}, alerts), nil
+}
+
+// @Summary Process incoming CloudEvents
+// @Description Map a CloudEvent in binary or structured HTTP mode to alerts by the mapping of its type and process them like alerts from Alertmanager
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param event body object true "CloudEvent"
+// @Param sync query bool false "Create the jobs before answering, overrides webhook.synchronous"
+// @Success 200 {object} webhookResponse "Jobs created, synchronous mode"
+// @Success 202 {object} webhookResponse "Jobs queued"
+// @Failure 400 {object} webhookError "Invalid event, status or no valid alert"
+// @Failure 413 {object} webhookError "Body or number of alerts exceeds the limits"
+// @Router /cloudevents [post]
+// Handling the CloudEvents Post-Requests
+func (server *clientsetStruct) cloudEventsPostHandler(httpwriter http.ResponseWriter, httprequest *http.Request) {
+	ctx, span := startWebhookSpan(httprequest, "cloudevents")
+	defer span.End()
+
+	cfg := server.currentConfig()
+	message, ok := decodeCloudEvent(ctx, httpwriter, httprequest, cfg.CloudEvents, cfg.Limits)
+	if !ok || !acceptHookMessage(ctx, httpwriter, &message, cfg.Limits) {
+		return
+	}
+	server.respondToWebhook(ctx, httpwriter, httprequest, &message)
+}
+
+// decodeCloudEvent decodes the CloudEvent of a request and maps it to a message. Bodies larger than the limit are
+// answered with 413, invalid events and events which can not be mapped to alerts with 400.
+func decodeCloudEvent(ctx context.Context, httpwriter http.ResponseWriter, httprequest *http.Request, cfg config.CloudEvents, limits config.Limits) (hookMessage, bool) {
+	// The SDK reads the whole body, so it gets the body after the limit was checked
+	body, err := io.ReadAll(http.MaxBytesReader(httpwriter, httprequest.Body, limits.MaxBodyBytes))
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeWebhookError(ctx, httpwriter, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", maxBytesErr.Limit))
+		return hookMessage{}, false
+	}
+	if err != nil {
+		writeWebhookError(ctx, httpwriter, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return hookMessage{}, false
+	}
+	httprequest.Body = io.NopCloser(bytes.NewReader(body))
+
+	event, err := cehttp.NewEventFromHTTPRequest(httprequest)
+	if err == nil {
+		err = event.Validate()
+	}
+	if err != nil {
+		writeWebhookError(ctx, httpwriter, http.StatusBadRequest, "invalid CloudEvent: "+err.Error())
+		return hookMessage{}, false
+	}
+
+	message, err := mapCloudEvent(cfg, event)
+	if err != nil {
+		writeWebhookError(ctx, httpwriter, http.StatusBadRequest, "event could not be mapped to alerts: "+err.Error())
+		return hookMessage{}, false
+	}
+	return message, true
+}
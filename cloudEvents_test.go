@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newTestCloudEvent(t *testing.T, eventType string, data interface{}) *cloudevents.Event {
+	t.Helper()
+	event := cloudevents.NewEvent()
+	event.SetID("1")
+	event.SetSource("/apis/v1/namespaces/default")
+	event.SetType(eventType)
+	event.SetSubject("web")
+	if err := event.SetData(cloudevents.ApplicationJSON, data); err != nil {
+		t.Fatal(err)
+	}
+	return &event
+}
+
+func TestMapCloudEvent(t *testing.T) {
+	cfg := config.CloudEvents{Types: map[string]config.HookSource{
+		"dev.knative.apiserver.resource.update": {
+			Alertname: "DeploymentChanged",
+			Labels:    map[string]string{"deployment": "{.data.name}", "replicas": "{.data.replicas}"},
+		},
+	}}
+	withExtension := newTestCloudEvent(t, "com.example.build", map[string]string{})
+	withExtension.SetExtension("alertname", "BuildFailed")
+
+	tests := []struct {
+		name            string
+		event           *cloudevents.Event
+		wantLabels      map[string]string
+		wantAnnotations map[string]string
+		wantErr         bool
+	}{
+		{
+			name:       "Mapping of the type",
+			event:      newTestCloudEvent(t, "dev.knative.apiserver.resource.update", map[string]interface{}{"name": "web", "replicas": 3}),
+			wantLabels: map[string]string{"alertname": "DeploymentChanged", "deployment": "web", "replicas": "3"},
+			wantAnnotations: map[string]string{
+				cloudEventIDAnnotation:      "1",
+				cloudEventSourceAnnotation:  "/apis/v1/namespaces/default",
+				cloudEventTypeAnnotation:    "dev.knative.apiserver.resource.update",
+				cloudEventSubjectAnnotation: "web",
+			},
+		},
+		{
+			name:       "Alertname extension",
+			event:      withExtension,
+			wantLabels: map[string]string{"alertname": "BuildFailed"},
+		},
+		{
+			name:    "Unmapped type without alertname",
+			event:   newTestCloudEvent(t, "com.example.other", map[string]string{}),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := mapCloudEvent(cfg, tt.event)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mapCloudEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if message.Status != "firing" || message.GroupKey != "cloudevents/"+tt.event.Type() || len(message.Alerts) != 1 {
+				t.Fatalf("unexpected message: %+v", message)
+			}
+			alert := message.Alerts[0]
+			for key, want := range tt.wantLabels {
+				if got := alert.Labels[key]; got != want {
+					t.Errorf("label %s = %q, want %q", key, got, want)
+				}
+			}
+			for key, want := range tt.wantAnnotations {
+				if got := alert.Annotations[key]; got != want {
+					t.Errorf("annotation %s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCloudEventsPostHandler(t *testing.T) {
+	jobDefinition := `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: build
+spec:
+  template:
+    spec:
+      containers:
+        - name: main
+          image: busybox
+      restartPolicy: Never
+`
+	structured := `{"specversion":"1.0","id":"2","source":"ci","type":"com.example.build","alertname":"BuildFailed","datacontenttype":"application/json","data":{}}`
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		body     string
+		wantCode int
+		wantJob  bool
+	}{
+		{
+			name: "Binary mode",
+			header: http.Header{
+				"Content-Type":   {"application/json"},
+				"Ce-Specversion": {"1.0"},
+				"Ce-Id":          {"1"},
+				"Ce-Source":      {"ci"},
+				"Ce-Type":        {"com.example.build"},
+				"Ce-Alertname":   {"BuildFailed"},
+			},
+			body:     `{}`,
+			wantCode: http.StatusAccepted,
+			wantJob:  true,
+		},
+		{
+			name:     "Structured mode",
+			header:   http.Header{"Content-Type": {"application/cloudevents+json"}},
+			body:     structured,
+			wantCode: http.StatusAccepted,
+			wantJob:  true,
+		},
+		{
+			name:     "No CloudEvent",
+			header:   http.Header{"Content-Type": {"application/json"}},
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Missing attribute",
+			header:   http.Header{"Content-Type": {"application/cloudevents+json"}},
+			body:     `{"specversion":"1.0","source":"ci","type":"com.example.build"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "Body too large",
+			header:   http.Header{"Content-Type": {"application/cloudevents+json"}},
+			body:     strings.Replace(structured, `"data":{}`, `"data":{"log":"`+strings.Repeat("x", 5000)+`"}`, 1),
+			wantCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "Invalid alert",
+			header:   http.Header{"Content-Type": {"application/cloudevents+json"}},
+			body:     strings.Replace(structured, `"BuildFailed"`, `"BuildFailedOnTheMainBranch"`, 1),
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alertStore = make([]alertStoreEntry, 0, 10)
+			configMapStore := cache.NewStore(cache.MetaNamespaceKeyFunc)
+			if err := configMapStore.Add(&v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "openfero-buildfailed-firing", Namespace: "default"},
+				Data:       map[string]string{"BuildFailed": jobDefinition},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			clientset := fake.NewClientset()
+			server := &clientsetStruct{
+				clientset:               clientset,
+				configmapNamespace:      "default",
+				jobDestinationNamespace: "default",
+				configMapStore:          configMapStore,
+				jobStore:                cache.NewStore(cache.MetaNamespaceKeyFunc),
+			}
+			cfg := config.Default()
+			cfg.Limits = testLimits
+			server.config.Store(cfg)
+
+			req := httptest.NewRequest("POST", "/cloudevents", strings.NewReader(tt.body))
+			req.Header = tt.header
+			responserecorder := httptest.NewRecorder()
+
+			server.cloudEventsPostHandler(responserecorder, req)
+
+			if status := responserecorder.Code; status != tt.wantCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantCode)
+			}
+			if tt.wantCode >= http.StatusBadRequest {
+				decodeWebhookError(t, responserecorder)
+			}
+			if !tt.wantJob {
+				return
+			}
+			// Jobs are created in the background
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				jobs, err := clientset.BatchV1().Jobs("default").List(context.Background(), metav1.ListOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(jobs.Items) == 1 {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("created %d jobs, want 1", len(jobs.Items))
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestCloudEventEmitter(t *testing.T) {
+	received := make(chan cloudevents.Event, 3)
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event, err := cehttp.NewEventFromHTTPRequest(r)
+		if err != nil {
+			t.Errorf("sink received invalid event: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- *event
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer sink.Close()
+
+	emitter, err := newCloudEventEmitter(config.CloudEvents{Sink: sink.URL, Source: "openfero"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name:      "quota-abc12",
+		Namespace: "default",
+		UID:       "uid-1",
+		Annotations: map[string]string{
+			definitionAnnotation:  "openfero-kubequotaalmostfull-firing",
+			alertnameAnnotation:   "KubeQuotaAlmostFull",
+			traceparentAnnotation: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		},
+	}}
+
+	tests := []struct {
+		name       string
+		emit       func()
+		wantType   string
+		wantReason string
+	}{
+		{name: "Created", emit: func() { emitter.jobCreated(context.Background(), &jobRun{job: job}) }, wantType: eventTypeJobCreated},
+		{name: "Succeeded", emit: func() { emitter.jobFinished(&jobRun{job: job}, jobOutcomeSucceeded, "") }, wantType: eventTypeJobSucceeded},
+		{name: "Failed", emit: func() { emitter.jobFinished(&jobRun{job: job}, jobOutcomeFailed, "DeadlineExceeded") }, wantType: eventTypeJobFailed, wantReason: "DeadlineExceeded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.emit()
+			var event cloudevents.Event
+			select {
+			case event = <-received:
+			case <-time.After(5 * time.Second):
+				t.Fatal("sink did not receive an event")
+			}
+
+			if event.Type() != tt.wantType || event.Source() != "openfero" || event.Subject() != job.Name || event.ID() != "uid-1-"+tt.wantType {
+				t.Errorf("unexpected event attributes: %s", event.String())
+			}
+			if traceparent := event.Extensions()["traceparent"]; traceparent != job.Annotations[traceparentAnnotation] {
+				t.Errorf("traceparent = %v", traceparent)
+			}
+			data := jobEventData{}
+			if err := json.Unmarshal(event.Data(), &data); err != nil {
+				t.Fatal(err)
+			}
+			want := jobEventData{Job: job.Name, Namespace: "default", Definition: "openfero-kubequotaalmostfull-firing", Alertname: "KubeQuotaAlmostFull", Reason: tt.wantReason}
+			if data != want {
+				t.Errorf("data = %+v, want %+v", data, want)
+			}
+		})
+	}
+}
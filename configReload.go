@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"strconv"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// currentConfig returns the active configuration
+func (server *clientsetStruct) currentConfig() *config.Config {
+	if cfg := server.config.Load(); cfg != nil {
+		return cfg
+	}
+	return config.Default()
+}
+
+// reloadConfig applies the settings which can change at runtime: log levels, API tokens, policy, job defaults, injection and hook sources.
+// All other changes are logged and take effect with the next restart.
+func (server *clientsetStruct) reloadConfig(cfg *config.Config) {
+	old := server.currentConfig()
+	if sections := config.RestartRequired(old, cfg); len(sections) > 0 {
+		log.Warn("Configuration changes require a restart to take effect", zap.Strings("sections", sections))
+	}
+
+	// Levels changed via the API are only replaced if the file changed them as well
+	if old.Logging.Level != cfg.Logging.Level {
+		if err := log.SetLevel(cfg.Logging.Level); err != nil {
+			log.Error("error setting log level", zap.String("error", err.Error()))
+		}
+	}
+	if !maps.Equal(old.Logging.Subsystems, cfg.Logging.Subsystems) {
+		for _, subsystem := range log.Subsystems {
+			if err := log.SetSubsystemLevel(subsystem, cfg.Logging.Subsystems[subsystem]); err != nil {
+				log.Error("error setting log level", zap.String("subsystem", subsystem), zap.String("error", err.Error()))
+			}
+		}
+	}
+
+	if server.auth != nil {
+		server.auth.setTokens(apiTokens(cfg.Auth))
+	}
+	if server.limiter != nil {
+		server.limiter.update(cfg.RateLimit)
+	}
+	server.config.Store(cfg)
+	log.Info("Configuration reloaded")
+}
+
+// apiTokens maps the configured tokens to their users
+func apiTokens(auth config.Auth) map[string]string {
+	tokens := make(map[string]string, len(auth.Tokens))
+	for _, token := range auth.Tokens {
+		tokens[token.Token] = token.User
+	}
+	return tokens
+}
+
+// flagOverrides returns a function which applies all explicitly set command line flags to the configuration
+func flagOverrides() func(*config.Config) error {
+	var set []*flag.Flag
+	flag.Visit(func(f *flag.Flag) {
+		set = append(set, f)
+	})
+	return func(cfg *config.Config) error {
+		for _, f := range set {
+			if err := applyFlag(cfg, f.Name, f.Value.String()); err != nil {
+				return fmt.Errorf("invalid value of flag -%s: %w", f.Name, err)
+			}
+		}
+		return nil
+	}
+}
+
+// applyFlag sets the configuration setting of a command line flag
+func applyFlag(cfg *config.Config, name string, value string) error {
+	var err error
+	switch name {
+	case "addr":
+		cfg.Server.Addr = value
+	case "readTimeout":
+		cfg.Server.ReadTimeout, err = parseSeconds(value)
+	case "writeTimeout":
+		cfg.Server.WriteTimeout, err = parseSeconds(value)
+	case "alertStoreSize":
+		cfg.Store.AlertStoreSize, err = strconv.Atoi(value)
+	case "configmapNamespace":
+		cfg.Namespaces.Configmap = value
+	case "jobDestinationNamespace":
+		cfg.Namespaces.JobDestination = value
+	case "logLevel":
+		cfg.Logging.Level = value
+	case "logFormat":
+		cfg.Logging.Format = value
+	case "logStacktrace":
+		cfg.Logging.Stacktrace, err = strconv.ParseBool(value)
+	case "logSubsystemLevels":
+		cfg.Logging.Subsystems, err = parseSubsystemLevels(value)
+	case "otelExporter":
+		cfg.Tracing.Exporter = value
+	case "otelEndpoint":
+		cfg.Tracing.Endpoint = value
+	case "otelInsecure":
+		cfg.Tracing.Insecure, err = strconv.ParseBool(value)
+	case "otelSampleRatio":
+		cfg.Tracing.SampleRatio, err = strconv.ParseFloat(value, 64)
+	}
+	return err
+}
+
+func parseSeconds(value string) (config.Duration, error) {
+	seconds, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	return config.Duration(time.Duration(seconds) * time.Second), nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"k8s.io/utils/ptr"
+)
+
+func TestApplyFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		value   string
+		check   func(*config.Config) bool
+		wantErr bool
+	}{
+		{name: "Address", flag: "addr", value: ":9090", check: func(cfg *config.Config) bool { return cfg.Server.Addr == ":9090" }},
+		{name: "Timeout in seconds", flag: "readTimeout", value: "30", check: func(cfg *config.Config) bool { return cfg.Server.ReadTimeout.Duration() == 30*time.Second }},
+		{name: "Namespace", flag: "jobDestinationNamespace", value: "jobs", check: func(cfg *config.Config) bool { return cfg.Namespaces.JobDestination == "jobs" }},
+		{name: "Subsystem levels", flag: "logSubsystemLevels", value: "jobs=debug", check: func(cfg *config.Config) bool { return cfg.Logging.Subsystems["jobs"] == "debug" }},
+		{name: "Invalid number", flag: "alertStoreSize", value: "many", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			err := applyFlag(cfg, tt.flag, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyFlag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.check != nil && !tt.check(cfg) {
+				t.Errorf("applyFlag() did not apply -%s=%s: %+v", tt.flag, tt.value, cfg)
+			}
+		})
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	t.Cleanup(func() {
+		if err := initLogger(config.Logging{Level: "debug", Format: log.FormatConsole}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	server := &clientsetStruct{auth: newAPIAuthenticator(nil)}
+	server.config.Store(config.Default())
+
+	cfg := config.Default()
+	cfg.Auth.Tokens = []config.Token{{User: "alice", Token: "secret"}}
+	cfg.Logging.Level = "warn"
+	cfg.Logging.Subsystems = map[string]string{"jobs": "debug"}
+	cfg.Defaults.TTLSecondsAfterFinished = ptr.To[int32](60)
+	server.reloadConfig(cfg)
+
+	if *server.currentConfig().Defaults.TTLSecondsAfterFinished != 60 {
+		t.Error("reloadConfig() did not store the new configuration")
+	}
+	level, subsystems := log.Levels()
+	if level != "warn" || subsystems["jobs"] != "debug" {
+		t.Errorf("reloadConfig() log levels = %s, %v", level, subsystems)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/loglevel", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	if user, ok := server.auth.authenticate(req); !ok || user != "alice" {
+		t.Errorf("reloadConfig() did not apply the API tokens, got user %q", user)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	"github.com/ghodss/yaml"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// definitionSettingsKey is the data key of a definition ConfigMap holding OpenFero settings instead of a job definition.
+// Alertnames cannot contain dots, so the key never collides with a job definition.
+const definitionSettingsKey = "openfero.yaml"
+
+// Execution modes of a definition
+const (
+	// modePerAlert creates a job for every alert of a notification
+	modePerAlert = "perAlert"
+	// modePerGroup creates a single job for all alerts of a notification
+	modePerGroup = "perGroup"
+)
+
+// definitionSettings are the OpenFero settings of a single job definition
+type definitionSettings struct {
+	// JobDefaults override the global job defaults for this definition
+	JobDefaults config.Defaults `json:"jobDefaults"`
+	// Injection overrides how the alert context is passed to the containers
+	Injection config.Injection `json:"injection"`
+	// Mode is perAlert or perGroup, defaults to perAlert
+	Mode string `json:"mode,omitempty"`
+	// Alertmanager overrides what is written back to Alertmanager
+	Alertmanager config.WriteBack `json:"alertmanager"`
+	// Notifications replace the global notification routes for the jobs of this definition
+	Notifications []config.Route `json:"notifications,omitempty"`
+	// RequiresApproval parks jobs as pending until a user approves them
+	RequiresApproval bool `json:"requiresApproval,omitempty"`
+	// ApprovalTimeout overrides after which time pending jobs are dropped
+	ApprovalTimeout config.Duration `json:"approvalTimeout,omitempty"`
+	// Workflow runs several jobs of the ConfigMap as steps instead of the job of the alertname
+	Workflow *workflowSpec `json:"workflow,omitempty"`
+	// Schedule additionally runs the definition on a cron schedule
+	Schedule *scheduleSpec `json:"schedule,omitempty"`
+	// Priority of the jobs when the job creation is rate limited, defaults to the priority of the severity of the alerts
+	Priority string `json:"priority,omitempty"`
+	// rateLimit maps the severity of the alerts to the priority of the jobs
+	rateLimit config.RateLimit
+}
+
+// withGlobal returns the settings with all unset values taken from the global configuration
+func (settings definitionSettings) withGlobal(cfg *config.Config) definitionSettings {
+	merged := definitionSettings{
+		JobDefaults:      cfg.Defaults.Merge(settings.JobDefaults),
+		Injection:        cfg.Injection.Merge(settings.Injection),
+		Mode:             settings.Mode,
+		Alertmanager:     cfg.Alertmanager.WriteBack.Merge(settings.Alertmanager),
+		Notifications:    settings.Notifications,
+		RequiresApproval: settings.RequiresApproval,
+		ApprovalTimeout:  settings.ApprovalTimeout,
+		Workflow:         settings.Workflow,
+		Schedule:         settings.Schedule,
+		Priority:         settings.Priority,
+		rateLimit:        cfg.RateLimit,
+	}
+	if len(merged.Notifications) == 0 {
+		merged.Notifications = cfg.Notifications.Routes
+	}
+	if merged.ApprovalTimeout == 0 {
+		merged.ApprovalTimeout = cfg.Approvals.Timeout
+	}
+	if merged.Mode == "" {
+		merged.Mode = modePerAlert
+	}
+	// Jobs per group receive their alerts as file
+	if merged.Mode == modePerGroup && !hasAlertFile(merged.Injection) {
+		merged.Injection.AlertFile = config.AlertFileConfigMap
+	}
+	return merged
+}
+
+// parseDefinitionSettings reads the settings stored in a definition ConfigMap.
+// A ConfigMap without settings returns empty settings.
+func parseDefinitionSettings(configMap *v1.ConfigMap) (definitionSettings, error) {
+	settings := definitionSettings{}
+	data, ok := configMap.Data[definitionSettingsKey]
+	if !ok {
+		return settings, nil
+	}
+
+	jsonBytes, err := yaml.YAMLToJSON([]byte(data))
+	if err != nil {
+		return settings, fmt.Errorf("error while converting YAML settings to JSON: %w", err)
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(jsonBytes)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&settings); err != nil {
+		return settings, fmt.Errorf("invalid settings in %s: %w", definitionSettingsKey, err)
+	}
+	var modeErr error
+	if settings.Mode != "" && settings.Mode != modePerAlert && settings.Mode != modePerGroup {
+		modeErr = fmt.Errorf("mode must be %s or %s", modePerAlert, modePerGroup)
+	}
+	errs := []error{settings.JobDefaults.Validate("jobDefaults"), settings.Injection.Validate("injection"), modeErr}
+	if settings.ApprovalTimeout < 0 {
+		errs = append(errs, errors.New("approvalTimeout must not be negative"))
+	}
+	for i, route := range settings.Notifications {
+		errs = append(errs, route.Validate(fmt.Sprintf("notifications[%d]", i)))
+	}
+	errs = append(errs, settings.Workflow.validate(configMap.Data))
+	errs = append(errs, settings.Schedule.validate())
+	if settings.Priority != "" && !config.ValidPriority(settings.Priority) {
+		errs = append(errs, fmt.Errorf("priority must be %s, %s or %s", config.PriorityHigh, config.PriorityNormal, config.PriorityLow))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return settings, fmt.Errorf("invalid settings in %s: %w", definitionSettingsKey, err)
+	}
+	return settings, nil
+}
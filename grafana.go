@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// Annotations the Grafana specific fields of a notification are mapped to
+const (
+	grafanaOrgIDAnnotation        = "grafana_org_id"
+	grafanaStateAnnotation        = "grafana_state"
+	grafanaTitleAnnotation        = "grafana_title"
+	grafanaMessageAnnotation      = "grafana_message"
+	grafanaDashboardURLAnnotation = "grafana_dashboard_url"
+	grafanaPanelURLAnnotation     = "grafana_panel_url"
+	grafanaSilenceURLAnnotation   = "grafana_silence_url"
+	grafanaImageURLAnnotation     = "grafana_image_url"
+	grafanaValuesAnnotation       = "grafana_values"
+	grafanaValueStringAnnotation  = "grafana_value_string"
+)
+
+// @Description Webhook message from Grafana unified alerting, an Alertmanager message with Grafana specific fields
+type grafanaMessage struct {
+	hookMessage
+	// @Description ID of the Grafana organization
+	OrgID int64 `json:"orgId"`
+	// @Description State of the alert group (alerting/ok)
+	State string `json:"state" example:"alerting"`
+	// @Description Rendered title of the notification
+	Title string `json:"title"`
+	// @Description Rendered message of the notification
+	Message string `json:"message"`
+	// @Description List of alerts in the group
+	Alerts []grafanaAlert `json:"alerts"`
+}
+
+// @Description Alert information from Grafana unified alerting
+type grafanaAlert struct {
+	alert
+	// @Description Values of the queries and expressions of the alert rule
+	Values map[string]float64 `json:"values,omitempty"`
+	// @Description Values of the alert rule as text
+	ValueString string `json:"valueString,omitempty"`
+	// @Description URL of the dashboard of the alert rule
+	DashboardURL string `json:"dashboardURL,omitempty"`
+	// @Description URL of the panel of the alert rule
+	PanelURL string `json:"panelURL,omitempty"`
+	// @Description URL to silence the alert
+	SilenceURL string `json:"silenceURL,omitempty"`
+	// @Description URL of a screenshot of the panel
+	ImageURL string `json:"imageURL,omitempty"`
+}
+
+// toHookMessage maps the Grafana message into the Alertmanager message OpenFero processes.
+// The Grafana specific fields become annotations, without replacing annotations set by the alert rule.
+func (message grafanaMessage) toHookMessage() hookMessage {
+	hook := message.hookMessage
+	if hook.Status == "" {
+		hook.Status = grafanaStatus(message.State)
+	}
+	hook.CommonAnnotations = addMissing(cloneMap(hook.CommonAnnotations), nonEmpty(map[string]string{
+		grafanaOrgIDAnnotation:   strconv.FormatInt(message.OrgID, 10),
+		grafanaStateAnnotation:   message.State,
+		grafanaTitleAnnotation:   message.Title,
+		grafanaMessageAnnotation: message.Message,
+	}))
+
+	hook.Alerts = make([]alert, 0, len(message.Alerts))
+	for _, grafanaAlert := range message.Alerts {
+		alert := grafanaAlert.alert
+		extra := map[string]string{
+			grafanaDashboardURLAnnotation: grafanaAlert.DashboardURL,
+			grafanaPanelURLAnnotation:     grafanaAlert.PanelURL,
+			grafanaSilenceURLAnnotation:   grafanaAlert.SilenceURL,
+			grafanaImageURLAnnotation:     grafanaAlert.ImageURL,
+			grafanaValueStringAnnotation:  grafanaAlert.ValueString,
+		}
+		if len(grafanaAlert.Values) > 0 {
+			// A map of numbers always encodes
+			values, _ := json.Marshal(grafanaAlert.Values)
+			extra[grafanaValuesAnnotation] = string(values)
+		}
+		alert.Annotations = addMissing(cloneMap(alert.Annotations), nonEmpty(extra))
+		hook.Alerts = append(hook.Alerts, alert)
+	}
+	return hook
+}
+
+// grafanaStatus maps the state of a Grafana notification to the Alertmanager status
+func grafanaStatus(state string) string {
+	switch state {
+	case "alerting":
+		return "firing"
+	case "ok":
+		return "resolved"
+	default:
+		return state
+	}
+}
+
+// nonEmpty removes all entries without a value
+func nonEmpty(values map[string]string) map[string]string {
+	for key, value := range values {
+		if value == "" {
+			delete(values, key)
+		}
+	}
+	return values
+}
+
+func cloneMap(values map[string]string) map[string]string {
+	clone := make(map[string]string, len(values))
+	for key, value := range values {
+		clone[key] = value
+	}
+	return clone
+}
+
+// @Summary Process incoming Grafana alerts
+// @Description Process alerts received from the webhook contact point of Grafana unified alerting
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param message body grafanaMessage true "Grafana alert message"
+// @Param sync query bool false "Create the jobs before answering, overrides webhook.synchronous"
+// @Success 200 {object} webhookResponse "Jobs created, synchronous mode"
+// @Success 202 {object} webhookResponse "Jobs queued"
+// @Failure 400 {object} webhookError "Invalid body, status or no valid alert"
+// @Failure 413 {object} webhookError "Body or number of alerts exceeds the limits"
+// @Router /alerts/grafana [post]
+// Handling the Grafana Post-Requests
+func (server *clientsetStruct) grafanaAlertsPostHandler(httpwriter http.ResponseWriter, httprequest *http.Request) {
+	ctx, span := startWebhookSpan(httprequest, "grafana")
+	defer span.End()
+
+	limits := server.currentConfig().Limits
+	message := grafanaMessage{}
+	if !decodeWebhook(ctx, httpwriter, httprequest, &message, limits) {
+		return
+	}
+	hook := message.toHookMessage()
+	if !acceptHookMessage(ctx, httpwriter, &hook, limits) {
+		return
+	}
+	server.respondToWebhook(ctx, httpwriter, httprequest, &hook)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGrafanaToHookMessage(t *testing.T) {
+	jsonFile, err := os.Open("test/grafana.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer jsonFile.Close()
+
+	message := grafanaMessage{}
+	if err := json.NewDecoder(jsonFile).Decode(&message); err != nil {
+		t.Fatalf("could not decode message: %v", err)
+	}
+	hook := message.toHookMessage()
+
+	if hook.Status != "firing" || hook.Receiver != "openfero" || hook.GroupLabels["alertname"] != "KubeQuotaAlmostFull" {
+		t.Errorf("unexpected group context: %+v", hook)
+	}
+	wantCommon := map[string]string{
+		"summary":                "Quota of namespace team-a is almost full",
+		grafanaOrgIDAnnotation:   "1",
+		grafanaStateAnnotation:   "alerting",
+		grafanaTitleAnnotation:   "[FIRING:1] KubeQuotaAlmostFull Kubernetes (team-a)",
+		grafanaMessageAnnotation: "**Firing**\n\nValue: B=92.5, C=1",
+	}
+	for key, want := range wantCommon {
+		if got := hook.CommonAnnotations[key]; got != want {
+			t.Errorf("common annotation %s = %q, want %q", key, got, want)
+		}
+	}
+
+	if len(hook.Alerts) != 1 {
+		t.Fatalf("mapped %d alerts, want 1", len(hook.Alerts))
+	}
+	alert := hook.Alerts[0]
+	if alert.Labels["alertname"] != "KubeQuotaAlmostFull" || alert.Fingerprint != "57c6d9296de2ad39" {
+		t.Errorf("alert fields not mapped: %+v", alert)
+	}
+	wantAlert := map[string]string{
+		"summary":                     "Quota of namespace team-a is almost full",
+		grafanaDashboardURLAnnotation: "https://grafana.example.com/d/quota",
+		grafanaPanelURLAnnotation:     "https://grafana.example.com/d/quota?viewPanel=2",
+		grafanaSilenceURLAnnotation:   "https://grafana.example.com/alerting/silence/new?matcher=alertname%3DKubeQuotaAlmostFull",
+		grafanaValuesAnnotation:       `{"B":92.5,"C":1}`,
+	}
+	for key, want := range wantAlert {
+		if got := alert.Annotations[key]; got != want {
+			t.Errorf("alert annotation %s = %q, want %q", key, got, want)
+		}
+	}
+	if _, ok := alert.Annotations[grafanaImageURLAnnotation]; ok {
+		t.Errorf("empty image URL must not be mapped")
+	}
+}
+
+func TestGrafanaToHookMessageFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		message    grafanaMessage
+		wantStatus string
+		wantTitle  string
+		wantURL    string
+	}{
+		{
+			name:       "status derived from alerting state",
+			message:    grafanaMessage{State: "alerting"},
+			wantStatus: "firing",
+		},
+		{
+			name:       "status derived from ok state",
+			message:    grafanaMessage{State: "ok"},
+			wantStatus: "resolved",
+		},
+		{
+			name:       "status is kept",
+			message:    grafanaMessage{hookMessage: hookMessage{Status: "resolved"}, State: "alerting"},
+			wantStatus: "resolved",
+		},
+		{
+			name: "annotations of the alert rule are kept",
+			message: grafanaMessage{
+				hookMessage: hookMessage{Status: "firing", CommonAnnotations: map[string]string{grafanaTitleAnnotation: "rule"}},
+				Title:       "grafana",
+				Alerts: []grafanaAlert{{
+					alert:        alert{Annotations: map[string]string{grafanaDashboardURLAnnotation: "https://rule"}},
+					DashboardURL: "https://grafana",
+				}},
+			},
+			wantStatus: "firing",
+			wantTitle:  "rule",
+			wantURL:    "https://rule",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := tt.message.toHookMessage()
+			if hook.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", hook.Status, tt.wantStatus)
+			}
+			if got := hook.CommonAnnotations[grafanaTitleAnnotation]; got != tt.wantTitle {
+				t.Errorf("title = %q, want %q", got, tt.wantTitle)
+			}
+			if len(hook.Alerts) > 0 {
+				if got := hook.Alerts[0].Annotations[grafanaDashboardURLAnnotation]; got != tt.wantURL {
+					t.Errorf("dashboard URL = %q, want %q", got, tt.wantURL)
+				}
+			}
+		})
+	}
+}
+
+func TestGrafanaAlertsPostHandlerInvalidBody(t *testing.T) {
+	req := httptest.NewRequest("POST", "/alerts/grafana", strings.NewReader("{"))
+	responserecorder := httptest.NewRecorder()
+
+	(&clientsetStruct{}).grafanaAlertsPostHandler(responserecorder, req)
+
+	if status := responserecorder.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/OpenFero/openfero/pkg/config"
+)
+
+// errNoAlerts is returned if a payload did not result in a single alert with an alertname
+var errNoAlerts = errors.New("payload contains no alert with an alertname")
+
+// mapHookPayload maps the JSON payload of a generic source to an Alertmanager message
+func mapHookPayload(name string, source config.HookSource, payload interface{}) (hookMessage, error) {
+	alerts, err := mapHookAlerts(source, payload)
+	if err != nil {
+		return hookMessage{}, err
+	}
+	return newHookMessage("hooks/"+name, name, map[string]string{"source": name}, alerts), nil
+}
+
+// newHookMessage groups mapped alerts into a message, which is firing as long as one of its alerts is firing.
+// Unlike in notifications of Alertmanager, every alert keeps its own status, which selects its definition.
+func newHookMessage(groupKey string, receiver string, groupLabels map[string]string, alerts []alert) hookMessage {
+	message := hookMessage{
+		Version:     "4",
+		GroupKey:    groupKey,
+		Receiver:    receiver,
+		GroupLabels: groupLabels,
+		Status:      "resolved",
+		Alerts:      alerts,
+
+		alertStatuses: true,
+	}
+	for _, alert := range alerts {
+		if alert.Status == "firing" {
+			message.Status = "firing"
+		}
+	}
+	return message
+}
+
+// mapHookAlerts maps a payload to alerts by the mappings of a source.
+// Alerts without alertname are dropped, because no definition can be selected for them. A status which is
+// neither firing nor resolved after statusValues is an error, it would select no or an unintended definition.
+func mapHookAlerts(source config.HookSource, payload interface{}) ([]alert, error) {
+	items, err := hookItems(source.Alerts, payload)
+	if err != nil {
+		return nil, err
+	}
+	var alerts []alert
+	for _, item := range items {
+		alert, err := mapHookAlert(source, item)
+		if err != nil {
+			return nil, err
+		}
+		if alert.Labels["alertname"] == "" {
+			continue
+		}
+		if !checkAlertStatus(alert.Status) {
+			return nil, fmt.Errorf("status %q of alert %s is neither firing nor resolved, translate it with statusValues", alert.Status, alert.Labels["alertname"])
+		}
+		alerts = append(alerts, alert)
+	}
+	if len(alerts) == 0 {
+		return nil, errNoAlerts
+	}
+	return alerts, nil
+}
+
+// decodeHookPayload decodes a JSON payload for the evaluation of mappings.
+// Numbers are kept as written, so large IDs are not formatted as floats.
+func decodeHookPayload(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var payload interface{}
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// hookItems returns the items selected by the alerts mapping, or the payload itself if there is no such mapping
+func hookItems(template string, payload interface{}) ([]interface{}, error) {
+	if template == "" {
+		return []interface{}{payload}, nil
+	}
+	parser, err := config.ParseTemplate("alerts", template)
+	if err != nil {
+		return nil, err
+	}
+	results, err := parser.FindResults(payload)
+	if err != nil {
+		return nil, fmt.Errorf("error selecting alerts: %w", err)
+	}
+	var items []interface{}
+	for _, result := range results {
+		for _, value := range result {
+			// A path to the list itself selects all of its items
+			if list, ok := value.Interface().([]interface{}); ok {
+				items = append(items, list...)
+			} else {
+				items = append(items, value.Interface())
+			}
+		}
+	}
+	return items, nil
+}
+
+// mapHookAlert evaluates the mappings of a source against a single item
+func mapHookAlert(source config.HookSource, item interface{}) (alert, error) {
+	alertname, err := executeTemplate("alertname", source.Alertname, item)
+	if err != nil {
+		return alert{}, err
+	}
+	status, err := executeTemplate("status", source.Status, item)
+	if err != nil {
+		return alert{}, err
+	}
+	if mapped, ok := source.StatusValues[status]; ok {
+		status = mapped
+	}
+	if status == "" {
+		status = "firing"
+	}
+
+	labels, err := executeTemplates(source.Labels, item)
+	if err != nil {
+		return alert{}, err
+	}
+	labels["alertname"] = alertname
+	annotations, err := executeTemplates(source.Annotations, item)
+	if err != nil {
+		return alert{}, err
+	}
+	return alert{Status: status, Labels: labels, Annotations: annotations}, nil
+}
+
+func executeTemplates(templates map[string]string, item interface{}) (map[string]string, error) {
+	values := make(map[string]string, len(templates))
+	for key, template := range templates {
+		value, err := executeTemplate(key, template, item)
+		if err != nil {
+			return nil, err
+		}
+		if value != "" {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+func executeTemplate(name string, template string, item interface{}) (string, error) {
+	if template == "" {
+		return "", nil
+	}
+	parser, err := config.ParseTemplate(name, template)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := parser.Execute(&buf, item); err != nil {
+		return "", fmt.Errorf("error evaluating %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// @Summary Process events of a generic source
+// @Description Map a JSON payload to alerts by the mappings configured for the source and process them like alerts from Alertmanager
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param source path string true "Name of the source"
+// @Param message body object true "Event payload"
+// @Param sync query bool false "Create the jobs before answering, overrides webhook.synchronous"
+// @Success 200 {object} webhookResponse "Jobs created, synchronous mode"
+// @Success 202 {object} webhookResponse "Jobs queued"
+// @Failure 400 {object} webhookError "Invalid body, status or no valid alert"
+// @Failure 404 {object} webhookError "Unknown source"
+// @Failure 413 {object} webhookError "Body or number of alerts exceeds the limits"
+// @Router /hooks/{source} [post]
+// Handling the Post-Requests of generic sources
+func (server *clientsetStruct) hooksPostHandler(httpwriter http.ResponseWriter, httprequest *http.Request) {
+	name := httprequest.PathValue("source")
+	ctx, span := startWebhookSpan(httprequest, "hooks/"+sanitizeInput(name))
+	defer span.End()
+
+	cfg := server.currentConfig()
+	source, ok := cfg.Hooks.Sources[name]
+	if !ok {
+		writeWebhookError(ctx, httpwriter, http.StatusNotFound, "unknown source")
+		return
+	}
+
+	body := json.RawMessage{}
+	if !decodeWebhook(ctx, httpwriter, httprequest, &body, cfg.Limits) {
+		return
+	}
+	payload, err := decodeHookPayload(body)
+	if err != nil {
+		writeWebhookError(ctx, httpwriter, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	message, err := mapHookPayload(name, source, payload)
+	if err != nil {
+		writeWebhookError(ctx, httpwriter, http.StatusBadRequest, "payload could not be mapped to alerts: "+err.Error())
+		return
+	}
+	if !acceptHookMessage(ctx, httpwriter, &message, cfg.Limits) {
+		return
+	}
+	server.respondToWebhook(ctx, httpwriter, httprequest, &message)
+}
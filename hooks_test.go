@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func decodePayload(t *testing.T, body string) interface{} {
+	t.Helper()
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var payload interface{}
+	if err := decoder.Decode(&payload); err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
+
+func TestMapHookPayload(t *testing.T) {
+	tests := []struct {
+		name       string
+		source     config.HookSource
+		payload    string
+		wantStatus string
+		wantAlerts []alert
+		wantErr    bool
+	}{
+		{
+			name: "Single event",
+			source: config.HookSource{
+				Alertname:    "PipelineFailed",
+				Status:       "{.build.result}",
+				StatusValues: map[string]string{"failure": "firing", "success": "resolved"},
+				Labels:       map[string]string{"repository": "{.repository.name}", "build": "{.build.id}"},
+				Annotations:  map[string]string{"url": "{.build.url}", "commit": "{.build.missing}"},
+			},
+			payload:    `{"repository":{"name":"openfero"},"build":{"id":12345678901,"result":"failure","url":"https://ci.example.com/12345678901"}}`,
+			wantStatus: "firing",
+			wantAlerts: []alert{{
+				Status:      "firing",
+				Labels:      map[string]string{"alertname": "PipelineFailed", "repository": "openfero", "build": "12345678901"},
+				Annotations: map[string]string{"url": "https://ci.example.com/12345678901"},
+			}},
+		},
+		{
+			name: "List of events",
+			source: config.HookSource{
+				Alerts:    "{.records}",
+				Alertname: "{.event}",
+				Status:    "{.state}",
+				Labels:    map[string]string{"instance": "{.instance}"},
+			},
+			payload:    `{"records":[{"event":"InstanceStopped","instance":"i-1","state":"resolved"},{"event":"","instance":"i-2"},{"event":"InstanceStopped","instance":"i-3","state":"resolved"}]}`,
+			wantStatus: "resolved",
+			wantAlerts: []alert{
+				{Status: "resolved", Labels: map[string]string{"alertname": "InstanceStopped", "instance": "i-1"}, Annotations: map[string]string{}},
+				{Status: "resolved", Labels: map[string]string{"alertname": "InstanceStopped", "instance": "i-3"}, Annotations: map[string]string{}},
+			},
+		},
+		{
+			name:    "No alertname",
+			source:  config.HookSource{Alertname: "{.name}"},
+			payload: `{"other":"value"}`,
+			wantErr: true,
+		},
+		{
+			name:    "Untranslated status",
+			source:  config.HookSource{Alertname: "PipelineFailed", Status: "{.result}"},
+			payload: `{"result":"failure"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := mapHookPayload("ci", tt.source, decodePayload(t, tt.payload))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mapHookPayload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if message.Status != tt.wantStatus || message.Receiver != "ci" || message.GroupKey != "hooks/ci" {
+				t.Errorf("unexpected group context: %+v", message)
+			}
+			if !reflect.DeepEqual(message.Alerts, tt.wantAlerts) {
+				t.Errorf("alerts = %+v, want %+v", message.Alerts, tt.wantAlerts)
+			}
+		})
+	}
+}
+
+func TestHooksPostHandlerErrors(t *testing.T) {
+	cfg := config.Default()
+	cfg.Hooks.Sources = map[string]config.HookSource{"ci": {Alertname: "{.name}", Status: "{.result}"}}
+	server := &clientsetStruct{}
+	server.config.Store(cfg)
+
+	tests := []struct {
+		name     string
+		source   string
+		body     string
+		wantCode int
+	}{
+		{name: "Unknown source", source: "unknown", body: `{"name":"Build"}`, wantCode: http.StatusNotFound},
+		{name: "Invalid body", source: "ci", body: `{`, wantCode: http.StatusBadRequest},
+		{name: "No alert", source: "ci", body: `{"other":"Build"}`, wantCode: http.StatusBadRequest},
+		{name: "Untranslated status", source: "ci", body: `{"name":"Build","result":"failure"}`, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("POST /hooks/{source}", server.hooksPostHandler)
+			req := httptest.NewRequest("POST", "/hooks/"+tt.source, bytes.NewBufferString(tt.body))
+			responserecorder := httptest.NewRecorder()
+
+			mux.ServeHTTP(responserecorder, req)
+
+			if status := responserecorder.Code; status != tt.wantCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAlertStatus(t *testing.T) {
+	resolved := alert{Status: "resolved", Labels: map[string]string{"alertname": "BuildFailed"}}
+	tests := []struct {
+		name    string
+		message hookMessage
+		alert   alert
+		want    string
+	}{
+		{name: "Alertmanager notification", message: hookMessage{Status: "firing", Alerts: []alert{resolved}}, alert: resolved, want: "firing"},
+		{name: "Generic source", message: newHookMessage("hooks/ci", "ci", nil, []alert{resolved}), alert: resolved, want: "resolved"},
+		{name: "Alert without status", message: newHookMessage("hooks/ci", "ci", nil, []alert{{}}), want: "firing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := alertStatus(&tt.message, tt.alert, "firing"); got != tt.want {
+				t.Errorf("alertStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHooksPostHandlerMixedStatus(t *testing.T) {
+	jobDefinition := `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: build
+spec:
+  template:
+    spec:
+      containers:
+        - name: main
+          image: busybox
+      restartPolicy: Never
+`
+	alertStore = make([]alertStoreEntry, 0, 10)
+	configMapStore := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	for _, name := range []string{"openfero-buildfailed-firing", "openfero-buildfailed-resolved"} {
+		if err := configMapStore.Add(&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Data:       map[string]string{"BuildFailed": jobDefinition},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := config.Default()
+	cfg.Hooks.Sources = map[string]config.HookSource{"ci": {
+		Alerts:       "{.builds}",
+		Alertname:    "BuildFailed",
+		Status:       "{.result}",
+		StatusValues: map[string]string{"failure": "firing", "success": "resolved"},
+		Labels:       map[string]string{"pipeline": "{.pipeline}"},
+	}}
+	server := &clientsetStruct{
+		clientset:               fake.NewClientset(),
+		configmapNamespace:      "default",
+		jobDestinationNamespace: "default",
+		configMapStore:          configMapStore,
+		jobStore:                cache.NewStore(cache.MetaNamespaceKeyFunc),
+	}
+	server.config.Store(cfg)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /hooks/{source}", server.hooksPostHandler)
+	body := `{"builds":[{"pipeline":"a","result":"failure"},{"pipeline":"b","result":"success"}]}`
+	req := httptest.NewRequest("POST", "/hooks/ci?sync=true", bytes.NewBufferString(body))
+	responserecorder := httptest.NewRecorder()
+
+	mux.ServeHTTP(responserecorder, req)
+
+	response := webhookResponse{}
+	if err := json.NewDecoder(responserecorder.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	wantDefinitions := []string{"openfero-buildfailed-firing", "openfero-buildfailed-resolved"}
+	if len(response.Alerts) != len(wantDefinitions) {
+		t.Fatalf("response = %+v, want %d results", response, len(wantDefinitions))
+	}
+	for i, result := range response.Alerts {
+		if result.Result != resultCreated || result.Definition != wantDefinitions[i] {
+			t.Errorf("alerts[%d] = %+v, want a job of %s", i, result, wantDefinitions[i])
+		}
+	}
+	statuses := map[string]string{}
+	for _, entry := range alertStore {
+		statuses[entry.Alert.Labels["pipeline"]] = entry.Status
+	}
+	if statuses["a"] != "firing" || statuses["b"] != "resolved" {
+		t.Errorf("alert store statuses = %v, want a firing and b resolved", statuses)
+	}
+}
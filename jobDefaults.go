@@ -0,0 +1,84 @@
+package main
+
+import (
+	"github.com/OpenFero/openfero/pkg/config"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// trackingLabels are set on every job, the job informer only watches jobs with these labels
+var trackingLabels = map[string]string{
+	"app": "openfero",
+}
+
+// applyJobDefaults sets all defaults which the job definition does not set itself
+// and guarantees the tracking labels, even if the definition sets other labels.
+func applyJobDefaults(jobObject *batchv1.Job, defaults config.Defaults) {
+	spec := &jobObject.Spec
+	if spec.TTLSecondsAfterFinished == nil && defaults.TTLSecondsAfterFinished != nil {
+		ttl := *defaults.TTLSecondsAfterFinished
+		spec.TTLSecondsAfterFinished = &ttl
+	}
+	if spec.ActiveDeadlineSeconds == nil && defaults.ActiveDeadlineSeconds != nil {
+		deadline := *defaults.ActiveDeadlineSeconds
+		spec.ActiveDeadlineSeconds = &deadline
+	}
+	if spec.BackoffLimit == nil && defaults.BackoffLimit != nil {
+		backoffLimit := *defaults.BackoffLimit
+		spec.BackoffLimit = &backoffLimit
+	}
+
+	jobObject.Labels = addMissing(jobObject.Labels, defaults.Labels)
+	for key, value := range trackingLabels {
+		jobObject.Labels[key] = value
+	}
+	jobObject.Annotations = addMissing(jobObject.Annotations, defaults.Annotations)
+
+	podSpec := &spec.Template.Spec
+	if podSpec.ServiceAccountName == "" && podSpec.DeprecatedServiceAccount == "" {
+		podSpec.ServiceAccountName = defaults.ServiceAccountName
+	}
+	if defaults.Resources != nil {
+		for i := range podSpec.InitContainers {
+			addDefaultResources(&podSpec.InitContainers[i].Resources, *defaults.Resources)
+		}
+		for i := range podSpec.Containers {
+			addDefaultResources(&podSpec.Containers[i].Resources, *defaults.Resources)
+		}
+	}
+}
+
+// addMissing adds all entries of defaults whose key is not set in values
+func addMissing(values map[string]string, defaults map[string]string) map[string]string {
+	if values == nil {
+		values = make(map[string]string, len(defaults))
+	}
+	for key, value := range defaults {
+		if _, ok := values[key]; !ok {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// addDefaultResources adds the requests and limits of resources the container does not specify itself
+func addDefaultResources(resources *v1.ResourceRequirements, defaults v1.ResourceRequirements) {
+	resources.Requests = addMissingResources(resources.Requests, defaults.Requests)
+	resources.Limits = addMissingResources(resources.Limits, defaults.Limits)
+}
+
+func addMissingResources(values v1.ResourceList, defaults v1.ResourceList) v1.ResourceList {
+	if len(defaults) == 0 {
+		return values
+	}
+	if values == nil {
+		values = make(v1.ResourceList, len(defaults))
+	}
+	for name, quantity := range defaults {
+		if _, ok := values[name]; !ok {
+			values[name] = quantity.DeepCopy()
+		}
+	}
+	return values
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/OpenFero/openfero/pkg/config"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestApplyJobDefaults(t *testing.T) {
+	defaults := config.Defaults{
+		TTLSecondsAfterFinished: ptr.To[int32](300),
+		ActiveDeadlineSeconds:   ptr.To[int64](600),
+		BackoffLimit:            ptr.To[int32](1),
+		Labels:                  map[string]string{"team": "sre", "app": "other"},
+		Annotations:             map[string]string{"owner": "sre"},
+		Resources: &v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m"), v1.ResourceMemory: resource.MustParse("64Mi")},
+		},
+		ServiceAccountName: "remediation",
+	}
+
+	tests := []struct {
+		name  string
+		job   *batchv1.Job
+		check func(t *testing.T, job *batchv1.Job)
+	}{
+		{
+			name: "Empty job receives all defaults",
+			job: &batchv1.Job{Spec: batchv1.JobSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: "main"}},
+			}}}},
+			check: func(t *testing.T, job *batchv1.Job) {
+				if *job.Spec.TTLSecondsAfterFinished != 300 || *job.Spec.ActiveDeadlineSeconds != 600 || *job.Spec.BackoffLimit != 1 {
+					t.Errorf("job spec defaults not applied: %+v", job.Spec)
+				}
+				if job.Labels["app"] != "openfero" || job.Labels["team"] != "sre" {
+					t.Errorf("labels = %v", job.Labels)
+				}
+				if job.Annotations["owner"] != "sre" {
+					t.Errorf("annotations = %v", job.Annotations)
+				}
+				if job.Spec.Template.Spec.ServiceAccountName != "remediation" {
+					t.Errorf("serviceAccountName = %q", job.Spec.Template.Spec.ServiceAccountName)
+				}
+				if cpu := job.Spec.Template.Spec.Containers[0].Resources.Requests[v1.ResourceCPU]; cpu.String() != "100m" {
+					t.Errorf("cpu request = %s", cpu.String())
+				}
+			},
+		},
+		{
+			name: "Values of the definition take precedence",
+			job: &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{"team": "platform"},
+					Annotations: map[string]string{"owner": "platform"},
+				},
+				Spec: batchv1.JobSpec{
+					TTLSecondsAfterFinished: ptr.To[int32](0),
+					BackoffLimit:            ptr.To[int32](5),
+					Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+						ServiceAccountName: "custom",
+						Containers: []v1.Container{{
+							Name:      "main",
+							Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}},
+						}},
+					}},
+				},
+			},
+			check: func(t *testing.T, job *batchv1.Job) {
+				if *job.Spec.TTLSecondsAfterFinished != 0 || *job.Spec.BackoffLimit != 5 {
+					t.Errorf("job spec values were overwritten: %+v", job.Spec)
+				}
+				if job.Labels["team"] != "platform" || job.Annotations["owner"] != "platform" {
+					t.Errorf("labels = %v, annotations = %v", job.Labels, job.Annotations)
+				}
+				if job.Spec.Template.Spec.ServiceAccountName != "custom" {
+					t.Errorf("serviceAccountName = %q", job.Spec.Template.Spec.ServiceAccountName)
+				}
+				requests := job.Spec.Template.Spec.Containers[0].Resources.Requests
+				if cpu := requests[v1.ResourceCPU]; cpu.String() != "1" {
+					t.Errorf("cpu request = %s", cpu.String())
+				}
+				if memory := requests[v1.ResourceMemory]; memory.String() != "64Mi" {
+					t.Errorf("memory request = %s", memory.String())
+				}
+			},
+		},
+		{
+			name: "Tracking label is guaranteed for jobs with custom labels",
+			job: &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"app": "custom", "component": "cleanup"},
+			}},
+			check: func(t *testing.T, job *batchv1.Job) {
+				if job.Labels["app"] != "openfero" || job.Labels["component"] != "cleanup" {
+					t.Errorf("labels = %v", job.Labels)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applyJobDefaults(tt.job, defaults)
+			tt.check(t, tt.job)
+		})
+	}
+}
+
+func TestParseDefinitionSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    config.Defaults
+		wantErr bool
+	}{
+		{
+			name: "No settings",
+			data: map[string]string{"TestAlert": "job"},
+		},
+		{
+			name: "Job defaults",
+			data: map[string]string{definitionSettingsKey: "jobDefaults:\n  backoffLimit: 3\n  serviceAccountName: cleanup\n"},
+			want: config.Defaults{BackoffLimit: ptr.To[int32](3), ServiceAccountName: "cleanup"},
+		},
+		{
+			name:    "Unknown field",
+			data:    map[string]string{definitionSettingsKey: "jobDefault:\n  backoffLimit: 3\n"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid notification outcome",
+			data:    map[string]string{definitionSettingsKey: "notifications:\n  - receivers: [sre]\n    outcomes: [started]\n"},
+			wantErr: true,
+		},
+		{
+			name:    "Negative approval timeout",
+			data:    map[string]string{definitionSettingsKey: "requiresApproval: true\napprovalTimeout: -1m\n"},
+			wantErr: true,
+		},
+		{
+			name:    "Workflow step without job definition",
+			data:    map[string]string{definitionSettingsKey: "workflow:\n  steps:\n    - name: diagnose\n      job: diagnose\n"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid priority",
+			data:    map[string]string{definitionSettingsKey: "priority: urgent\n"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid schedule",
+			data:    map[string]string{definitionSettingsKey: "schedule:\n  cron: '0 3 * *'\n"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid value",
+			data:    map[string]string{definitionSettingsKey: "jobDefaults:\n  activeDeadlineSeconds: 0\n"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, err := parseDefinitionSettings(&v1.ConfigMap{Data: tt.data})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDefinitionSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := settings.JobDefaults
+			if ptr.Deref(got.BackoffLimit, -1) != ptr.Deref(tt.want.BackoffLimit, -1) || got.ServiceAccountName != tt.want.ServiceAccountName {
+				t.Errorf("parseDefinitionSettings() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
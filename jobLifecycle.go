@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"go.uber.org/zap"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// jobRun is a job created by OpenFero together with the alerts and settings it was created for.
+// Runs of jobs created before a restart of OpenFero only hold the job.
+type jobRun struct {
+	job      *batchv1.Job
+	data     alertContext
+	settings *definitionSettings
+	// outputs are the outputs reported by a finished job
+	outputs map[string]string
+}
+
+// jobListener is notified about the lifecycle of the jobs created by OpenFero
+type jobListener interface {
+	// jobCreated is called after the job and its alert file were created
+	jobCreated(ctx context.Context, run *jobRun)
+	// jobFinished is called once when the job succeeded or failed, reason is the reason of a failure
+	jobFinished(run *jobRun, outcome jobOutcome, reason string)
+}
+
+// jobDeletionListener is implemented by listeners keeping state of unfinished jobs, which is dropped when a job
+// is deleted before it finished
+type jobDeletionListener interface {
+	jobDeleted(uid types.UID)
+}
+
+// jobLifecycle keeps the runs of unfinished jobs and notifies the listeners about created and finished jobs
+type jobLifecycle struct {
+	mu        sync.Mutex
+	runs      map[types.UID]*jobRun
+	listeners []jobListener
+	// readOutputs reads the outputs of finished jobs, without it runs hold no outputs
+	readOutputs func(ctx context.Context, job *batchv1.Job) (map[string]string, error)
+}
+
+func newJobLifecycle(listeners ...jobListener) *jobLifecycle {
+	return &jobLifecycle{
+		runs:      make(map[types.UID]*jobRun),
+		listeners: listeners,
+	}
+}
+
+// created records the run of a created job
+func (lifecycle *jobLifecycle) created(ctx context.Context, run *jobRun) {
+	if lifecycle == nil {
+		return
+	}
+	lifecycle.mu.Lock()
+	lifecycle.runs[run.job.UID] = run
+	lifecycle.mu.Unlock()
+
+	for _, listener := range lifecycle.listeners {
+		listener.jobCreated(ctx, run)
+	}
+}
+
+// finished removes the run of a finished job, the run holds the job in its final state and its outputs.
+// Reading the outputs calls the Kubernetes API, so the listeners are then notified in the background
+// to not block the job informer.
+func (lifecycle *jobLifecycle) finished(job *batchv1.Job, outcome jobOutcome, reason string) {
+	if lifecycle == nil {
+		return
+	}
+	lifecycle.mu.Lock()
+	run, ok := lifecycle.runs[job.UID]
+	delete(lifecycle.runs, job.UID)
+	lifecycle.mu.Unlock()
+
+	if !ok {
+		run = &jobRun{}
+	}
+	finished := *run
+	finished.job = job
+	if lifecycle.readOutputs == nil {
+		lifecycle.notifyFinished(&finished, outcome, reason)
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), jobOutputTimeout)
+		outputs, err := lifecycle.readOutputs(ctx, job)
+		cancel()
+		if err != nil {
+			log.Subsystem(log.SubsystemJobs).Warn("error reading job outputs", zap.String(log.JobKey, job.Name), zap.String("error", err.Error()))
+		}
+		finished.outputs = outputs
+		lifecycle.notifyFinished(&finished, outcome, reason)
+	}()
+}
+
+func (lifecycle *jobLifecycle) notifyFinished(run *jobRun, outcome jobOutcome, reason string) {
+	for _, listener := range lifecycle.listeners {
+		listener.jobFinished(run, outcome, reason)
+	}
+}
+
+// forget removes the run of a deleted job and notifies the listeners keeping state of unfinished jobs
+func (lifecycle *jobLifecycle) forget(uid types.UID) {
+	if lifecycle == nil {
+		return
+	}
+	lifecycle.mu.Lock()
+	delete(lifecycle.runs, uid)
+	lifecycle.mu.Unlock()
+
+	for _, listener := range lifecycle.listeners {
+		if deletion, ok := listener.(jobDeletionListener); ok {
+			deletion.jobDeleted(uid)
+		}
+	}
+}
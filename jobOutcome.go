@@ -0,0 +1,146 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"go.uber.org/zap"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// jobOutcome is the final state of a job derived from its conditions
+type jobOutcome string
+
+const (
+	jobOutcomeUnknown   jobOutcome = ""
+	jobOutcomeSucceeded jobOutcome = "succeeded"
+	jobOutcomeFailed    jobOutcome = "failed"
+)
+
+// jobOutcomeTracker records the outcome of every job exactly once.
+// Informers replay all existing jobs on startup and on relists, so outcomes
+// are deduplicated by the job UID instead of relying on status transitions.
+type jobOutcomeTracker struct {
+	mu        sync.Mutex
+	recorded  map[types.UID]struct{}
+	lifecycle *jobLifecycle
+	// startedAt separates jobs which finished before OpenFero started from those finished while the informer syncs
+	startedAt time.Time
+}
+
+func newJobOutcomeTracker(lifecycle *jobLifecycle) *jobOutcomeTracker {
+	return &jobOutcomeTracker{
+		recorded:  make(map[types.UID]struct{}),
+		lifecycle: lifecycle,
+		startedAt: time.Now(),
+	}
+}
+
+// eventHandler returns the informer event handler feeding the tracker
+func (tracker *jobOutcomeTracker) eventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			job, ok := obj.(*batchv1.Job)
+			if !ok {
+				return
+			}
+			log.Subsystem(log.SubsystemInformer).Debug("Job added", zap.String("job", job.Name), zap.Bool("initialList", isInInitialList))
+			tracker.observe(job, isInInitialList)
+		},
+		UpdateFunc: func(_, new interface{}) {
+			job, ok := new.(*batchv1.Job)
+			if !ok {
+				return
+			}
+			tracker.observe(job, false)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			job, ok := obj.(*batchv1.Job)
+			if !ok {
+				return
+			}
+			log.Subsystem(log.SubsystemInformer).Debug("Job deleted", zap.String("job", job.Name))
+			tracker.forget(job.UID)
+		},
+	}
+}
+
+// observe records the outcome of a finished job if it was not recorded before.
+// Jobs of the initial list which already finished before OpenFero started are only remembered, not counted,
+// and their listeners are not notified. Jobs of the initial list which finished later are recorded.
+func (tracker *jobOutcomeTracker) observe(job *batchv1.Job, initialList bool) bool {
+	outcome, reason, finishedAt := getJobOutcome(job)
+	if outcome == jobOutcomeUnknown {
+		return false
+	}
+
+	tracker.mu.Lock()
+	_, seen := tracker.recorded[job.UID]
+	tracker.recorded[job.UID] = struct{}{}
+	tracker.mu.Unlock()
+
+	if seen || (initialList && finishedAt.Before(tracker.startedAt)) {
+		return false
+	}
+
+	definition := job.Annotations[definitionAnnotation]
+	alertname := job.Annotations[alertnameAnnotation]
+	switch outcome {
+	case jobOutcomeSucceeded:
+		log.Subsystem(log.SubsystemInformer).Debug("Job completed successfully", zap.String("job", job.Name), zap.String("definition", definition))
+		metadata.JobsSucceededTotal.WithLabelValues(definition, alertname).Inc()
+	case jobOutcomeFailed:
+		log.Subsystem(log.SubsystemInformer).Debug("Job failed", zap.String("job", job.Name), zap.String("definition", definition), zap.String("reason", reason))
+		metadata.JobsFailedTotal.WithLabelValues(definition, alertname, reason).Inc()
+	}
+
+	if job.Status.StartTime != nil && !finishedAt.IsZero() {
+		duration := finishedAt.Sub(job.Status.StartTime.Time).Seconds()
+		metadata.JobDurationSeconds.WithLabelValues(definition, string(outcome)).Observe(duration)
+	}
+
+	tracker.lifecycle.finished(job, outcome, reason)
+	return true
+}
+
+// forget removes a deleted job from the tracker
+func (tracker *jobOutcomeTracker) forget(uid types.UID) {
+	tracker.mu.Lock()
+	delete(tracker.recorded, uid)
+	tracker.mu.Unlock()
+	tracker.lifecycle.forget(uid)
+}
+
+// getJobOutcome evaluates the Complete and Failed conditions of a job.
+// It returns the outcome, the reason of a failure (e.g. DeadlineExceeded) and the time the job finished.
+func getJobOutcome(job *batchv1.Job) (jobOutcome, string, time.Time) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != v1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			finishedAt := condition.LastTransitionTime.Time
+			if job.Status.CompletionTime != nil {
+				finishedAt = job.Status.CompletionTime.Time
+			}
+			return jobOutcomeSucceeded, "", finishedAt
+		case batchv1.JobFailed:
+			reason := condition.Reason
+			if reason == "" {
+				reason = string(batchv1.JobFailed)
+			}
+			return jobOutcomeFailed, reason, condition.LastTransitionTime.Time
+		}
+	}
+	return jobOutcomeUnknown, "", time.Time{}
+}
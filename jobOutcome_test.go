@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func finishedJob(uid string, conditionType batchv1.JobConditionType, reason string) *batchv1.Job {
+	start := metav1.NewTime(time.Now().Add(-time.Minute))
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "job-" + uid,
+			UID:  types.UID(uid),
+			Annotations: map[string]string{
+				definitionAnnotation: "openfero-testalert-firing",
+				alertnameAnnotation:  "TestAlert",
+			},
+		},
+		Status: batchv1.JobStatus{
+			StartTime: &start,
+			Conditions: []batchv1.JobCondition{
+				{Type: conditionType, Status: v1.ConditionTrue, Reason: reason, LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+}
+
+func TestGetJobOutcome(t *testing.T) {
+	tests := []struct {
+		name           string
+		job            *batchv1.Job
+		expected       jobOutcome
+		expectedReason string
+	}{
+		{
+			name:     "Running job",
+			job:      &batchv1.Job{},
+			expected: jobOutcomeUnknown,
+		},
+		{
+			name:     "Completed job",
+			job:      finishedJob("a", batchv1.JobComplete, ""),
+			expected: jobOutcomeSucceeded,
+		},
+		{
+			name:           "Failed job",
+			job:            finishedJob("b", batchv1.JobFailed, batchv1.JobReasonBackoffLimitExceeded),
+			expected:       jobOutcomeFailed,
+			expectedReason: batchv1.JobReasonBackoffLimitExceeded,
+		},
+		{
+			name:           "Job exceeding its deadline",
+			job:            finishedJob("c", batchv1.JobFailed, batchv1.JobReasonDeadlineExceeded),
+			expected:       jobOutcomeFailed,
+			expectedReason: batchv1.JobReasonDeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome, reason, _ := getJobOutcome(tt.job)
+			if outcome != tt.expected || reason != tt.expectedReason {
+				t.Errorf("getJobOutcome() = %q, %q; want %q, %q", outcome, reason, tt.expected, tt.expectedReason)
+			}
+		})
+	}
+}
+
+// finishedBeforeStart moves the end of a job before the start of OpenFero
+func finishedBeforeStart(job *batchv1.Job) *batchv1.Job {
+	job.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	return job
+}
+
+func TestJobOutcomeTrackerDeduplicates(t *testing.T) {
+	tracker := newJobOutcomeTracker(nil)
+	succeeded := metadata.JobsSucceededTotal.WithLabelValues("openfero-testalert-firing", "TestAlert")
+	before := testutil.ToFloat64(succeeded)
+
+	// Jobs which finished before startup are not counted
+	if tracker.observe(finishedBeforeStart(finishedJob("initial", batchv1.JobComplete, "")), true) {
+		t.Error("observe() counted a job from the initial list")
+	}
+	if tracker.observe(finishedBeforeStart(finishedJob("initial", batchv1.JobComplete, "")), false) {
+		t.Error("observe() counted a job from the initial list after a resync")
+	}
+
+	// Jobs of the initial list which finished after startup are counted
+	if !tracker.observe(finishedJob("syncing", batchv1.JobComplete, ""), true) {
+		t.Error("observe() did not count a job which finished while the informer synced")
+	}
+
+	// New jobs are counted exactly once
+	job := finishedJob("new", batchv1.JobComplete, "")
+	if !tracker.observe(job, false) {
+		t.Error("observe() did not count a finished job")
+	}
+	if tracker.observe(job, false) {
+		t.Error("observe() counted a finished job twice")
+	}
+
+	if got := testutil.ToFloat64(succeeded) - before; got != 2 {
+		t.Errorf("succeeded counter increased by %v, want 2", got)
+	}
+
+	// Failed jobs are counted with their reason
+	failed := metadata.JobsFailedTotal.WithLabelValues("openfero-testalert-firing", "TestAlert", "BackoffLimitExceeded")
+	failedBefore := testutil.ToFloat64(failed)
+	if !tracker.observe(finishedJob("failed", batchv1.JobFailed, "BackoffLimitExceeded"), false) {
+		t.Error("observe() did not count a failed job")
+	}
+	if got := testutil.ToFloat64(failed) - failedBefore; got != 1 {
+		t.Errorf("failed counter increased by %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(succeeded) - before; got != 2 {
+		t.Errorf("succeeded counter increased by %v after a failed job, want 2", got)
+	}
+
+	// Deleted jobs are removed from the tracker
+	tracker.forget(job.UID)
+	if len(tracker.recorded) != 3 {
+		t.Errorf("tracker holds %d jobs after delete, want 3", len(tracker.recorded))
+	}
+}
+
+// recordingListener records the lifecycle calls of the job listener
+type recordingListener struct {
+	created  []string
+	finished []jobOutcome
+}
+
+func (listener *recordingListener) jobCreated(_ context.Context, run *jobRun) {
+	listener.created = append(listener.created, run.job.Name)
+}
+
+func (listener *recordingListener) jobFinished(_ *jobRun, outcome jobOutcome, _ string) {
+	listener.finished = append(listener.finished, outcome)
+}
+
+func TestJobOutcomeTrackerNotifiesListeners(t *testing.T) {
+	listener := &recordingListener{}
+	tracker := newJobOutcomeTracker(newJobLifecycle(listener))
+
+	tracker.observe(finishedBeforeStart(finishedJob("initial", batchv1.JobComplete, "")), true)
+	failed := finishedJob("failed", batchv1.JobFailed, "BackoffLimitExceeded")
+	tracker.observe(failed, false)
+	tracker.observe(failed, false)
+
+	if len(listener.finished) != 1 || listener.finished[0] != jobOutcomeFailed {
+		t.Errorf("listener was notified about %v, want a single failed job", listener.finished)
+	}
+}
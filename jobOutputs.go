@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+)
+
+// outputMessageKey is the output holding a termination message which is no JSON object
+const outputMessageKey = "message"
+
+// outputLinePrefix marks log lines reporting an output as key=value
+const outputLinePrefix = "OPENFERO_OUTPUT "
+
+// Limits of the container logs searched for output lines
+const (
+	outputLogLines = 100
+	outputLogBytes = 64 * 1024
+)
+
+// jobOutputTimeout limits reading the outputs of a finished job
+const jobOutputTimeout = 30 * time.Second
+
+// jobOutputs returns the outputs a job reported. Outputs are read from the termination message of its containers,
+// by default /dev/termination-log and limited to 4096 bytes by Kubernetes. Without termination message the
+// last log lines of the containers starting with OPENFERO_OUTPUT are used. Only the latest pod of the job is read.
+func (server *clientsetStruct) jobOutputs(ctx context.Context, job *batchv1.Job) (map[string]string, error) {
+	selector := labels.Set{batchv1.JobNameLabel: job.Name}.AsSelector().String()
+	pods, err := server.clientset.CoreV1().Pods(job.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	if len(pods.Items) == 0 {
+		return nil, nil
+	}
+	items := pods.Items
+	sort.Slice(items, func(i, j int) bool {
+		return items[j].CreationTimestamp.Before(&items[i].CreationTimestamp)
+	})
+	pod := items[0]
+	if message := terminationMessage(pod); message != "" {
+		return parseOutputs(message), nil
+	}
+	return logOutputs(ctx, server.clientset, pod)
+}
+
+// terminationMessage returns the first termination message of the containers of the pod
+func terminationMessage(pod v1.Pod) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		if terminated := status.State.Terminated; terminated != nil && strings.TrimSpace(terminated.Message) != "" {
+			return strings.TrimSpace(terminated.Message)
+		}
+	}
+	return ""
+}
+
+// parseOutputs reads a JSON object as outputs, values which are no strings are kept as JSON.
+// Any other message is returned as the single output message.
+func parseOutputs(message string) map[string]string {
+	values := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(message), &values); err != nil {
+		return map[string]string{outputMessageKey: message}
+	}
+	outputs := make(map[string]string, len(values))
+	for key, value := range values {
+		var text string
+		if err := json.Unmarshal(value, &text); err == nil {
+			outputs[key] = text
+		} else {
+			outputs[key] = string(value)
+		}
+	}
+	return outputs
+}
+
+// logOutputs searches the last log lines of the containers of the pod for outputs
+func logOutputs(ctx context.Context, clientset kubernetes.Interface, pod v1.Pod) (map[string]string, error) {
+	outputs := map[string]string{}
+	for _, container := range pod.Spec.Containers {
+		stream, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{
+			Container:  container.Name,
+			TailLines:  ptr.To[int64](outputLogLines),
+			LimitBytes: ptr.To[int64](outputLogBytes),
+		}).Stream(ctx)
+		if err != nil {
+			return nil, err
+		}
+		err = parseOutputLines(stream, outputs)
+		stream.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(outputs) == 0 {
+		return nil, nil
+	}
+	return outputs, nil
+}
+
+// parseOutputLines adds the outputs of all lines like "OPENFERO_OUTPUT freed=12GB" to outputs,
+// lines without key are added as output message
+func parseOutputLines(reader io.Reader, outputs map[string]string) error {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), outputLinePrefix)
+		if !ok {
+			continue
+		}
+		key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if !found || key == "" || strings.ContainsAny(key, " \t") {
+			outputs[outputMessageKey] = strings.TrimSpace(line)
+			continue
+		}
+		outputs[key] = value
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseOutputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    map[string]string
+	}{
+		{name: "JSON object", message: `{"pod": "db-0", "restarts": 3, "ok": true}`, want: map[string]string{"pod": "db-0", "restarts": "3", "ok": "true"}},
+		{name: "Nested value", message: `{"pods": ["db-0","db-1"]}`, want: map[string]string{"pods": `["db-0","db-1"]`}},
+		{name: "Text", message: "restarted db-0", want: map[string]string{outputMessageKey: "restarted db-0"}},
+		{name: "JSON array", message: `["db-0"]`, want: map[string]string{outputMessageKey: `["db-0"]`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseOutputs(tt.message); !maps.Equal(got, tt.want) {
+				t.Errorf("parseOutputs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOutputLines(t *testing.T) {
+	logs := `cleaning up
+OPENFERO_OUTPUT freed=12GB
+OPENFERO_OUTPUT query=a=b
+  OPENFERO_OUTPUT restarted 3 pods
+OPENFERO_OUTPUTS ignored=true
+`
+	outputs := map[string]string{}
+	if err := parseOutputLines(strings.NewReader(logs), outputs); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"freed": "12GB", "query": "a=b", outputMessageKey: "restarted 3 pods"}
+	if !maps.Equal(outputs, want) {
+		t.Errorf("parseOutputLines() = %v, want %v", outputs, want)
+	}
+}
+
+func TestJobOutputs(t *testing.T) {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "cleanup-abcde", Namespace: "default"}}
+	pod := func(name string, age time.Duration, message string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				Labels:            map[string]string{batchv1.JobNameLabel: job.Name},
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			},
+			Spec: v1.PodSpec{Containers: []v1.Container{{Name: "main"}}},
+			Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{
+				Name:  "main",
+				State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Message: message}},
+			}}},
+		}
+	}
+	tests := []struct {
+		name string
+		pods []*v1.Pod
+		want map[string]string
+	}{
+		{name: "No pods"},
+		{
+			name: "Latest pod",
+			pods: []*v1.Pod{pod("first", time.Hour, `{"freed": "1GB"}`), pod("retry", time.Minute, `{"freed": "12GB"}`)},
+			want: map[string]string{"freed": "12GB"},
+		},
+		{name: "No outputs in termination message and logs", pods: []*v1.Pod{pod("first", time.Minute, "")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewClientset()
+			for _, pod := range tt.pods {
+				if _, err := clientset.CoreV1().Pods("default").Create(context.Background(), pod, metav1.CreateOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			server := &clientsetStruct{clientset: clientset}
+			got, err := server.jobOutputs(context.Background(), job)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("jobOutputs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
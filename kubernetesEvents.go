@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"github.com/OpenFero/openfero/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// eventReceiver is the receiver of the alerts created from Kubernetes Events
+const eventReceiver = "kubernetes-events"
+
+// eventTracker turns new Kubernetes Events into alerts.
+// Kubernetes aggregates repeated occurrences into the existing event by increasing its count or series count,
+// so every event triggers only once, no matter how often it occurs.
+type eventTracker struct {
+	mu     sync.Mutex
+	counts map[types.UID]int32
+	server *clientsetStruct
+}
+
+func newEventTracker(server *clientsetStruct) *eventTracker {
+	return &eventTracker{
+		counts: make(map[types.UID]int32),
+		server: server,
+	}
+}
+
+// run starts the informer on the events of the namespace, all namespaces if empty, and waits for its cache to sync
+func (tracker *eventTracker) run(ctx context.Context, clientset kubernetes.Interface, namespace string) error {
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, time.Hour*1, informers.WithNamespace(namespace))
+	informer := factory.Core().V1().Events().Informer()
+	if _, err := informer.AddEventHandler(tracker.eventHandler()); err != nil {
+		return fmt.Errorf("failed to add Event event handler: %w", err)
+	}
+
+	go factory.Start(ctx.Done())
+
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return fmt.Errorf("failed to sync Event cache")
+	}
+	metadata.InformerSynced.WithLabelValues("event").Set(1)
+	return nil
+}
+
+// eventHandler returns the informer event handler feeding the tracker
+func (tracker *eventTracker) eventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			event, ok := obj.(*v1.Event)
+			if !ok {
+				return
+			}
+			tracker.observe(event, isInInitialList)
+		},
+		UpdateFunc: func(_, new interface{}) {
+			event, ok := new.(*v1.Event)
+			if !ok {
+				return
+			}
+			tracker.observe(event, false)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			event, ok := obj.(*v1.Event)
+			if !ok {
+				return
+			}
+			tracker.forget(event.UID)
+		},
+	}
+}
+
+// observe processes an event the first time it is seen. Repeated occurrences of the event are only logged.
+// Events which already existed before OpenFero started are only remembered, not processed.
+func (tracker *eventTracker) observe(event *v1.Event, initialList bool) bool {
+	count := eventCount(event)
+	tracker.mu.Lock()
+	previous, seen := tracker.counts[event.UID]
+	tracker.counts[event.UID] = count
+	tracker.mu.Unlock()
+
+	logger := log.Subsystem(log.SubsystemInformer).With(zap.String("event", event.Namespace+"/"+event.Name), zap.String("reason", event.Reason))
+	if seen {
+		if count > previous {
+			logger.Debug("Event repeated", zap.Int32("count", count))
+		}
+		return false
+	}
+	if initialList {
+		return false
+	}
+
+	events := tracker.server.currentConfig().Events
+	if !matchesEvent(event, events) {
+		logger.Debug("Event does not match the configured types and reasons")
+		return false
+	}
+	// Remediating the failures of remediation jobs would start ever more jobs
+	if tracker.server.ownsObject(context.Background(), event.InvolvedObject) {
+		logger.Debug("Event of a job of OpenFero is ignored")
+		return false
+	}
+
+	ctx := log.WithSubsystem(context.Background(), log.SubsystemInformer)
+	ctx = log.WithFields(ctx, zap.String("source", eventReceiver))
+	ctx, span := tracing.Tracer().Start(ctx, "kubernetes.event", trace.WithSpanKind(trace.SpanKindConsumer), trace.WithAttributes(
+		attribute.String("openfero.source", eventReceiver),
+		attribute.String("openfero.event.reason", event.Reason),
+	))
+	defer span.End()
+
+	message := eventMessage(event)
+	tracker.server.processHookMessage(ctx, &message, 0)
+	return true
+}
+
+// forget removes a deleted event from the tracker
+func (tracker *eventTracker) forget(uid types.UID) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	delete(tracker.counts, uid)
+}
+
+// eventCount returns how often the event occurred
+func eventCount(event *v1.Event) int32 {
+	count := max(event.Count, 1)
+	if event.Series != nil {
+		count = max(count, event.Series.Count)
+	}
+	return count
+}
+
+// ownsObject reports whether the object is a job of OpenFero, one of its pods or its alert file
+func (server *clientsetStruct) ownsObject(ctx context.Context, object v1.ObjectReference) bool {
+	if object.Namespace != server.jobDestinationNamespace || server.jobStore == nil {
+		return false
+	}
+	if server.ownsJob(object.Name) {
+		return true
+	}
+	if object.Kind != "Pod" {
+		return false
+	}
+	pod, err := server.clientset.CoreV1().Pods(object.Namespace).Get(ctx, object.Name, metav1.GetOptions{})
+	if err == nil {
+		return server.ownsJob(pod.Labels[batchv1.JobNameLabel]) || server.ownsJob(pod.Labels["job-name"])
+	}
+	// The pod can be gone already, pods of a job are named after the job with a random suffix
+	if index := strings.LastIndex(object.Name, "-"); index > 0 {
+		return server.ownsJob(object.Name[:index])
+	}
+	return false
+}
+
+// ownsJob reports whether OpenFero created the job of the name
+func (server *clientsetStruct) ownsJob(name string) bool {
+	if name == "" {
+		return false
+	}
+	_, exists, err := server.jobStore.GetByKey(server.jobDestinationNamespace + "/" + name)
+	return err == nil && exists
+}
+
+// matchesEvent reports whether the event has one of the configured types and reasons
+func matchesEvent(event *v1.Event, events config.Events) bool {
+	eventTypes := events.Types
+	if len(eventTypes) == 0 {
+		eventTypes = []string{config.EventTypeWarning}
+	}
+	if !slices.Contains(eventTypes, event.Type) {
+		return false
+	}
+	return len(events.Reasons) == 0 || slices.Contains(events.Reasons, event.Reason)
+}
+
+// eventMessage maps an event to a firing alert named like the reason of the event.
+// The involved object becomes the labels kind, name and namespace.
+func eventMessage(event *v1.Event) hookMessage {
+	object := event.InvolvedObject
+	component := event.Source.Component
+	if component == "" {
+		component = event.ReportingController
+	}
+	host := event.Source.Host
+	if host == "" {
+		host = event.ReportingInstance
+	}
+
+	startsAt := event.FirstTimestamp.Time
+	if startsAt.IsZero() {
+		startsAt = event.EventTime.Time
+	}
+	eventAlert := alert{
+		Status: "firing",
+		Labels: nonEmpty(map[string]string{
+			"alertname": event.Reason,
+			"reason":    event.Reason,
+			"type":      event.Type,
+			"kind":      object.Kind,
+			"name":      object.Name,
+			"namespace": object.Namespace,
+			"component": component,
+			"host":      host,
+		}),
+		Annotations: nonEmpty(map[string]string{
+			"message": event.Message,
+			"event":   event.Namespace + "/" + event.Name,
+			"count":   strconv.Itoa(int(eventCount(event))),
+		}),
+		Fingerprint: string(event.UID),
+	}
+	if !startsAt.IsZero() {
+		eventAlert.StartsAt = startsAt.UTC().Format(time.RFC3339)
+	}
+
+	groupKey := fmt.Sprintf("events/%s/%s/%s/%s", object.Namespace, object.Kind, object.Name, event.Reason)
+	return newHookMessage(groupKey, eventReceiver, map[string]string{"reason": event.Reason}, []alert{eventAlert})
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newTestEvent(uid string, eventType string, reason string, count int32) *v1.Event {
+	return &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0." + uid, Namespace: "shop", UID: types.UID(uid)},
+		InvolvedObject: v1.ObjectReference{
+			Kind:      "Pod",
+			Name:      "web-0",
+			Namespace: "shop",
+		},
+		Reason:         reason,
+		Message:        "MountVolume.SetUp failed for volume \"data\"",
+		Type:           eventType,
+		Count:          count,
+		Source:         v1.EventSource{Component: "kubelet", Host: "node-1"},
+		FirstTimestamp: metav1.NewTime(time.Date(2026, 1, 19, 10, 0, 0, 0, time.UTC)),
+	}
+}
+
+func TestMatchesEvent(t *testing.T) {
+	tests := []struct {
+		name   string
+		event  *v1.Event
+		events config.Events
+		want   bool
+	}{
+		{name: "Warning by default", event: newTestEvent("1", "Warning", "FailedMount", 1), want: true},
+		{name: "Normal not by default", event: newTestEvent("1", "Normal", "Scheduled", 1), want: false},
+		{name: "Configured type", event: newTestEvent("1", "Normal", "Scheduled", 1), events: config.Events{Types: []string{"Normal"}}, want: true},
+		{name: "Configured reason", event: newTestEvent("1", "Warning", "BackOff", 1), events: config.Events{Reasons: []string{"BackOff", "Evicted"}}, want: true},
+		{name: "Other reason", event: newTestEvent("1", "Warning", "FailedMount", 1), events: config.Events{Reasons: []string{"BackOff"}}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesEvent(tt.event, tt.events); got != tt.want {
+				t.Errorf("matchesEvent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventMessage(t *testing.T) {
+	event := newTestEvent("uid-1", "Warning", "FailedMount", 3)
+	message := eventMessage(event)
+
+	if message.Status != "firing" || message.Receiver != eventReceiver || message.GroupKey != "events/shop/Pod/web-0/FailedMount" {
+		t.Errorf("unexpected group context: %+v", message)
+	}
+	if len(message.Alerts) != 1 {
+		t.Fatalf("mapped %d alerts, want 1", len(message.Alerts))
+	}
+	alert := message.Alerts[0]
+	wantLabels := map[string]string{
+		"alertname": "FailedMount",
+		"reason":    "FailedMount",
+		"type":      "Warning",
+		"kind":      "Pod",
+		"name":      "web-0",
+		"namespace": "shop",
+		"component": "kubelet",
+		"host":      "node-1",
+	}
+	for key, want := range wantLabels {
+		if got := alert.Labels[key]; got != want {
+			t.Errorf("label %s = %q, want %q", key, got, want)
+		}
+	}
+	if alert.Annotations["count"] != "3" || alert.Annotations["event"] != "shop/web-0.uid-1" || alert.Annotations["message"] == "" {
+		t.Errorf("unexpected annotations: %v", alert.Annotations)
+	}
+	if alert.StartsAt != "2026-01-19T10:00:00Z" || alert.Fingerprint != "uid-1" {
+		t.Errorf("unexpected alert fields: %+v", alert)
+	}
+}
+
+func TestEventCount(t *testing.T) {
+	event := newTestEvent("1", "Warning", "BackOff", 0)
+	if got := eventCount(event); got != 1 {
+		t.Errorf("eventCount() = %d, want 1", got)
+	}
+	event.Series = &v1.EventSeries{Count: 7}
+	if got := eventCount(event); got != 7 {
+		t.Errorf("eventCount() = %d, want 7", got)
+	}
+}
+
+func TestEventTracker(t *testing.T) {
+	jobDefinition := `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: remount
+spec:
+  template:
+    spec:
+      containers:
+        - name: main
+          image: busybox
+      restartPolicy: Never
+`
+	alertStore = make([]alertStoreEntry, 0, 10)
+	configMapStore := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	if err := configMapStore.Add(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "openfero-failedmount-firing", Namespace: "default"},
+		Data:       map[string]string{"FailedMount": jobDefinition},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The event existing before the start must not trigger a job
+	clientset := fake.NewClientset(newTestEvent("old", "Warning", "FailedMount", 1))
+	server := &clientsetStruct{
+		clientset:               clientset,
+		configmapNamespace:      "default",
+		jobDestinationNamespace: "default",
+		configMapStore:          configMapStore,
+		jobStore:                cache.NewStore(cache.MetaNamespaceKeyFunc),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := newEventTracker(server).run(ctx, clientset, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	events := clientset.CoreV1().Events("shop")
+	event, err := events.Create(ctx, newTestEvent("new", "Warning", "FailedMount", 1), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := events.Create(ctx, newTestEvent("normal", "Normal", "FailedMount", 1), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	// Repeated occurrences of the event must not trigger another job
+	event.Count = 2
+	if _, err := events.Update(ctx, event, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		jobs, err := clientset.BatchV1().Jobs("default").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(jobs.Items) > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no job was created for the event")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Give further jobs time to show up before counting
+	time.Sleep(200 * time.Millisecond)
+	jobs, err := clientset.BatchV1().Jobs("default").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs.Items) != 1 {
+		t.Errorf("created %d jobs, want 1", len(jobs.Items))
+	}
+}
+
+func TestOwnsObject(t *testing.T) {
+	jobStore := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	if err := jobStore.Add(&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "remount-abc12", Namespace: "openfero"}}); err != nil {
+		t.Fatal(err)
+	}
+	server := &clientsetStruct{
+		clientset: fake.NewClientset(
+			&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "remount-abc12-x7k2p", Namespace: "openfero", Labels: map[string]string{batchv1.JobNameLabel: "remount-abc12"}}},
+			&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "backup-9f8d7", Namespace: "openfero", Labels: map[string]string{batchv1.JobNameLabel: "backup"}}},
+		),
+		jobDestinationNamespace: "openfero",
+		jobStore:                jobStore,
+	}
+
+	tests := []struct {
+		name   string
+		object v1.ObjectReference
+		want   bool
+	}{
+		{name: "Job of OpenFero", object: v1.ObjectReference{Kind: "Job", Name: "remount-abc12", Namespace: "openfero"}, want: true},
+		{name: "Pod of a job of OpenFero", object: v1.ObjectReference{Kind: "Pod", Name: "remount-abc12-x7k2p", Namespace: "openfero"}, want: true},
+		{name: "Deleted pod of a job of OpenFero", object: v1.ObjectReference{Kind: "Pod", Name: "remount-abc12-q9w8e", Namespace: "openfero"}, want: true},
+		{name: "Alert file of a job of OpenFero", object: v1.ObjectReference{Kind: "ConfigMap", Name: "remount-abc12", Namespace: "openfero"}, want: true},
+		{name: "Pod of another job", object: v1.ObjectReference{Kind: "Pod", Name: "backup-9f8d7", Namespace: "openfero"}},
+		{name: "Other namespace", object: v1.ObjectReference{Kind: "Job", Name: "remount-abc12", Namespace: "shop"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := server.ownsObject(context.Background(), tt.object); got != tt.want {
+				t.Errorf("ownsObject() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Events of the jobs of OpenFero do not start jobs
+	server.config.Store(config.Default())
+	event := newTestEvent("backoff", "Warning", "BackOff", 1)
+	event.InvolvedObject = v1.ObjectReference{Kind: "Pod", Name: "remount-abc12-x7k2p", Namespace: "openfero"}
+	if newEventTracker(server).observe(event, false) {
+		t.Error("observe() processed an event of a job of OpenFero")
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// maxLogLevelRequestBytes limits the body of a log level change
+const maxLogLevelRequestBytes = 1 << 10
+
+// @Description Log levels of OpenFero
+type logLevels struct {
+	// @Description Global log level
+	Level string `json:"level" enum:"debug,info,warn,error" example:"info"`
+	// @Description Log levels of subsystems which override the global level
+	Subsystems map[string]string `json:"subsystems"`
+}
+
+// @Description Request to change a log level
+type logLevelRequest struct {
+	// @Description Subsystem to change, the global level is changed if empty
+	Subsystem string `json:"subsystem,omitempty" enum:"webhook,informer,jobs"`
+	// @Description New log level, an empty level resets a subsystem to the global level and is rejected for the global level
+	Level string `json:"level" enum:"debug,info,warn,error" example:"debug"`
+}
+
+// @Summary Get log levels
+// @Description Get the global log level and the levels of all subsystems
+// @Tags logging
+// @Produce json
+// @Success 200 {object} logLevels
+// @Router /api/loglevel [get]
+func logLevelGetHandler(w http.ResponseWriter, r *http.Request) {
+	writeLogLevels(w)
+}
+
+// @Summary Change log level
+// @Description Change the global log level or the level of a subsystem without restart
+// @Tags logging
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body logLevelRequest true "Log level change"
+// @Success 200 {object} logLevels
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 413 {string} string "Request Entity Too Large"
+// @Router /api/loglevel [put]
+func logLevelPutHandler(w http.ResponseWriter, r *http.Request) {
+	request := logLevelRequest{}
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxLogLevelRequestBytes)).Decode(&request)
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	// An empty level would reset the global level to info, e.g. for a misspelled field
+	if request.Subsystem == "" && request.Level == "" {
+		http.Error(w, "level is required to change the global level", http.StatusBadRequest)
+		return
+	}
+
+	if request.Subsystem == "" {
+		err = log.SetLevel(request.Level)
+	} else {
+		err = log.SetSubsystemLevel(request.Subsystem, request.Level)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.FromContext(r.Context()).Info("Log level changed", zap.String("subsystem", request.Subsystem), zap.String("level", request.Level))
+	writeLogLevels(w)
+}
+
+func writeLogLevels(w http.ResponseWriter) {
+	level, subsystems := log.Levels()
+	w.Header().Set(contentType, applicationJSON)
+	if err := json.NewEncoder(w).Encode(logLevels{Level: level, Subsystems: subsystems}); err != nil {
+		log.Error("error encoding log levels", zap.String("error", err.Error()))
+	}
+}
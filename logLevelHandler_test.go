@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	log "github.com/OpenFero/openfero/pkg/logging"
+)
+
+func TestLogLevelPutHandler(t *testing.T) {
+	t.Cleanup(func() {
+		if err := initLogger(config.Logging{Level: "debug", Format: log.FormatConsole}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	tests := []struct {
+		name           string
+		body           string
+		wantStatus     int
+		wantLevel      string
+		wantSubsystems map[string]string
+	}{
+		{
+			name:           "Change global level",
+			body:           `{"level":"warn"}`,
+			wantStatus:     http.StatusOK,
+			wantLevel:      "warn",
+			wantSubsystems: map[string]string{},
+		},
+		{
+			name:           "Change subsystem level",
+			body:           `{"subsystem":"webhook","level":"debug"}`,
+			wantStatus:     http.StatusOK,
+			wantLevel:      "warn",
+			wantSubsystems: map[string]string{"webhook": "debug"},
+		},
+		{
+			name:           "Reset subsystem level",
+			body:           `{"subsystem":"webhook","level":""}`,
+			wantStatus:     http.StatusOK,
+			wantLevel:      "warn",
+			wantSubsystems: map[string]string{},
+		},
+		{
+			name:       "Invalid level",
+			body:       `{"level":"verbose"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid subsystem",
+			body:       `{"subsystem":"database","level":"debug"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Empty global level",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Misspelled level",
+			body:       `{"levle":"debug"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Body too large",
+			body:       `{"level":"debug","comment":"` + strings.Repeat("x", 2048) + `"}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "Invalid body",
+			body:       `level=debug`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/loglevel", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			logLevelPutHandler(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			levels := logLevels{}
+			if err := json.NewDecoder(rr.Body).Decode(&levels); err != nil {
+				t.Fatalf("could not decode response: %v", err)
+			}
+			if levels.Level != tt.wantLevel {
+				t.Errorf("level = %q, want %q", levels.Level, tt.wantLevel)
+			}
+			if len(levels.Subsystems) != len(tt.wantSubsystems) {
+				t.Errorf("subsystems = %v, want %v", levels.Subsystems, tt.wantSubsystems)
+			}
+			for subsystem, level := range tt.wantSubsystems {
+				if levels.Subsystems[subsystem] != level {
+					t.Errorf("subsystem %s level = %q, want %q", subsystem, levels.Subsystems[subsystem], level)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSubsystemLevels(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "Empty", input: "", want: map[string]string{}},
+		{name: "Single", input: "webhook=debug", want: map[string]string{"webhook": "debug"}},
+		{name: "Multiple with spaces", input: "webhook=debug, informer = warn", want: map[string]string{"webhook": "debug", "informer": "warn"}},
+		{name: "Missing level", input: "webhook", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSubsystemLevels(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSubsystemLevels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseSubsystemLevels() = %v, want %v", got, tt.want)
+			}
+			for key, value := range tt.want {
+				if got[key] != value {
+					t.Errorf("parseSubsystemLevels()[%s] = %q, want %q", key, got[key], value)
+				}
+			}
+		})
+	}
+}
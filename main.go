@@ -7,19 +7,30 @@ import (
 	"flag"
 	"fmt"
 	"html/template"
+	"maps"
 	"math/rand"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/OpenFero/openfero/pkg/config"
 	_ "github.com/OpenFero/openfero/pkg/docs"
 	log "github.com/OpenFero/openfero/pkg/logging"
 	"github.com/OpenFero/openfero/pkg/metadata"
+	"github.com/OpenFero/openfero/pkg/tracing"
 	"github.com/ghodss/yaml"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	batchv1 "k8s.io/api/batch/v1"
@@ -57,6 +68,8 @@ type hookMessage struct {
 	Version string `json:"version"`
 	// @Description Key used to group alerts
 	GroupKey string `json:"groupKey"`
+	// @Description Number of alerts Alertmanager dropped from the message because of the max_alerts limit
+	TruncatedAlerts int `json:"truncatedAlerts"`
 	// @Description Status of the alert group (firing/resolved)
 	Status string `json:"status" enum:"firing,resolved" example:"firing"`
 	// @Description Name of the receiver that handled the alert
@@ -70,11 +83,21 @@ type hookMessage struct {
 	// @Description External URL to the Alertmanager
 	ExternalURL string `json:"externalURL"`
 	// @Description List of alerts in the group
-	Alerts []alert `json:"alerts"`
+	Alerts []alert `json:"alerts,omitempty"`
+
+	// trigger is recorded on the jobs of messages not caused by alerts, e.g. schedule
+	trigger string
+	// rejected are the invalid alerts removed by acceptHookMessage
+	rejected []rejectedAlert
+	// alertStatuses selects the definition of every alert by its own status, set for messages built by OpenFero.
+	// Notifications of Alertmanager select it by the status of the notification.
+	alertStatuses bool
 }
 
 // @Description Alert information from Alertmanager
 type alert struct {
+	// @Description Status of the alert (firing/resolved)
+	Status string `json:"status,omitempty" enum:"firing,resolved" example:"firing"`
 	// @Description Key-value pairs of alert labels
 	Labels map[string]string `json:"labels"`
 	// @Description Key-value pairs of alert annotations
@@ -83,26 +106,69 @@ type alert struct {
 	StartsAt string `json:"startsAt,omitempty"`
 	// @Description Time when the alert ended
 	EndsAt string `json:"EndsAt,omitempty"`
+	// @Description URL of the rule which generated the alert
+	GeneratorURL string `json:"generatorURL,omitempty"`
+	// @Description Fingerprint identifying the alert
+	Fingerprint string `json:"fingerprint,omitempty"`
 }
 
 type clientsetStruct struct {
-	clientset               kubernetes.Clientset
+	clientset               kubernetes.Interface
 	jobDestinationNamespace string
 	configmapNamespace      string
 	configMapStore          cache.Store
 	jobStore                cache.Store
+	auth                    *apiAuthenticator
+	lifecycle               *jobLifecycle
+	approvals               *approvalStore
+	workflows               *workflowEngine
+	runs                    *runHistory
+	limiter                 *jobLimiter
+	config                  atomic.Pointer[config.Config]
 }
 
 type alertStoreEntry struct {
 	Alert     alert     `json:"alert"`
 	Status    string    `json:"status"`
 	Timestamp time.Time `json:"timestamp"`
+	// SkipReason tells why no job was created for the alert, e.g. a maintenance window
+	SkipReason string `json:"skipReason,omitempty"`
+	// Approval is the ID of the approval the job of the alert waits or waited for
+	Approval string `json:"approval,omitempty"`
 }
 
-var alertStore []alertStoreEntry
+var (
+	alertStore []alertStoreEntry
+	// alertStoreMu guards alertStore, which is written by the jobs of all alerts and by approval decisions
+	alertStoreMu sync.Mutex
+)
 
 const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
 
+// Label and annotation keys used by OpenFero on ConfigMaps and Jobs
+const (
+	jobDisabledLabel      = "openfero/job-disabled"
+	definitionAnnotation  = "openfero/definition"
+	alertnameAnnotation   = "openfero/alertname"
+	traceparentAnnotation = "openfero/traceparent"
+	groupKeyAnnotation    = "openfero/group-key"
+	approvedByAnnotation  = "openfero/approved-by"
+	// workflowAnnotation and workflowStepAnnotation mark the jobs of a step of a workflow
+	workflowAnnotation     = "openfero/workflow"
+	workflowStepAnnotation = "openfero/workflow-step"
+	// Annotations of the maintenance ConfigMap pausing the creation of all jobs
+	pausedAnnotation      = "openfero/paused"
+	pauseReasonAnnotation = "openfero/pause-reason"
+	pausedByAnnotation    = "openfero/paused-by"
+	pausedAtAnnotation    = "openfero/paused-at"
+	// triggerAnnotation records what caused a job, e.g. schedule, jobs without it were created for alerts
+	triggerAnnotation = "openfero/trigger"
+	// priorityAnnotation is the priority of a job when the job creation is rate limited
+	priorityAnnotation = "openfero/priority"
+)
+
+var errJobAlreadyExists = errors.New("job already exists")
+
 func initKubeClient(kubeconfig *string) *kubernetes.Clientset {
 	var config *rest.Config
 	var err error
@@ -143,13 +209,13 @@ func initConfigMapInformer(clientset *kubernetes.Clientset, configmapNamespace s
 	// Add event handlers to configMap informer
 	if _, err := configMapInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
-			log.Debug("ConfigMap added to store")
+			log.Subsystem(log.SubsystemInformer).Debug("ConfigMap added to store")
 		},
 		UpdateFunc: func(old, new interface{}) {
-			log.Debug("ConfigMap updated in store")
+			log.Subsystem(log.SubsystemInformer).Debug("ConfigMap updated in store")
 		},
 		DeleteFunc: func(obj interface{}) {
-			log.Debug("ConfigMap removed from store")
+			log.Subsystem(log.SubsystemInformer).Debug("ConfigMap removed from store")
 		},
 	}); err != nil {
 		log.Fatal("Failed to add ConfigMap event handler", zap.String("error", err.Error()))
@@ -162,12 +228,13 @@ func initConfigMapInformer(clientset *kubernetes.Clientset, configmapNamespace s
 	if !cache.WaitForCacheSync(context.Background().Done(), configMapInformer.HasSynced) {
 		log.Fatal("Failed to sync ConfigMap cache")
 	}
+	metadata.InformerSynced.WithLabelValues("configmap").Set(1)
 
 	return configMapInformer.GetStore()
 
 }
 
-func initJobInformer(clientset *kubernetes.Clientset, jobDestinationNamespace string, labelSelector metav1.LabelSelector) cache.Store {
+func initJobInformer(clientset *kubernetes.Clientset, jobDestinationNamespace string, labelSelector metav1.LabelSelector, lifecycle *jobLifecycle) cache.Store {
 	// Create informer factory
 	jobFactory := informers.NewSharedInformerFactoryWithOptions(
 		clientset,
@@ -181,31 +248,8 @@ func initJobInformer(clientset *kubernetes.Clientset, jobDestinationNamespace st
 	// Get Job informer
 	jobInformer := jobFactory.Batch().V1().Jobs().Informer()
 
-	// Add event handlers to job informer
-	// Add job event handlers
-	if _, err := jobInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc: func(obj interface{}) {
-			job := obj.(*batchv1.Job)
-			log.Debug("Job added: " + job.Name)
-			metadata.JobsCreatedTotal.Inc()
-		},
-		UpdateFunc: func(old, new interface{}) {
-			oldJob := old.(*batchv1.Job)
-			newJob := new.(*batchv1.Job)
-			if newJob.Status.Succeeded > 0 && oldJob.Status.Succeeded == 0 {
-				log.Debug("Job completed successfully: " + newJob.Name)
-				metadata.JobsSucceededTotal.Inc()
-			}
-			if newJob.Status.Failed > 0 && oldJob.Status.Failed == 0 {
-				log.Debug("Job failed: " + newJob.Name)
-				metadata.JobsFailedTotal.Inc()
-			}
-		},
-		DeleteFunc: func(obj interface{}) {
-			job := obj.(*batchv1.Job)
-			log.Debug("Job deleted: " + job.Name)
-		},
-	}); err != nil {
+	// Add job event handlers, the outcome tracker records job results once per job
+	if _, err := jobInformer.AddEventHandler(newJobOutcomeTracker(lifecycle).eventHandler()); err != nil {
 		log.Fatal("Failed to add Job event handler", zap.String("error", err.Error()))
 	}
 
@@ -216,24 +260,37 @@ func initJobInformer(clientset *kubernetes.Clientset, jobDestinationNamespace st
 	if !cache.WaitForCacheSync(context.Background().Done(), jobInformer.HasSynced) {
 		log.Fatal("Failed to sync Job cache")
 	}
+	metadata.InformerSynced.WithLabelValues("job").Set(1)
 
 	return jobInformer.GetStore()
 
 }
 
-// initLogger initializes the logger with the given log level
-func initLogger(logLevel string) error {
-	var cfg zap.Config
-	switch strings.ToLower(logLevel) {
-	case "debug":
-		cfg = zap.NewDevelopmentConfig()
-	case "info":
-		cfg = zap.NewProductionConfig()
-	default:
-		return fmt.Errorf("invalid log level specified: %s", logLevel)
-	}
+// initLogger initializes the logger with the given log level, format and subsystem levels
+func initLogger(cfg config.Logging) error {
+	return log.SetConfig(log.Config{
+		Level:           cfg.Level,
+		Format:          cfg.Format,
+		Stacktrace:      cfg.Stacktrace,
+		SubsystemLevels: cfg.Subsystems,
+	})
+}
 
-	return log.SetConfig(cfg)
+// parseSubsystemLevels parses a comma separated list of subsystem=level pairs
+func parseSubsystemLevels(subsystemLevels string) (map[string]string, error) {
+	levels := make(map[string]string)
+	for _, pair := range strings.Split(subsystemLevels, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		subsystem, level, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid subsystem log level specified: %s", pair)
+		}
+		levels[strings.TrimSpace(subsystem)] = strings.TrimSpace(level)
+	}
+	return levels, nil
 }
 
 // @title OpenFero API
@@ -249,37 +306,81 @@ func initLogger(logLevel string) error {
 
 // @host localhost:8080
 // @BasePath /
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Bearer token of the administrative API, e.g. "Bearer <token>"
 func main() {
 
-	// Parse command line arguments
-	addr := flag.String("addr", ":8080", "address to listen for webhook")
-	logLevel := flag.String("logLevel", "info", "log level")
+	// Parse command line arguments, explicitly set flags take precedence over the configuration file
+	defaults := config.Default()
+	configPath := flag.String("config", "", "path to the YAML configuration file")
+	validateConfig := flag.Bool("validateConfig", false, "validate the configuration and exit")
+	configReloadInterval := flag.Duration("configReloadInterval", 10*time.Second, "interval to check the configuration file for changes")
+	flag.String("addr", defaults.Server.Addr, "address to listen for webhook")
+	flag.String("logLevel", defaults.Logging.Level, "log level (debug, info, warn or error)")
+	flag.String("logFormat", defaults.Logging.Format, "log format (json or console)")
+	flag.Bool("logStacktrace", defaults.Logging.Stacktrace, "add stack traces to error logs")
+	flag.String("logSubsystemLevels", "", "log levels of subsystems, e.g. webhook=debug,informer=warn")
 	kubeconfig := flag.String("kubeconfig", "", "absolute path to the kubeconfig file")
-	configmapNamespace := flag.String("configmapNamespace", "", "Kubernetes namespace where jobs are defined")
-	jobDestinationNamespace := flag.String("jobDestinationNamespace", "", "Kubernetes namespace where jobs will be created")
-	readTimeout := flag.Int("readTimeout", 5, "read timeout in seconds")
-	writeTimeout := flag.Int("writeTimeout", 10, "write timeout in seconds")
-	alertStoreSize := flag.Int("alertStoreSize", 10, "size of the alert store")
+	flag.String("configmapNamespace", "", "Kubernetes namespace where jobs are defined")
+	flag.String("jobDestinationNamespace", "", "Kubernetes namespace where jobs will be created")
+	flag.Int("readTimeout", int(defaults.Server.ReadTimeout.Duration().Seconds()), "read timeout in seconds")
+	flag.Int("writeTimeout", int(defaults.Server.WriteTimeout.Duration().Seconds()), "write timeout in seconds")
+	flag.Int("alertStoreSize", defaults.Store.AlertStoreSize, "size of the alert store and of the histories of approvals, workflows and runs")
+	flag.String("otelExporter", defaults.Tracing.Exporter, "OpenTelemetry trace exporter (none, otlpgrpc or otlphttp)")
+	flag.String("otelEndpoint", "", "OTLP collector endpoint, defaults to the OTEL_EXPORTER_OTLP_ENDPOINT environment variable")
+	flag.Bool("otelInsecure", defaults.Tracing.Insecure, "disable TLS for the connection to the OTLP collector")
+	flag.Float64("otelSampleRatio", defaults.Tracing.SampleRatio, "ratio of sampled traces when no parent sampling decision exists")
 
 	flag.Parse()
 
+	configLoader := config.Loader{Path: *configPath, Override: flagOverrides()}
+	cfg, err := configLoader.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid configuration:", err)
+		os.Exit(1)
+	}
+	if *validateConfig {
+		fmt.Println("Configuration is valid")
+		os.Exit(0)
+	}
+
 	// Set the alert store size
-	alertStore = make([]alertStoreEntry, 0, *alertStoreSize)
+	alertStore = make([]alertStoreEntry, 0, cfg.Store.AlertStoreSize)
 
 	// configure log
-	if err := initLogger(*logLevel); err != nil {
-		log.Fatal("Could not set log configuration")
+	if err := initLogger(cfg.Logging); err != nil {
+		fmt.Fprintln(os.Stderr, "Could not set log configuration:", err)
+		os.Exit(1)
 	}
 
 	log.Info("Starting OpenFero", zap.String("version", version), zap.String("commit", commit), zap.String("date", date))
 
+	// configure tracing
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	}, version)
+	if err != nil {
+		log.Fatal("Could not set tracing configuration", zap.String("error", err.Error()))
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Error("error shutting down tracing", zap.String("error", err.Error()))
+		}
+	}()
+
 	// Use the in-cluster config to create a kubernetes client
 	clientset := initKubeClient(kubeconfig)
 	defaultNamespaceLocation := "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
 	currentNamespace := ""
 
 	//Check if running in-cluster or out-of-cluster
-	_, err := rest.InClusterConfig()
+	_, err = rest.InClusterConfig()
 	if err != nil {
 		log.Debug("Using out of cluster configuration")
 		// Extract the current namespace from the client config
@@ -295,17 +396,19 @@ func main() {
 		}
 		namespaceDat, err := os.ReadFile(defaultNamespaceLocation)
 		if err != nil {
-			log.Fatal("Couldn't read from "+defaultNamespaceLocation, zap.String("error", err.Error()))
+			log.Fatal("Couldn't read namespace file", zap.String("path", defaultNamespaceLocation), zap.String("error", err.Error()))
 		}
 		currentNamespace = string(namespaceDat)
 	}
 
-	if *configmapNamespace == "" {
-		configmapNamespace = &currentNamespace
+	configmapNamespace := cfg.Namespaces.Configmap
+	if configmapNamespace == "" {
+		configmapNamespace = currentNamespace
 	}
 
-	if *jobDestinationNamespace == "" {
-		jobDestinationNamespace = &currentNamespace
+	jobDestinationNamespace := cfg.Namespaces.JobDestination
+	if jobDestinationNamespace == "" {
+		jobDestinationNamespace = currentNamespace
 	}
 
 	// Create label selector for openfero ConfigMaps
@@ -316,18 +419,49 @@ func main() {
 	}
 
 	// Create informer factory for configmaps
-	configMapInformer := initConfigMapInformer(clientset, *configmapNamespace)
-	// Create informer factory for jobs
-	jobInformer := initJobInformer(clientset, *jobDestinationNamespace, labelSelector)
+	configMapInformer := initConfigMapInformer(clientset, configmapNamespace)
 
 	server := &clientsetStruct{
-		clientset:               *clientset,
-		jobDestinationNamespace: *jobDestinationNamespace,
-		configmapNamespace:      *configmapNamespace,
+		clientset:               clientset,
+		jobDestinationNamespace: jobDestinationNamespace,
+		configmapNamespace:      configmapNamespace,
 		configMapStore:          configMapInformer,
-		jobStore:                jobInformer,
+		auth:                    newAPIAuthenticator(apiTokens(cfg.Auth)),
+		approvals:               newApprovalStore(cfg.Store.AlertStoreSize),
+		runs:                    newRunHistory(cfg.Store.AlertStoreSize),
+		limiter:                 newJobLimiter(cfg.RateLimit),
+	}
+	server.config.Store(cfg)
+	server.workflows = newWorkflowEngine(server, cfg.Store.AlertStoreSize)
+
+	// Listeners are notified about the lifecycle of the created jobs
+	jobListeners := []jobListener{newAlertmanagerWriteBack(server.currentConfig), newJobNotifier(server.currentConfig), server.runs, server.workflows}
+	if cfg.CloudEvents.Sink != "" {
+		emitter, err := newCloudEventEmitter(cfg.CloudEvents)
+		if err != nil {
+			log.Fatal("Could not create CloudEvents client", zap.String("error", err.Error()))
+		}
+		jobListeners = append(jobListeners, emitter)
+	}
+	server.lifecycle = newJobLifecycle(jobListeners...)
+	server.lifecycle.readOutputs = server.jobOutputs
+
+	// Create informer factory for jobs
+	server.jobStore = initJobInformer(clientset, jobDestinationNamespace, labelSelector, server.lifecycle)
+
+	// Create jobs for Kubernetes Events
+	if cfg.Events.Enabled {
+		if err := newEventTracker(server).run(context.Background(), clientset, cfg.Events.Namespace); err != nil {
+			log.Fatal("Failed to start Event informer", zap.String("error", err.Error()))
+		}
 	}
 
+	// Run definitions with a schedule, with several instances only in the leader
+	go runScheduler(context.Background(), server, clientset, cfg.Scheduler)
+
+	// Apply changes of the configuration file without restart
+	go configLoader.Watch(context.Background(), *configReloadInterval, server.reloadConfig)
+
 	//register metrics and set prometheus handler
 	metadata.AddMetricsToPrometheusRegistry()
 	http.Handle(metadata.MetricsPath, promhttp.Handler())
@@ -338,8 +472,25 @@ func main() {
 	http.HandleFunc("GET /alertStore", server.alertStoreGetHandler)
 	http.HandleFunc("GET /alerts", server.alertsGetHandler)
 	http.HandleFunc("POST /alerts", server.alertsPostHandler)
+	http.HandleFunc("POST /alerts/grafana", server.grafanaAlertsPostHandler)
+	http.HandleFunc("POST /hooks/{source}", server.hooksPostHandler)
+	http.HandleFunc("POST /cloudevents", server.cloudEventsPostHandler)
+	// Reading is public like the UI pages, changes require an API token
+	http.HandleFunc("GET /api/loglevel", logLevelGetHandler)
+	http.HandleFunc("PUT /api/loglevel", server.auth.requireAuth(logLevelPutHandler))
+	http.HandleFunc("GET /api/approvals", server.auth.requireAuth(server.approvalsGetHandler))
+	http.HandleFunc("POST /api/approvals/{id}/approve", server.auth.requireAuth(server.approvalApproveHandler))
+	http.HandleFunc("POST /api/approvals/{id}/reject", server.auth.requireAuth(server.approvalRejectHandler))
+	http.HandleFunc("GET /api/workflows", server.workflowsGetHandler)
+	http.HandleFunc("GET /api/runs", server.runsGetHandler)
+	http.HandleFunc("GET /api/pause", server.pauseGetHandler)
+	http.HandleFunc("PUT /api/pause", server.auth.requireAuth(server.pausePutHandler))
 	http.HandleFunc("GET /ui", uiHandler)
 	http.HandleFunc("GET /ui/jobs", server.jobsUIHandler)
+	http.HandleFunc("GET /ui/runs", server.runsUIHandler)
+	http.HandleFunc("GET /ui/approvals", server.approvalsUIHandler)
+	http.HandleFunc("GET /ui/workflows", server.workflowsUIHandler)
+	http.HandleFunc("GET /ui/maintenance", server.maintenanceUIHandler)
 	http.HandleFunc("GET /assets/", assetsHandler)
 	http.Handle("GET /swagger/", httpSwagger.Handler(
 		httpSwagger.DeepLinking(true),
@@ -348,12 +499,13 @@ func main() {
 	))
 
 	srv := &http.Server{
-		Addr:         *addr,
-		ReadTimeout:  time.Duration(*readTimeout) * time.Second,
-		WriteTimeout: time.Duration(*writeTimeout) * time.Second,
+		Addr:         cfg.Server.Addr,
+		Handler:      requestIDMiddleware(http.DefaultServeMux),
+		ReadTimeout:  cfg.Server.ReadTimeout.Duration(),
+		WriteTimeout: cfg.Server.WriteTimeout.Duration(),
 	}
 
-	log.Info("Starting server on " + *addr)
+	log.Info("Starting server", zap.String("addr", cfg.Server.Addr))
 	if err := srv.ListenAndServe(); err != nil {
 		log.Fatal("error starting server: ", zap.String("error", err.Error()))
 	}
@@ -426,175 +578,417 @@ func (server *clientsetStruct) alertsGetHandler(httpwriter http.ResponseWriter,
 // @Accept json
 // @Produce json
 // @Param message body hookMessage true "Alert message"
-// @Success 200
-// @Failure 400 {string} string "Bad Request"
+// @Param sync query bool false "Create the jobs before answering, overrides webhook.synchronous"
+// @Success 200 {object} webhookResponse "Jobs created, synchronous mode"
+// @Success 202 {object} webhookResponse "Jobs queued"
+// @Failure 400 {object} webhookError "Invalid body, status or no valid alert"
+// @Failure 413 {object} webhookError "Body or number of alerts exceeds the limits"
 // @Router /alerts [post]
 // Handling the Alertmanager Post-Requests
 func (server *clientsetStruct) alertsPostHandler(httpwriter http.ResponseWriter, httprequest *http.Request) {
+	ctx, span := startWebhookSpan(httprequest, "alertmanager")
+	defer span.End()
 
-	dec := json.NewDecoder(httprequest.Body)
-	defer httprequest.Body.Close()
-
+	limits := server.currentConfig().Limits
 	message := hookMessage{}
-	if err := dec.Decode(&message); err != nil {
-		log.Error("error decoding message: ", zap.String("error", err.Error()))
-		http.Error(httpwriter, "invalid request body", http.StatusBadRequest)
+	if !decodeWebhook(ctx, httpwriter, httprequest, &message, limits) || !acceptHookMessage(ctx, httpwriter, &message, limits) {
 		return
 	}
+	server.respondToWebhook(ctx, httpwriter, httprequest, &message)
+}
 
+// startWebhookSpan starts the span of a webhook request and continues a trace started by its sender
+func startWebhookSpan(httprequest *http.Request, source string) (context.Context, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(httprequest.Context(), propagation.HeaderCarrier(httprequest.Header))
+	ctx = log.WithSubsystem(ctx, log.SubsystemWebhook)
+	ctx = log.WithFields(ctx, zap.String("source", source))
+	return tracing.Tracer().Start(ctx, "webhook", trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+		attribute.String("openfero.source", source),
+	))
+}
+
+// processHookMessage creates the response jobs for all alerts of a notification and returns the result of every alert.
+// Job creation runs in the background, so the sender of the webhook does not wait for it. Results of alerts
+// processed within timeout are returned, alerts still in progress and all alerts without timeout are queued.
+func (server *clientsetStruct) processHookMessage(ctx context.Context, message *hookMessage, timeout time.Duration) []alertResult {
+	span := trace.SpanFromContext(ctx)
 	status := sanitizeInput(message.Status)
 	alertcount := len(message.Alerts)
+	ctx = log.WithFields(ctx, zap.String(log.GroupKeyKey, sanitizeInput(message.GroupKey)), zap.String(log.StatusKey, status))
+	span.SetAttributes(
+		attribute.String("openfero.status", status),
+		attribute.String("openfero.group_key", message.GroupKey),
+		attribute.Int("openfero.alert_count", alertcount),
+	)
 
-	log.Debug(status + " webhook received with " + fmt.Sprint(alertcount) + " alerts")
+	logger := log.FromContext(ctx)
+	logger.Debug("Webhook received", zap.Int("alerts", alertcount))
 
-	if !checkAlertStatus(status) {
-		log.Warn("Status of alert was neither firing nor resolved, stop creating a response job.")
-		return
+	results := make([]alertResult, alertcount)
+	for index, alert := range message.Alerts {
+		results[index] = alertResult{Alertname: sanitizeInput(alert.Labels["alertname"]), Fingerprint: alert.Fingerprint, Result: resultQueued}
 	}
 
-	log.Debug("Creating response job for " + fmt.Sprint(alertcount) + " alerts")
+	if !checkAlertStatus(status) {
+		logger.Warn("Status of alert was neither firing nor resolved, stop creating a response job.")
+		span.SetStatus(codes.Error, "invalid alert status")
+		for index := range results {
+			results[index] = results[index].skipped("status is neither firing nor resolved")
+		}
+		return results
+	}
 
-	for _, alert := range message.Alerts {
-		go server.createResponseJob(alert, status, httpwriter)
+	logger.Debug("Creating response jobs", zap.Int("alerts", alertcount))
+
+	// Job creation outlives the request, so it must not be canceled with it
+	jobCtx := context.WithoutCancel(ctx)
+	done := make(chan indexedResult, alertcount)
+	for index, alert := range message.Alerts {
+		// Alerts of generic sources can be resolved while the message is firing
+		alertStatus := alertStatus(message, alert, status)
+		metadata.AlertsReceivedTotal.WithLabelValues(sanitizeInput(alert.Labels["alertname"]), alertStatus).Inc()
+		metadata.JobQueueDepth.Inc()
+		go func() {
+			defer metadata.JobQueueDepth.Dec()
+			done <- indexedResult{index: index, result: server.createResponseJob(jobCtx, message, index, alertStatus)}
+		}()
+	}
+	if timeout <= 0 {
+		return results
 	}
 
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for range alertcount {
+		select {
+		case finished := <-done:
+			results[finished.index] = finished.result
+		case <-timer.C:
+			logger.Warn("Jobs were not created within the timeout of the synchronous response", zap.Duration("timeout", timeout))
+			return results
+		}
+	}
+	return results
 }
 
 func checkAlertStatus(status string) bool {
 	return status == "resolved" || status == "firing"
 }
 
+// alertStatus returns the status selecting the definition of the alert. It is the status of the message,
+// or of the alert itself for messages built by OpenFero if the alert has one.
+func alertStatus(message *hookMessage, alert alert, status string) string {
+	if message.alertStatuses && checkAlertStatus(alert.Status) {
+		return alert.Status
+	}
+	return status
+}
+
 func sanitizeInput(input string) string {
 	input = strings.ReplaceAll(input, "\n", "")
 	input = strings.ReplaceAll(input, "\r", "")
 	return input
 }
 
-func (server *clientsetStruct) createResponseJob(alert alert, status string, _ http.ResponseWriter) {
-	server.saveAlert(alert, status)
+// createResponseJob creates the job for the alert at index of the notification group.
+// For definitions running per group only the first alert with the alertname creates a job for all of them.
+// It returns what happened to the alert.
+func (server *clientsetStruct) createResponseJob(ctx context.Context, group *hookMessage, index int, status string) alertResult {
+	alert := group.Alerts[index]
 	alertname := sanitizeInput(alert.Labels["alertname"])
+	result := alertResult{Alertname: alertname, Fingerprint: alert.Fingerprint}
+	ctx, span := tracing.Tracer().Start(ctx, "alert", trace.WithAttributes(
+		attribute.String("openfero.alertname", alertname),
+		attribute.String("openfero.status", status),
+	))
+	defer span.End()
+	ctx = log.WithSubsystem(ctx, log.SubsystemJobs)
+	ctx = log.WithFields(ctx, zap.String(log.AlertnameKey, alertname), zap.String(log.FingerprintKey, sanitizeInput(alert.Fingerprint)))
+	logger := log.FromContext(ctx)
+
 	responsesConfigmap := strings.ToLower("openfero-" + alertname + "-" + status)
-	log.Debug("Try to load configmap " + responsesConfigmap)
+	cfg := server.currentConfig()
+
+	// Alerts are recorded during a pause or maintenance window, but no jobs are created
+	skipReason, skipMetricReason := server.maintenanceSkipReason(cfg, alert.Labels, time.Now())
+	server.saveAlert(alert, status, skipReason)
+	if skipReason != "" {
+		logger.Info("Skipping job creation", zap.String("reason", skipReason))
+		metadata.JobsSkippedTotal.WithLabelValues(alertname, responsesConfigmap, skipMetricReason).Inc()
+		return result.skipped(skipReason)
+	}
+
+	if slices.Contains(cfg.Policy.DisabledAlerts, alertname) {
+		logger.Info("Alert is disabled by policy, skipping job creation")
+		metadata.JobsSkippedTotal.WithLabelValues(alertname, responsesConfigmap, metadata.SkipReasonPolicy).Inc()
+		return result.skipped("alert is disabled by policy")
+	}
+
+	configMap, jobDefinition, err := server.lookupJobDefinition(ctx, responsesConfigmap, alertname)
+	if err != nil {
+		logger.Error("error getting configmap from store", zap.String("error", err.Error()))
+		span.SetStatus(codes.Error, err.Error())
+		return result.failed(err)
+	}
+	if configMap == nil {
+		logger.Error("configmap not found in store", zap.String("definition", responsesConfigmap))
+		metadata.AlertsWithoutDefinitionTotal.WithLabelValues(alertname, status).Inc()
+		return result.skipped("no definition " + responsesConfigmap)
+	}
+	result.Definition = responsesConfigmap
+
+	if configMap.Labels[jobDisabledLabel] == "true" {
+		logger.Info("Job definition is disabled, skipping job creation", zap.String("definition", responsesConfigmap))
+		metadata.JobsSkippedTotal.WithLabelValues(alertname, responsesConfigmap, metadata.SkipReasonDisabled).Inc()
+		return result.skipped("definition is disabled")
+	}
+
+	settings, err := parseDefinitionSettings(configMap)
+	if err != nil {
+		logger.Error("error parsing definition settings", zap.String("definition", responsesConfigmap), zap.String("error", err.Error()))
+		span.SetStatus(codes.Error, err.Error())
+		return result.failed(err)
+	}
+
+	// Workflows take their job definitions from the steps
+	if jobDefinition == "" && settings.Workflow == nil {
+		logger.Error("Could not find a data block with the alertname as key in the configmap", zap.String("definition", responsesConfigmap))
+		metadata.AlertsWithoutDefinitionTotal.WithLabelValues(alertname, status).Inc()
+		return result.skipped("definition has no job for the alertname")
+	}
+
+	settings = settings.withGlobal(cfg)
+	data := alertContext{group: group, alert: alert}
+	if settings.Mode == modePerGroup {
+		first, alerts := groupAlerts(group, alertname, status)
+		if first != index {
+			logger.Debug("Alert is handled by the job of its group", zap.String("definition", responsesConfigmap))
+			return result.skipped(fmt.Sprintf("handled by the job of alert %d of the group", first))
+		}
+		data.alerts = alerts
+		span.SetAttributes(attribute.Int("openfero.alert_count", len(alerts)))
+	}
+	if settings.Workflow != nil {
+		run := newWorkflowRun(ctx, configMap, data, responsesConfigmap, alertname, settings)
+		result.Workflow = run.status.ID
+		if settings.RequiresApproval {
+			return result.parked(server.parkForApproval(ctx, &pendingJob{data: data, settings: settings, workflow: run}, responsesConfigmap, alertname, status))
+		}
+		server.workflows.start(ctx, run)
+		result.Result = resultCreated
+		return result
+	}
+	jobObject, err := renderJob(ctx, jobDefinition, data, responsesConfigmap, alertname, settings)
+	if err != nil {
+		logger.Error("error rendering job definition", zap.String("definition", responsesConfigmap), zap.String("error", err.Error()))
+		span.SetStatus(codes.Error, err.Error())
+		return result.failed(err)
+	}
+
+	file, err := newAlertFile(data, settings.Injection)
+	if err != nil {
+		logger.Error("error rendering alert file", zap.String("definition", responsesConfigmap), zap.String("error", err.Error()))
+		span.SetStatus(codes.Error, err.Error())
+		return result.failed(err)
+	}
+
+	// Create the job
+	ctx = log.WithFields(ctx, zap.String(log.JobKey, jobObject.Name))
+	logger = log.FromContext(ctx)
+	result.Job = jobObject.Name
+	if settings.RequiresApproval {
+		return result.parked(server.parkForApproval(ctx, &pendingJob{job: jobObject, file: file, data: data, settings: settings}, responsesConfigmap, alertname, status))
+	}
+	created, err := server.createRemediationJob(ctx, jobObject, file)
+	if errors.Is(err, errJobAlreadyExists) {
+		logger.Info("Job already exists, skipping job creation")
+		metadata.JobsSkippedTotal.WithLabelValues(alertname, responsesConfigmap, metadata.SkipReasonDeduplicated).Inc()
+		return result.skipped("job already exists")
+	}
+	if errors.Is(err, errJobShed) {
+		return result.skipped(err.Error())
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return result.failed(err)
+	}
+	server.lifecycle.created(ctx, &jobRun{job: created, data: data, settings: &settings})
+
+	if startsAt, err := time.Parse(time.RFC3339, alert.StartsAt); err == nil && !startsAt.IsZero() {
+		metadata.AlertToJobStartSeconds.WithLabelValues(alertname, responsesConfigmap).Observe(time.Since(startsAt).Seconds())
+	}
+	result.Result = resultCreated
+	return result
+}
+
+// lookupJobDefinition gets the ConfigMap of a definition from the store and returns the job definition for the alertname.
+// The returned ConfigMap is nil if the definition does not exist.
+func (server *clientsetStruct) lookupJobDefinition(ctx context.Context, configMapName string, alertname string) (*v1.ConfigMap, string, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "definition.lookup", trace.WithAttributes(
+		attribute.String("openfero.definition", configMapName),
+	))
+	defer span.End()
+
+	log.FromContext(ctx).Debug("Try to load configmap", zap.String("definition", configMapName))
 
 	// Get ConfigMap from store instead of API
-	obj, exists, err := server.configMapStore.GetByKey(server.configmapNamespace + "/" + responsesConfigmap)
+	obj, exists, err := server.configMapStore.GetByKey(server.configmapNamespace + "/" + configMapName)
 	if err != nil {
-		log.Error("error getting configmap from store: ", zap.String("error", err.Error()))
-		return
+		span.SetStatus(codes.Error, err.Error())
+		return nil, "", err
 	}
+	span.SetAttributes(attribute.Bool("openfero.definition_found", exists))
 	if !exists {
-		log.Error("configmap not found in store")
-		return
+		return nil, "", nil
 	}
 
 	configMap := obj.(*v1.ConfigMap)
+	return configMap, configMap.Data[alertname], nil
+}
 
-	jobDefinition := configMap.Data[alertname]
+// renderJob creates the job object from a YAML job definition and enriches it with the alert
+func renderJob(ctx context.Context, jobDefinition string, data alertContext, definition string, alertname string, settings definitionSettings) (*batchv1.Job, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "job.render", trace.WithAttributes(
+		attribute.String("openfero.definition", definition),
+	))
+	defer span.End()
 
-	if jobDefinition == "" {
-		log.Error("Could not find a data block with the key " + alertname + " in the configmap.")
-		return
-	}
 	yamlJobDefinition := []byte(jobDefinition)
 
 	// yamlJobDefinition contains a []byte of the yaml job spec
 	// convert the yaml to json so it works with Unmarshal
 	jsonBytes, err := yaml.YAMLToJSON(yamlJobDefinition)
 	if err != nil {
-		log.Error("error while converting YAML job definition to JSON: ", zap.String("error", err.Error()))
-		return
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("error while converting YAML job definition to JSON: %w", err)
 	}
 	randomstring := stringWithCharset(5, charset)
 
 	jobObject := &batchv1.Job{}
 	err = json.Unmarshal(jsonBytes, jobObject)
 	if err != nil {
-		log.Error("Error while using unmarshal on received job: ", zap.String("error", err.Error()))
-		return
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("error while using unmarshal on received job: %w", err)
 	}
 
 	// Adding randomString to avoid name conflict
 	jobObject.SetName(jobObject.Name + "-" + randomstring)
 
-	// Adding alert labels to job
-	addLabelsAsEnvVars(jobObject, alert)
+	// Adding alert labels and annotations to the selected containers
+	log.FromContext(ctx).Debug("Adding alert context to containers")
+	injectAlertContext(jobObject, data, settings.Injection)
 
-	// Adding TTL to job if it is not already set
-	if !checkJobTTL(jobObject) {
-		addJobTTL(jobObject)
-	}
+	// Adding TTL, labels and the other defaults which are not set by the definition
+	applyJobDefaults(jobObject, settings.JobDefaults)
 
-	// Adding labels to job if they are not already set
-	if !checkJobLabels(jobObject) {
-		addJobLabels(jobObject)
+	// Adding alert metadata as annotations to job
+	addJobAnnotations(jobObject, definition, alertname)
+	if data.group != nil && data.group.GroupKey != "" {
+		jobObject.Annotations[groupKeyAnnotation] = data.group.GroupKey
 	}
-
-	// Create the job
-	err = server.createRemediationJob(jobObject)
-	if err != nil {
-		log.Error("error creating job: ", zap.String("error", err.Error()))
-		return
+	if data.group != nil && data.group.trigger != "" {
+		jobObject.Annotations[triggerAnnotation] = data.group.trigger
 	}
+	jobObject.Annotations[priorityAnnotation] = jobPriority(data, settings)
+
+	// Adding the trace context so remediation scripts can continue the trace
+	addTraceContext(ctx, jobObject)
 
+	return jobObject, nil
 }
 
-func (server *clientsetStruct) createRemediationJob(jobObject *batchv1.Job) error {
+// createRemediationJob creates the job and, if given, the alert file mounted by the job, and returns the created job
+func (server *clientsetStruct) createRemediationJob(ctx context.Context, jobObject *batchv1.Job, file *alertFile) (*batchv1.Job, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "job.create", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("openfero.job", jobObject.Name),
+		attribute.String("openfero.namespace", server.jobDestinationNamespace),
+	))
+	defer span.End()
+
+	logger := log.FromContext(ctx)
+
 	// Check if job already exists
 	_, exists, err := server.jobStore.GetByKey(server.jobDestinationNamespace + "/" + jobObject.Name)
 	if err != nil {
-		log.Error("error checking job existence: ", zap.String("error", err.Error()))
-		return err
+		logger.Error("error checking job existence", zap.String("error", err.Error()))
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 	if exists {
-		return fmt.Errorf("job %s already exists", jobObject.Name)
+		return nil, fmt.Errorf("%w: %s", errJobAlreadyExists, jobObject.Name)
+	}
+
+	// Wait for the rate limit, jobs of a higher priority are created first
+	if err := server.limiter.wait(ctx, jobObject.Annotations[priorityAnnotation]); err != nil {
+		if errors.Is(err, errJobShed) {
+			logger.Warn("Backlog of the job creation is full, dropping job", zap.String("priority", jobObject.Annotations[priorityAnnotation]))
+			metadata.JobsSkippedTotal.WithLabelValues(jobObject.Annotations[alertnameAnnotation], jobObject.Annotations[definitionAnnotation], metadata.SkipReasonShed).Inc()
+		}
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 
 	// Create job
 	jobsClient := server.clientset.BatchV1().Jobs(server.jobDestinationNamespace)
-	log.Info("Creating job " + jobObject.Name)
-	_, err = jobsClient.Create(context.TODO(), jobObject, metav1.CreateOptions{})
+	logger.Info("Creating job")
+	created, err := jobsClient.Create(ctx, jobObject, metav1.CreateOptions{})
 	if err != nil {
-		log.Error("error creating job: ", zap.String("error", err.Error()))
-		return err
+		logger.Error("error creating job", zap.String("error", err.Error()))
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
-	log.Info("Job " + jobObject.Name + " created successfully")
-	return nil
-}
-
-func addLabelsAsEnvVars(jobObject *batchv1.Job, alert alert) {
-	// Adding Labels as Environment variables
-	log.Debug("Adding labels as environment variables")
-	for labelkey, labelvalue := range alert.Labels {
-		jobObject.Spec.Template.Spec.Containers[0].Env = append(jobObject.Spec.Template.Spec.Containers[0].Env, v1.EnvVar{Name: "OPENFERO_" + strings.ToUpper(labelkey), Value: labelvalue})
+	if file != nil {
+		if err := server.createAlertFile(ctx, created, file); err != nil {
+			logger.Error("error creating alert file, deleting job", zap.String("error", err.Error()))
+			span.SetStatus(codes.Error, err.Error())
+			deletePolicy := metav1.DeletePropagationBackground
+			if deleteErr := jobsClient.Delete(ctx, created.Name, metav1.DeleteOptions{PropagationPolicy: &deletePolicy}); deleteErr != nil {
+				logger.Error("error deleting job", zap.String("error", deleteErr.Error()))
+			}
+			return nil, err
+		}
 	}
+	logger.Info("Job created successfully")
+	metadata.JobsCreatedTotal.WithLabelValues(jobObject.Annotations[definitionAnnotation], jobObject.Annotations[alertnameAnnotation]).Inc()
+	return created, nil
 }
 
-func checkJobTTL(jobObject *batchv1.Job) bool {
-	return jobObject.Spec.TTLSecondsAfterFinished != nil
-}
-
-func addJobTTL(jobObject *batchv1.Job) {
-	ttl := int32(300)
-	jobObject.Spec.TTLSecondsAfterFinished = &ttl
-}
-
-func checkJobLabels(jobObject *batchv1.Job) bool {
-	return jobObject.Labels != nil
+// addTraceContext adds the W3C traceparent of the current span as annotation and environment variable to the job
+func addTraceContext(ctx context.Context, jobObject *batchv1.Job) {
+	traceparent := tracing.Traceparent(ctx)
+	if traceparent == "" {
+		return
+	}
+	if jobObject.Annotations == nil {
+		jobObject.Annotations = make(map[string]string)
+	}
+	jobObject.Annotations[traceparentAnnotation] = traceparent
+	containers := jobObject.Spec.Template.Spec.Containers
+	for i := range containers {
+		containers[i].Env = append(containers[i].Env, v1.EnvVar{Name: traceparentEnv, Value: traceparent})
+	}
 }
 
-func addJobLabels(jobObject *batchv1.Job) {
-	jobObject.Labels = make(map[string]string)
-	jobObject.Labels["app"] = "openfero"
+// addJobAnnotations records which definition and alert a job was created for
+func addJobAnnotations(jobObject *batchv1.Job, definition string, alertname string) {
+	if jobObject.Annotations == nil {
+		jobObject.Annotations = make(map[string]string)
+	}
+	jobObject.Annotations[definitionAnnotation] = definition
+	jobObject.Annotations[alertnameAnnotation] = alertname
 }
 
 // function which saves the alert in the alertStore
-func (server *clientsetStruct) saveAlert(alert alert, status string) {
+func (server *clientsetStruct) saveAlert(alert alert, status string, skipReason string) {
 	log.Debug("Saving alert in alert store")
 	entry := alertStoreEntry{
-		Alert:     alert,
-		Status:    status,
-		Timestamp: time.Now(),
+		Alert:      alert,
+		Status:     status,
+		Timestamp:  time.Now(),
+		SkipReason: skipReason,
 	}
+	alertStoreMu.Lock()
+	defer alertStoreMu.Unlock()
 	if len(alertStore) < cap(alertStore) {
 		alertStore = append(alertStore, entry)
 	} else {
@@ -604,6 +998,53 @@ func (server *clientsetStruct) saveAlert(alert alert, status string) {
 	}
 }
 
+// markAlertApproval links the latest entry of the alert to the approval its job waits for
+func markAlertApproval(alert alert, status string, id string) {
+	alertStoreMu.Lock()
+	defer alertStoreMu.Unlock()
+	for i := len(alertStore) - 1; i >= 0; i-- {
+		stored := &alertStore[i]
+		if stored.Approval == "" && stored.SkipReason == "" && stored.Status == status &&
+			stored.Alert.Fingerprint == alert.Fingerprint && maps.Equal(stored.Alert.Labels, alert.Labels) {
+			stored.Approval = id
+			stored.SkipReason = approvalSkipReason(approval{ID: id, State: approvalPending})
+			return
+		}
+	}
+}
+
+// updateAlertApproval sets the skip reason of the alerts of the approval to its state
+func updateAlertApproval(entry approval) {
+	alertStoreMu.Lock()
+	defer alertStoreMu.Unlock()
+	for i := range alertStore {
+		if alertStore[i].Approval == entry.ID {
+			alertStore[i].SkipReason = approvalSkipReason(entry)
+		}
+	}
+}
+
+// approvalSkipReason tells why no job was created for the alert of an approval, approved jobs were created.
+// The alert store is public, so the reason does not name the user who rejected the job.
+func approvalSkipReason(entry approval) string {
+	switch entry.State {
+	case approvalPending:
+		return "pending approval " + entry.ID
+	case approvalRejected:
+		return "approval rejected"
+	case approvalExpired:
+		return "approval expired"
+	}
+	return ""
+}
+
+// storedAlerts returns a copy of the alert store
+func storedAlerts() []alertStoreEntry {
+	alertStoreMu.Lock()
+	defer alertStoreMu.Unlock()
+	return slices.Clone(alertStore)
+}
+
 // function which filters alerts based on the query
 func filterAlerts(alerts []alertStoreEntry, query string) []alertStoreEntry {
 	var filteredAlerts []alertStoreEntry
@@ -673,7 +1114,7 @@ func alertMatchesQuery(entry alertStoreEntry, query string) bool {
 // @Failure 400 {string} string "Bad Request"
 // @Router /assets/{path} [get]
 func assetsHandler(w http.ResponseWriter, r *http.Request) {
-	log.Debug("Called asset " + r.URL.Path)
+	log.Debug("Called asset", zap.String("path", r.URL.Path))
 	// set content type based on file extension
 	contentType := ""
 	switch filepath.Ext(r.URL.Path) {
@@ -691,7 +1132,7 @@ func assetsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Debug("Called asset " + r.URL.Path + " serves Filesystem asset: " + path)
+	log.Debug("Serving filesystem asset", zap.String("path", r.URL.Path), zap.String("file", path))
 	// serve assets from the web/assets directory
 	http.ServeFile(w, r, path)
 }
@@ -708,7 +1149,7 @@ func verifyPath(path string) (string, error) {
 		return "", errors.New(errmsg)
 	}
 	trustedRoot := filepath.Join(wd, "web")
-	log.Debug("Trusted root directory: " + trustedRoot)
+	log.Debug("Trusted root directory", zap.String("path", trustedRoot))
 
 	// Clean the path to remove any .. or . elements
 	cleanPath := filepath.Clean(path)
@@ -737,9 +1178,9 @@ func (server *clientsetStruct) alertStoreGetHandler(w http.ResponseWriter, r *ht
 	var alerts []alertStoreEntry
 
 	if query != "" {
-		alerts = filterAlerts(alertStore, query)
+		alerts = filterAlerts(storedAlerts(), query)
 	} else {
-		alerts = alertStore
+		alerts = storedAlerts()
 	}
 
 	w.Header().Set(contentType, applicationJSON)
@@ -825,6 +1266,9 @@ func (server *clientsetStruct) jobsUIHandler(w http.ResponseWriter, r *http.Requ
 
 		// Process each job definition in ConfigMap
 		for name, jobDef := range configMap.Data {
+			if name == definitionSettingsKey {
+				continue
+			}
 			// Parse YAML job definition
 			yamlJobDefinition := []byte(jobDef)
 			jsonBytes, err := yaml.YAMLToJSON(yamlJobDefinition)
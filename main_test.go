@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -8,11 +9,21 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	batchv1 "k8s.io/api/batch/v1"
 )
 
 func TestMain(m *testing.M) {
 	// Initialize logger before running tests
-	if err := initLogger("debug"); err != nil {
+	if err := initLogger(config.Logging{Level: "debug", Format: log.FormatConsole}); err != nil {
 		os.Exit(1)
 	}
 	os.Exit(m.Run())
@@ -112,6 +123,62 @@ func BenchmarkStringWithCharset(b *testing.B) {
 	}
 }
 
+func TestAddJobAnnotations(t *testing.T) {
+	jobObject := &batchv1.Job{}
+	jobObject.Annotations = map[string]string{"custom": "value"}
+
+	addJobAnnotations(jobObject, "openfero-kubequotaalmostfull-firing", "KubeQuotaAlmostFull")
+
+	expected := map[string]string{
+		"custom":             "value",
+		definitionAnnotation: "openfero-kubequotaalmostfull-firing",
+		alertnameAnnotation:  "KubeQuotaAlmostFull",
+	}
+	if !reflect.DeepEqual(jobObject.Annotations, expected) {
+		t.Errorf("addJobAnnotations() annotations = %v, want %v", jobObject.Annotations, expected)
+	}
+}
+
+func TestRenderJobAddsTraceContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	jobDefinition := `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: openfero-testalert-firing
+spec:
+  template:
+    spec:
+      containers:
+      - name: test
+        image: busybox
+`
+	ctx, span := tracing.Tracer().Start(context.Background(), "webhook")
+	jobObject, err := renderJob(ctx, jobDefinition, alertContext{alert: alert{Labels: map[string]string{"alertname": "TestAlert"}}}, "openfero-testalert-firing", "TestAlert", definitionSettings{}.withGlobal(config.Default()))
+	span.End()
+	if err != nil {
+		t.Fatalf("renderJob() returned error: %v", err)
+	}
+
+	traceparent := jobObject.Annotations[traceparentAnnotation]
+	if !strings.Contains(traceparent, span.SpanContext().TraceID().String()) {
+		t.Errorf("renderJob() traceparent annotation = %q, want trace ID %s", traceparent, span.SpanContext().TraceID())
+	}
+	env := jobObject.Spec.Template.Spec.Containers[0].Env
+	if env[len(env)-1].Name != "OPENFERO_TRACEPARENT" || env[len(env)-1].Value != traceparent {
+		t.Errorf("renderJob() did not add OPENFERO_TRACEPARENT env var, got %v", env)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 || spans[0].Name() != "job.render" || spans[0].Parent().SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("renderJob() recorded unexpected spans: %v", spans)
+	}
+}
+
 func TestSaveAlert(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -165,7 +232,7 @@ func TestSaveAlert(t *testing.T) {
 			alertStore = append(alertStore, tt.initialAlerts...)
 
 			server := &clientsetStruct{}
-			server.saveAlert(tt.newAlert, tt.newStatus)
+			server.saveAlert(tt.newAlert, tt.newStatus, "")
 
 			// Compare only the Alert and Status fields, ignore Timestamp
 			for i := range alertStore {
@@ -198,7 +265,7 @@ func BenchmarkSaveAlert(b *testing.B) {
 		copy(alertStore, initialAlerts)
 
 		server := &clientsetStruct{}
-		server.saveAlert(newAlert, newStatus)
+		server.saveAlert(newAlert, newStatus, "")
 	}
 }
 
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"go.uber.org/zap"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Sources of a global pause
+const (
+	pauseSourceConfig    = "config"
+	pauseSourceConfigMap = "configMap"
+)
+
+// @Description Global pause of the job creation
+type pauseStatus struct {
+	// @Description Whether no jobs are created
+	Paused bool `json:"paused"`
+	// @Description config if paused by maintenance.paused, configMap if paused by the annotation openfero/paused of the maintenance ConfigMap
+	Source string `json:"source,omitempty" enum:"config,configMap"`
+	// @Description Reason of the pause
+	Reason string `json:"reason,omitempty"`
+	// @Description User who paused OpenFero via the API
+	By string `json:"by,omitempty"`
+	// @Description Time when OpenFero was paused via the API
+	Since *time.Time `json:"since,omitempty"`
+}
+
+// @Description Request to pause or resume the job creation
+type pauseRequest struct {
+	// @Description true pauses, false resumes the job creation
+	Paused bool `json:"paused"`
+	// @Description Reason of the pause, shown on the skipped alerts
+	Reason string `json:"reason,omitempty"`
+}
+
+// maintenanceWindowStatus is a maintenance window and whether it covers the current time
+type maintenanceWindowStatus struct {
+	config.MaintenanceWindow
+	Active bool
+}
+
+// pauseStatus returns whether the configuration or the annotation of the maintenance ConfigMap pause OpenFero
+func (server *clientsetStruct) pauseStatus(cfg *config.Config) pauseStatus {
+	if cfg.Maintenance.Paused {
+		return pauseStatus{Paused: true, Source: pauseSourceConfig, Reason: "maintenance.paused is set"}
+	}
+	obj, exists, err := server.configMapStore.GetByKey(server.configmapNamespace + "/" + cfg.Maintenance.ConfigMap)
+	if err != nil || !exists {
+		return pauseStatus{}
+	}
+	return configMapPauseStatus(obj.(*v1.ConfigMap))
+}
+
+// configMapPauseStatus reads the pause from the annotations of the maintenance ConfigMap
+func configMapPauseStatus(configMap *v1.ConfigMap) pauseStatus {
+	if configMap.Annotations[pausedAnnotation] != "true" {
+		return pauseStatus{}
+	}
+	status := pauseStatus{
+		Paused: true,
+		Source: pauseSourceConfigMap,
+		Reason: configMap.Annotations[pauseReasonAnnotation],
+		By:     configMap.Annotations[pausedByAnnotation],
+	}
+	if since, err := time.Parse(time.RFC3339, configMap.Annotations[pausedAtAnnotation]); err == nil {
+		status.Since = &since
+	}
+	return status
+}
+
+// maintenanceSkipReason returns why no jobs are created for an alert with the labels and the reason of the
+// skipped jobs metric. Both are empty if jobs are created.
+func (server *clientsetStruct) maintenanceSkipReason(cfg *config.Config, labels map[string]string, now time.Time) (string, string) {
+	if pause := server.pauseStatus(cfg); pause.Paused {
+		if pause.Reason == "" {
+			return "OpenFero is paused", metadata.SkipReasonPaused
+		}
+		return "OpenFero is paused: " + pause.Reason, metadata.SkipReasonPaused
+	}
+	if window, ok := cfg.Maintenance.ActiveWindow(now, labels); ok {
+		return "maintenance window " + window.Name, metadata.SkipReasonMaintenance
+	}
+	return "", ""
+}
+
+// setPause pauses or resumes OpenFero by annotating the maintenance ConfigMap, which is created if missing.
+// The annotation is shared by all instances and kept across restarts.
+func (server *clientsetStruct) setPause(ctx context.Context, name string, request pauseRequest, user string) (pauseStatus, error) {
+	configMaps := server.clientset.CoreV1().ConfigMaps(server.configmapNamespace)
+	configMap, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+	notFound := apierrors.IsNotFound(err)
+	if err != nil && !notFound {
+		return pauseStatus{}, err
+	}
+	if notFound {
+		configMap = &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: server.configmapNamespace}}
+	}
+	if configMap.Annotations == nil {
+		configMap.Annotations = make(map[string]string)
+	}
+	for _, annotation := range []string{pausedAnnotation, pauseReasonAnnotation, pausedByAnnotation, pausedAtAnnotation} {
+		delete(configMap.Annotations, annotation)
+	}
+	if request.Paused {
+		configMap.Annotations[pausedAnnotation] = "true"
+		configMap.Annotations[pauseReasonAnnotation] = request.Reason
+		configMap.Annotations[pausedByAnnotation] = user
+		configMap.Annotations[pausedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	if notFound {
+		configMap, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+	} else {
+		configMap, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return pauseStatus{}, err
+	}
+	return configMapPauseStatus(configMap), nil
+}
+
+// @Summary Get the global pause
+// @Description Get whether the creation of all jobs is paused and why
+// @Tags maintenance
+// @Produce json
+// @Success 200 {object} pauseStatus
+// @Router /api/pause [get]
+func (server *clientsetStruct) pauseGetHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, server.pauseStatus(server.currentConfig()))
+}
+
+// @Summary Pause or resume
+// @Description Pause or resume the creation of all jobs. Alerts are still recorded while paused.
+// @Description The pause is stored as annotation on the maintenance ConfigMap, so it applies to all instances.
+// @Tags maintenance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body pauseRequest true "Pause or resume"
+// @Success 200 {object} pauseStatus
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 409 {string} string "Paused by the configuration"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /api/pause [put]
+func (server *clientsetStruct) pausePutHandler(w http.ResponseWriter, r *http.Request) {
+	request := pauseRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	cfg := server.currentConfig()
+	if !request.Paused && cfg.Maintenance.Paused {
+		http.Error(w, "paused by maintenance.paused of the configuration", http.StatusConflict)
+		return
+	}
+
+	user := userFromContext(r.Context())
+	logger := log.FromContext(r.Context()).With(zap.String("user", user))
+	status, err := server.setPause(r.Context(), cfg.Maintenance.ConfigMap, request, user)
+	if err != nil {
+		logger.Error("error setting pause", zap.String("error", err.Error()))
+		http.Error(w, fmt.Sprintf("error setting pause: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if request.Paused {
+		logger.Warn("Job creation paused", zap.String("reason", request.Reason))
+	} else {
+		logger.Warn("Job creation resumed")
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// @Summary Get maintenance UI page
+// @Description Get the page showing the global pause with a toggle and the maintenance windows
+// @Tags ui
+// @Produce html
+// @Success 200 {string} string "HTML page"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /ui/maintenance [get]
+func (server *clientsetStruct) maintenanceUIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(contentType, "text/html")
+
+	tmpl, err := template.ParseFiles(
+		"web/templates/maintenance.html.templ",
+		"web/templates/navbar.html.templ",
+	)
+	if err != nil {
+		log.Error("error parsing template", zap.String("error", err.Error()))
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	cfg := server.currentConfig()
+	now := time.Now()
+	windows := make([]maintenanceWindowStatus, 0, len(cfg.Maintenance.Windows))
+	for _, window := range cfg.Maintenance.Windows {
+		windows = append(windows, maintenanceWindowStatus{MaintenanceWindow: window, Active: window.Active(now)})
+	}
+	data := struct {
+		Title      string
+		ShowSearch bool
+		Pause      pauseStatus
+		Windows    []maintenanceWindowStatus
+	}{
+		Title:      "Maintenance",
+		ShowSearch: false,
+		Pause:      server.pauseStatus(cfg),
+		Windows:    windows,
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		log.Error("error executing template", zap.String("error", err.Error()))
+		http.Error(w, "", http.StatusInternalServerError)
+	}
+}
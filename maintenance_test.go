@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestMaintenanceSkipReason(t *testing.T) {
+	now := time.Now()
+	start, end := now.Add(-time.Hour), now.Add(time.Hour)
+	window := config.MaintenanceWindow{Name: "upgrade", Start: &start, End: &end, Matchers: map[string]string{"cluster": "prod"}}
+	pausedConfigMap := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:        "openfero-maintenance",
+		Namespace:   "default",
+		Annotations: map[string]string{pausedAnnotation: "true", pauseReasonAnnotation: "incident"},
+	}}
+	tests := []struct {
+		name        string
+		maintenance config.Maintenance
+		configMap   *v1.ConfigMap
+		labels      map[string]string
+		want        string
+	}{
+		{name: "Not paused", maintenance: config.Maintenance{ConfigMap: "openfero-maintenance"}},
+		{name: "Paused by configuration", maintenance: config.Maintenance{Paused: true}, want: "OpenFero is paused: maintenance.paused is set"},
+		{name: "Paused by annotation", maintenance: config.Maintenance{ConfigMap: "openfero-maintenance"}, configMap: pausedConfigMap, want: "OpenFero is paused: incident"},
+		{name: "Annotation of other ConfigMap", maintenance: config.Maintenance{ConfigMap: "openfero-control"}, configMap: pausedConfigMap},
+		{name: "Maintenance window", maintenance: config.Maintenance{Windows: []config.MaintenanceWindow{window}}, labels: map[string]string{"cluster": "prod"}, want: "maintenance window upgrade"},
+		{name: "Maintenance window of other alerts", maintenance: config.Maintenance{Windows: []config.MaintenanceWindow{window}}, labels: map[string]string{"cluster": "staging"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+			if tt.configMap != nil {
+				if err := store.Add(tt.configMap); err != nil {
+					t.Fatal(err)
+				}
+			}
+			server := &clientsetStruct{configmapNamespace: "default", configMapStore: store}
+			cfg := config.Default()
+			cfg.Maintenance = tt.maintenance
+			if got, _ := server.maintenanceSkipReason(cfg, tt.labels, now); got != tt.want {
+				t.Errorf("maintenanceSkipReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPauseHandlers(t *testing.T) {
+	clientset := fake.NewClientset()
+	configMapStore := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	server := &clientsetStruct{
+		clientset:          clientset,
+		configmapNamespace: "default",
+		configMapStore:     configMapStore,
+		auth:               newAPIAuthenticator(map[string]string{"secret": "alice"}),
+	}
+	server.config.Store(config.Default())
+
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/pause", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		recorder := httptest.NewRecorder()
+		server.auth.requireAuth(server.pausePutHandler)(recorder, req)
+		return recorder
+	}
+	// syncStore stands in for the ConfigMap informer
+	syncStore := func() {
+		configMap, err := clientset.CoreV1().ConfigMaps("default").Get(context.Background(), "openfero-maintenance", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := configMapStore.Update(configMap); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recorder := put(`{"paused": true, "reason": "incident INC-42"}`)
+	status := pauseStatus{}
+	if err := json.NewDecoder(recorder.Body).Decode(&status); err != nil || recorder.Code != http.StatusOK {
+		t.Fatalf("PUT /api/pause returned %d, error %v", recorder.Code, err)
+	}
+	if !status.Paused || status.By != "alice" || status.Reason != "incident INC-42" || status.Since == nil || status.Source != pauseSourceConfigMap {
+		t.Errorf("unexpected pause: %+v", status)
+	}
+	syncStore()
+	if !server.pauseStatus(server.currentConfig()).Paused {
+		t.Error("pauseStatus() is not paused after the annotation was set")
+	}
+
+	if recorder := put(`{"paused": false}`); recorder.Code != http.StatusOK {
+		t.Fatalf("PUT /api/pause returned %d: %s", recorder.Code, recorder.Body.String())
+	}
+	syncStore()
+	if server.pauseStatus(server.currentConfig()).Paused {
+		t.Error("pauseStatus() is paused after resume")
+	}
+
+	if recorder := put(`paused`); recorder.Code != http.StatusBadRequest {
+		t.Errorf("PUT /api/pause with invalid body returned %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+	cfg := config.Default()
+	cfg.Maintenance.Paused = true
+	server.config.Store(cfg)
+	if recorder := put(`{"paused": false}`); recorder.Code != http.StatusConflict {
+		t.Errorf("resuming a pause of the configuration returned %d, want %d", recorder.Code, http.StatusConflict)
+	}
+
+	recorder = httptest.NewRecorder()
+	server.maintenanceUIHandler(recorder, httptest.NewRequest(http.MethodGet, "/ui/maintenance", nil))
+	if !strings.Contains(recorder.Body.String(), "maintenance.paused") {
+		t.Errorf("maintenance page does not show the pause:\n%s", recorder.Body.String())
+	}
+}
+
+func TestCreateResponseJobDuringMaintenance(t *testing.T) {
+	alertStore = make([]alertStoreEntry, 0, 10)
+	configMapStore := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	if err := configMapStore.Add(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "openfero-kubequotaalmostfull-firing", Namespace: "default"},
+		Data:       map[string]string{"KubeQuotaAlmostFull": "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: quota\n"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	clientset := fake.NewClientset()
+	server := &clientsetStruct{
+		clientset:               clientset,
+		configmapNamespace:      "default",
+		jobDestinationNamespace: "default",
+		configMapStore:          configMapStore,
+		jobStore:                cache.NewStore(cache.MetaNamespaceKeyFunc),
+	}
+	start, end := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	cfg := config.Default()
+	cfg.Maintenance.Windows = []config.MaintenanceWindow{{Name: "upgrade", Start: &start, End: &end}}
+	server.config.Store(cfg)
+
+	jsonFile, err := os.Open("test/alerts.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer jsonFile.Close()
+	message := hookMessage{}
+	if err := json.NewDecoder(jsonFile).Decode(&message); err != nil {
+		t.Fatal(err)
+	}
+	skipped := func(reason string) float64 {
+		return testutil.ToFloat64(metadata.JobsSkippedTotal.WithLabelValues("KubeQuotaAlmostFull", "openfero-kubequotaalmostfull-firing", reason))
+	}
+	maintenanceBefore, pausedBefore := skipped(metadata.SkipReasonMaintenance), skipped(metadata.SkipReasonPaused)
+
+	server.createResponseJob(context.Background(), &message, 0, "firing")
+	paused := config.Default()
+	paused.Maintenance.Paused = true
+	server.config.Store(paused)
+	server.createResponseJob(context.Background(), &message, 0, "firing")
+
+	jobs, err := clientset.BatchV1().Jobs("default").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs.Items) != 0 {
+		t.Errorf("created %d jobs during maintenance, want 0", len(jobs.Items))
+	}
+	if len(alertStore) != 2 || alertStore[0].SkipReason != "maintenance window upgrade" {
+		t.Errorf("alert store = %+v, want the alert with the maintenance window as skip reason", alertStore)
+	}
+	if got := skipped(metadata.SkipReasonMaintenance) - maintenanceBefore; got != 1 {
+		t.Errorf("skipped counter for maintenance increased by %v, want 1", got)
+	}
+	if got := skipped(metadata.SkipReasonPaused) - pausedBefore; got != 1 {
+		t.Errorf("skipped counter for the pause increased by %v, want 1", got)
+	}
+}
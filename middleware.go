@@ -0,0 +1,39 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"go.uber.org/zap"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// validRequestID restricts request IDs passed by clients to values which are safe to log
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// requestIDMiddleware assigns every request an ID, which is returned to the client
+// and attached to the request context so all log lines of the request carry it
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+		ctx := log.WithFields(r.Context(), zap.String(log.RequestIDKey, requestID))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// newRequestID returns a random 16 character hex string
+func newRequestID() string {
+	randombytes := make([]byte, 8)
+	if _, err := rand.Read(randombytes); err != nil {
+		return stringWithCharset(16, charset)
+	}
+	return hex.EncodeToString(randombytes)
+}
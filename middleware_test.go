@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		requestID  string
+		expectSame bool
+	}{
+		{
+			name:       "Valid request ID is kept",
+			requestID:  "alertmanager-1234",
+			expectSame: true,
+		},
+		{
+			name:       "Missing request ID is generated",
+			requestID:  "",
+			expectSame: false,
+		},
+		{
+			name:       "Request ID with newlines is replaced",
+			requestID:  "abc\ndef",
+			expectSame: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			req := httptest.NewRequest("GET", "/healthz", nil)
+			if tt.requestID != "" {
+				req.Header.Set(requestIDHeader, tt.requestID)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			got := rr.Header().Get(requestIDHeader)
+			if tt.expectSame && got != tt.requestID {
+				t.Errorf("request ID = %q, want %q", got, tt.requestID)
+			}
+			if !tt.expectSame && (got == tt.requestID || !validRequestID.MatchString(got)) {
+				t.Errorf("request ID = %q, want a newly generated ID", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"github.com/OpenFero/openfero/pkg/notify"
+	"go.uber.org/zap"
+)
+
+// notificationTimeout limits the delivery of a single notification
+const notificationTimeout = 30 * time.Second
+
+// jobNotifier sends notifications about finished jobs to the receivers of all matching routes.
+// Receivers and routes are read on every call, so they can be changed without restart.
+type jobNotifier struct {
+	config func() *config.Config
+	sender *notify.Sender
+}
+
+func newJobNotifier(config func() *config.Config) *jobNotifier {
+	return &jobNotifier{
+		config: config,
+		sender: notify.New(&http.Client{Timeout: notificationTimeout}),
+	}
+}
+
+// jobCreated does nothing, notifications are only sent for finished jobs
+func (notifier *jobNotifier) jobCreated(context.Context, *jobRun) {}
+
+// jobFinished sends the notifications in the background, so the job informer is not blocked
+func (notifier *jobNotifier) jobFinished(run *jobRun, outcome jobOutcome, reason string) {
+	cfg := notifier.config()
+	routes := cfg.Notifications.Routes
+	if run.settings != nil {
+		routes = run.settings.Notifications
+	}
+	event := notificationEvent(run, outcome, reason)
+	receivers := routeReceivers(routes, event.Labels, string(outcome))
+	if len(receivers) == 0 {
+		return
+	}
+
+	logger := log.Subsystem(log.SubsystemJobs).With(zap.String(log.JobKey, run.job.Name))
+	for _, name := range receivers {
+		receiver, ok := cfg.Notifications.Receivers[name]
+		if !ok {
+			logger.Warn("Notification route refers to an unknown receiver", zap.String("receiver", name))
+			metadata.NotificationsTotal.WithLabelValues(name, "failed").Inc()
+			continue
+		}
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+			defer cancel()
+			if err := notifier.sender.Send(ctx, receiver, event); err != nil {
+				logger.Error("error sending notification", zap.String("receiver", name), zap.String("error", err.Error()))
+				metadata.NotificationsTotal.WithLabelValues(name, "failed").Inc()
+				return
+			}
+			logger.Debug("Notification sent", zap.String("receiver", name))
+			metadata.NotificationsTotal.WithLabelValues(name, "sent").Inc()
+		}()
+	}
+}
+
+// routeReceivers returns the receivers of all routes matching the labels and the outcome, every receiver once
+func routeReceivers(routes []config.Route, labels map[string]string, outcome string) []string {
+	var receivers []string
+	for _, route := range routes {
+		if !route.Matches(labels, outcome) {
+			continue
+		}
+		for _, receiver := range route.Receivers {
+			if !slices.Contains(receivers, receiver) {
+				receivers = append(receivers, receiver)
+			}
+		}
+	}
+	return receivers
+}
+
+// notificationEvent describes the finished job for the templates. The labels are those of the alert,
+// the common labels of the group for jobs per group, and hold at least the alertname of the job.
+func notificationEvent(run *jobRun, outcome jobOutcome, reason string) notify.Event {
+	job := run.job
+	labels := map[string]string{}
+	annotations := map[string]string{}
+	if alertname := job.Annotations[alertnameAnnotation]; alertname != "" {
+		labels["alertname"] = alertname
+	}
+	if run.data.perGroup() {
+		if run.data.group != nil {
+			maps.Copy(labels, run.data.group.CommonLabels)
+			maps.Copy(annotations, run.data.group.CommonAnnotations)
+		}
+	} else {
+		maps.Copy(labels, run.data.alert.Labels)
+		maps.Copy(annotations, run.data.alert.Annotations)
+	}
+
+	return notify.Event{
+		Job:         job.Name,
+		Namespace:   job.Namespace,
+		Definition:  job.Annotations[definitionAnnotation],
+		Alertname:   labels["alertname"],
+		GroupKey:    job.Annotations[groupKeyAnnotation],
+		Outcome:     string(outcome),
+		Reason:      reason,
+		Labels:      labels,
+		Annotations: annotations,
+		Outputs:     run.outputs,
+	}
+}
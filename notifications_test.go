@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	"github.com/OpenFero/openfero/pkg/notify"
+
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func TestRouteReceivers(t *testing.T) {
+	routes := []config.Route{
+		{Receivers: []string{"sre-slack"}},
+		{Receivers: []string{"shop-teams", "sre-slack"}, Matchers: map[string]string{"namespace": "shop"}},
+		{Receivers: []string{"oncall-email"}, Outcomes: []string{config.OutcomeFailed}},
+	}
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		outcome jobOutcome
+		want    []string
+	}{
+		{name: "Succeeded in other namespace", labels: map[string]string{"namespace": "db"}, outcome: jobOutcomeSucceeded, want: []string{"sre-slack"}},
+		{name: "Succeeded in matching namespace", labels: map[string]string{"namespace": "shop"}, outcome: jobOutcomeSucceeded, want: []string{"sre-slack", "shop-teams"}},
+		{name: "Failed in matching namespace", labels: map[string]string{"namespace": "shop"}, outcome: jobOutcomeFailed, want: []string{"sre-slack", "shop-teams", "oncall-email"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := routeReceivers(routes, tt.labels, string(tt.outcome)); !slices.Equal(got, tt.want) {
+				t.Errorf("routeReceivers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobNotifier(t *testing.T) {
+	type notification struct {
+		receiver string
+		event    notify.Event
+	}
+	received := make(chan notification, 2)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := notify.Event{}
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("invalid JSON: %v", err)
+		}
+		received <- notification{receiver: r.URL.Path, event: event}
+	}))
+	defer webhook.Close()
+
+	cfg := config.Default()
+	cfg.Notifications = config.Notifications{
+		Receivers: map[string]config.Receiver{
+			"global":     {Type: config.ReceiverWebhook, URL: webhook.URL + "/global"},
+			"definition": {Type: config.ReceiverWebhook, URL: webhook.URL + "/definition"},
+		},
+		Routes: []config.Route{{Receivers: []string{"global"}}},
+	}
+	settings := definitionSettings{Notifications: []config.Route{
+		{Receivers: []string{"definition"}, Matchers: map[string]string{"namespace": "shop"}, Outcomes: []string{config.OutcomeFailed}},
+	}}.withGlobal(cfg)
+
+	lifecycle := newJobLifecycle(newJobNotifier(func() *config.Config { return cfg }))
+	tracker := newJobOutcomeTracker(lifecycle)
+
+	// The job informer reports the failure of a job created for an alert in the namespace shop
+	job := finishedJob("uid-1", batchv1.JobFailed, batchv1.JobReasonBackoffLimitExceeded)
+	data := alertContext{alert: alert{
+		Labels:      map[string]string{"alertname": "TestAlert", "namespace": "shop"},
+		Annotations: map[string]string{"summary": "Quota almost full"},
+	}}
+	lifecycle.created(context.Background(), &jobRun{job: job, data: data, settings: &settings})
+	tracker.observe(job, false)
+
+	var got notification
+	select {
+	case got = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification was sent")
+	}
+	event := got.event
+	if got.receiver != "/definition" {
+		t.Errorf("notification sent to %s, want the receiver of the definition", got.receiver)
+	}
+	if event.Job != job.Name || event.Outcome != "failed" || event.Reason != batchv1.JobReasonBackoffLimitExceeded {
+		t.Errorf("unexpected event: %+v", event)
+	}
+	if event.Labels["namespace"] != "shop" || event.Annotations["summary"] != "Quota almost full" {
+		t.Errorf("unexpected labels or annotations: %+v", event)
+	}
+	select {
+	case got := <-received:
+		t.Errorf("unexpected second notification to %s", got.receiver)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Jobs without run, e.g. created before a restart, are routed by the global routes
+	tracker.observe(finishedJob("uid-2", batchv1.JobComplete, ""), false)
+	select {
+	case got = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification was sent for the job without run")
+	}
+	if got.receiver != "/global" || got.event.Outcome != "succeeded" || got.event.Alertname != "TestAlert" {
+		t.Errorf("unexpected notification to %s: %+v", got.receiver, got.event)
+	}
+}
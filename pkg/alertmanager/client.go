@@ -0,0 +1,131 @@
+// Package alertmanager is a minimal client of the Alertmanager API v2
+package alertmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxErrorBody limits how much of an error response is included in the returned error
+const maxErrorBody = 512
+
+// Client sends requests to the API v2 of a single Alertmanager
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// New returns a client of the Alertmanager at baseURL, e.g. http://alertmanager:9093
+func New(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{baseURL: strings.TrimSuffix(baseURL, "/"), httpClient: httpClient}
+}
+
+// Matcher selects alerts by a label
+type Matcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+// Silence mutes all alerts matching its matchers between StartsAt and EndsAt
+type Silence struct {
+	Matchers  []Matcher `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+}
+
+// Alert is an alert posted to Alertmanager
+type Alert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// MatchersFromLabels returns equality matchers for all labels, sorted by name
+func MatchersFromLabels(labels map[string]string) []Matcher {
+	matchers := make([]Matcher, 0, len(labels))
+	for name, value := range labels {
+		matchers = append(matchers, Matcher{Name: name, Value: value, IsEqual: true})
+	}
+	sort.Slice(matchers, func(i, j int) bool {
+		return matchers[i].Name < matchers[j].Name
+	})
+	return matchers
+}
+
+// CreateSilence creates the silence and returns its ID
+func (client *Client) CreateSilence(ctx context.Context, silence Silence) (string, error) {
+	response := struct {
+		SilenceID string `json:"silenceID"`
+	}{}
+	if err := client.do(ctx, http.MethodPost, "/api/v2/silences", silence, &response); err != nil {
+		return "", fmt.Errorf("error creating silence: %w", err)
+	}
+	return response.SilenceID, nil
+}
+
+// ExpireSilence ends the silence immediately
+func (client *Client) ExpireSilence(ctx context.Context, id string) error {
+	if err := client.do(ctx, http.MethodDelete, "/api/v2/silence/"+url.PathEscape(id), nil, nil); err != nil {
+		return fmt.Errorf("error expiring silence %s: %w", id, err)
+	}
+	return nil
+}
+
+// PostAlerts sends the alerts to Alertmanager
+func (client *Client) PostAlerts(ctx context.Context, alerts ...Alert) error {
+	if err := client.do(ctx, http.MethodPost, "/api/v2/alerts", alerts, nil); err != nil {
+		return fmt.Errorf("error posting alerts: %w", err)
+	}
+	return nil
+}
+
+// do sends body as JSON and decodes the JSON response into result, if given
+func (client *Client) do(ctx context.Context, method string, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, client.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := client.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBody))
+		return fmt.Errorf("alertmanager returned %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
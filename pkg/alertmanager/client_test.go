@@ -0,0 +1,138 @@
+package alertmanager
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchersFromLabels(t *testing.T) {
+	matchers := MatchersFromLabels(map[string]string{"namespace": "shop", "alertname": "KubeQuotaAlmostFull"})
+	want := []Matcher{
+		{Name: "alertname", Value: "KubeQuotaAlmostFull", IsEqual: true},
+		{Name: "namespace", Value: "shop", IsEqual: true},
+	}
+	if len(matchers) != len(want) {
+		t.Fatalf("MatchersFromLabels() = %+v, want %+v", matchers, want)
+	}
+	for i := range want {
+		if matchers[i] != want[i] {
+			t.Errorf("matcher %d = %+v, want %+v", i, matchers[i], want[i])
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	type request struct {
+		method string
+		path   string
+		body   string
+	}
+	tests := []struct {
+		name     string
+		call     func(client *Client) error
+		status   int
+		response string
+		want     request
+		wantErr  string
+	}{
+		{
+			name: "Create silence",
+			call: func(client *Client) error {
+				id, err := client.CreateSilence(context.Background(), Silence{
+					Matchers:  MatchersFromLabels(map[string]string{"alertname": "Test"}),
+					StartsAt:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+					EndsAt:    time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC),
+					CreatedBy: "openfero",
+				})
+				if err == nil && id != "abc" {
+					t.Errorf("CreateSilence() = %q, want abc", id)
+				}
+				return err
+			},
+			status:   http.StatusOK,
+			response: `{"silenceID":"abc"}`,
+			want: request{
+				method: http.MethodPost,
+				path:   "/api/v2/silences",
+				body:   `{"matchers":[{"name":"alertname","value":"Test","isRegex":false,"isEqual":true}],"startsAt":"2026-01-01T00:00:00Z","endsAt":"2026-01-01T01:00:00Z","createdBy":"openfero","comment":""}`,
+			},
+		},
+		{
+			name:   "Expire silence",
+			call:   func(client *Client) error { return client.ExpireSilence(context.Background(), "abc") },
+			status: http.StatusOK,
+			want:   request{method: http.MethodDelete, path: "/api/v2/silence/abc"},
+		},
+		{
+			name: "Post alerts",
+			call: func(client *Client) error {
+				return client.PostAlerts(context.Background(), Alert{
+					Labels:   map[string]string{"alertname": "Test"},
+					StartsAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+					EndsAt:   time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC),
+				})
+			},
+			status: http.StatusOK,
+			want: request{
+				method: http.MethodPost,
+				path:   "/api/v2/alerts",
+				body:   `[{"labels":{"alertname":"Test"},"startsAt":"2026-01-01T00:00:00Z","endsAt":"2026-01-01T01:00:00Z"}]`,
+			},
+		},
+		{
+			name:     "Error response",
+			call:     func(client *Client) error { return client.ExpireSilence(context.Background(), "unknown") },
+			status:   http.StatusNotFound,
+			response: "silence not found\n",
+			want:     request{method: http.MethodDelete, path: "/api/v2/silence/unknown"},
+			wantErr:  "404 Not Found: silence not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got request
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				got = request{method: r.Method, path: r.URL.Path, body: string(body)}
+				w.WriteHeader(tt.status)
+				_, _ = io.WriteString(w, tt.response)
+			}))
+			defer server.Close()
+
+			err := tt.call(New(server.URL+"/", nil))
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if got.method != tt.want.method || got.path != tt.want.path {
+				t.Errorf("request = %s %s, want %s %s", got.method, got.path, tt.want.method, tt.want.path)
+			}
+			if tt.want.body != "" && !jsonEqual(t, got.body, tt.want.body) {
+				t.Errorf("body = %s, want %s", got.body, tt.want.body)
+			}
+		})
+	}
+}
+
+func jsonEqual(t *testing.T, a string, b string) bool {
+	t.Helper()
+	var x, y interface{}
+	if err := json.Unmarshal([]byte(a), &x); err != nil {
+		t.Fatalf("invalid JSON %s: %v", a, err)
+	}
+	if err := json.Unmarshal([]byte(b), &y); err != nil {
+		t.Fatalf("invalid JSON %s: %v", b, err)
+	}
+	xs, _ := json.Marshal(x)
+	ys, _ := json.Marshal(y)
+	return string(xs) == string(ys)
+}
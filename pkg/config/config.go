@@ -0,0 +1,808 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/tracing"
+	"github.com/ghodss/yaml"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+)
+
+// EnvPrefix is the prefix of all environment variables overriding the configuration file
+const EnvPrefix = "OPENFERO_"
+
+// apiTokenEnv holds a single token of the user admin, it is added to the tokens of the configuration file
+const apiTokenEnv = "OPENFERO_API_TOKEN"
+
+const apiTokenUser = "admin"
+
+// Config is the configuration of OpenFero
+type Config struct {
+	Server        Server        `json:"server"`
+	Auth          Auth          `json:"auth"`
+	Store         Store         `json:"store"`
+	Policy        Policy        `json:"policy"`
+	Namespaces    Namespaces    `json:"namespaces"`
+	Defaults      Defaults      `json:"defaults"`
+	Injection     Injection     `json:"injection"`
+	Hooks         Hooks         `json:"hooks"`
+	CloudEvents   CloudEvents   `json:"cloudEvents"`
+	Events        Events        `json:"events"`
+	Alertmanager  Alertmanager  `json:"alertmanager"`
+	Notifications Notifications `json:"notifications"`
+	Approvals     Approvals     `json:"approvals"`
+	Maintenance   Maintenance   `json:"maintenance"`
+	Scheduler     Scheduler     `json:"scheduler"`
+	RateLimit     RateLimit     `json:"rateLimit"`
+	Limits        Limits        `json:"limits"`
+	Webhook       Webhook       `json:"webhook"`
+	Logging       Logging       `json:"logging"`
+	Tracing       Tracing       `json:"tracing"`
+}
+
+// Server configures the HTTP server
+type Server struct {
+	// Addr is the address to listen for webhooks
+	Addr         string   `json:"addr"`
+	ReadTimeout  Duration `json:"readTimeout"`
+	WriteTimeout Duration `json:"writeTimeout"`
+}
+
+// Limits restrict the webhook payloads, larger payloads are rejected
+type Limits struct {
+	// MaxBodyBytes is the size of the largest accepted request body
+	MaxBodyBytes int64 `json:"maxBodyBytes"`
+	// MaxAlerts is the number of alerts of a single notification
+	MaxAlerts int `json:"maxAlerts"`
+	// MaxLabels is the number of labels of a single alert
+	MaxLabels int `json:"maxLabels"`
+	// MaxLabelLength is the length of the names and values of labels
+	MaxLabelLength int `json:"maxLabelLength"`
+}
+
+// Webhook configures the responses to webhooks
+type Webhook struct {
+	// Synchronous creates the jobs before answering a webhook, so the response holds their results.
+	// Senders can override it per request with the query parameter sync.
+	Synchronous bool `json:"synchronous"`
+	// SyncTimeout is the longest time a synchronous response waits for the jobs, alerts still in progress are
+	// reported as queued
+	SyncTimeout Duration `json:"syncTimeout"`
+}
+
+// Auth configures the authentication of the administrative API
+type Auth struct {
+	Tokens []Token `json:"tokens"`
+}
+
+// Token is a bearer token of the administrative API and the user it identifies
+type Token struct {
+	User  string `json:"user"`
+	Token string `json:"token"`
+}
+
+// Store configures the in-memory stores
+type Store struct {
+	// AlertStoreSize is the number of alerts kept in memory. It also limits the history of approvals, workflow runs
+	// and job runs, each of which keeps that many entries.
+	AlertStoreSize int `json:"alertStoreSize"`
+}
+
+// Policy decides which alerts result in jobs
+type Policy struct {
+	// DisabledAlerts are alertnames for which no jobs are created
+	DisabledAlerts []string `json:"disabledAlerts"`
+}
+
+// Namespaces configures where definitions are read and jobs are created
+type Namespaces struct {
+	// Configmap is the namespace of the job definitions, defaults to the current namespace
+	Configmap string `json:"configmap"`
+	// JobDestination is the namespace of the created jobs, defaults to the current namespace
+	JobDestination string `json:"jobDestination"`
+}
+
+// Event types of Kubernetes Events
+const (
+	EventTypeNormal  = "Normal"
+	EventTypeWarning = "Warning"
+)
+
+// Events configures jobs triggered by Kubernetes Events
+type Events struct {
+	// Enabled starts an informer on Kubernetes Events, every new event becomes an alert named like its reason
+	Enabled bool `json:"enabled"`
+	// Namespace of the watched events, all namespaces if empty
+	Namespace string `json:"namespace"`
+	// Types of the events which result in alerts, Warning if empty
+	Types []string `json:"types"`
+	// Reasons of the events which result in alerts, e.g. FailedMount or BackOff, all reasons if empty
+	Reasons []string `json:"reasons"`
+}
+
+// Alertmanager configures the write back of job results to the Alertmanager API v2
+type Alertmanager struct {
+	// URL of Alertmanager, e.g. http://alertmanager:9093, nothing is written back if empty
+	URL string `json:"url"`
+	// SilenceDuration limits the silence of jobs without activeDeadlineSeconds
+	SilenceDuration Duration `json:"silenceDuration"`
+	// FailureAlertDuration is how long the alert of a failed job fires
+	FailureAlertDuration Duration `json:"failureAlertDuration"`
+	// WriteBack is the default of all definitions
+	WriteBack WriteBack `json:"writeBack"`
+}
+
+// WriteBack selects what is written back to Alertmanager for the jobs of a definition
+type WriteBack struct {
+	// Silence silences the alert while its job runs
+	Silence *bool `json:"silence,omitempty"`
+	// FailureAlert fires the alert OpenFeroRemediationFailed when the job fails
+	FailureAlert *bool `json:"failureAlert,omitempty"`
+}
+
+// Merge returns the write back settings with all settings of override applied on top
+func (writeBack WriteBack) Merge(override WriteBack) WriteBack {
+	merged := writeBack
+	if override.Silence != nil {
+		merged.Silence = override.Silence
+	}
+	if override.FailureAlert != nil {
+		merged.FailureAlert = override.FailureAlert
+	}
+	return merged
+}
+
+// Approvals configures the jobs of definitions which require approval
+type Approvals struct {
+	// Timeout after which a pending job is dropped, definitions can override it with approvalTimeout
+	Timeout Duration `json:"timeout"`
+}
+
+// Scheduler configures the scheduler of definitions with a schedule
+type Scheduler struct {
+	// LeaderElection runs the scheduled jobs only in the instance holding the lease, without it every instance runs them
+	LeaderElection bool `json:"leaderElection"`
+	// Lease is the name of the Lease in the namespace of the definitions used for the leader election
+	Lease string `json:"lease"`
+}
+
+// Priorities of jobs, jobs of a higher priority are created first when the creation is rate limited
+const (
+	PriorityHigh   = "high"
+	PriorityNormal = "normal"
+	PriorityLow    = "low"
+)
+
+// RateLimit limits the creation of jobs, so alert storms do not overload the API server
+type RateLimit struct {
+	// JobsPerSecond is the rate of job creations, 0 disables the limit
+	JobsPerSecond float64 `json:"jobsPerSecond"`
+	// Burst is the number of jobs created at once before the rate applies
+	Burst int `json:"burst"`
+	// MaxBacklog is the number of jobs waiting for their creation above which jobs of low and normal priority are shed,
+	// 0 never sheds
+	MaxBacklog int `json:"maxBacklog"`
+	// Priorities map the severity label of alerts to priorities, alerts with other severities get normal priority
+	Priorities map[string]string `json:"priorities"`
+}
+
+// Priority returns the priority of alerts with the labels
+func (rateLimit RateLimit) Priority(labels map[string]string) string {
+	if priority, ok := rateLimit.Priorities[labels["severity"]]; ok {
+		return priority
+	}
+	return PriorityNormal
+}
+
+// ValidPriority returns if priority is high, normal or low
+func ValidPriority(priority string) bool {
+	return priority == PriorityHigh || priority == PriorityNormal || priority == PriorityLow
+}
+
+// Defaults are applied to jobs which do not set the value themselves
+type Defaults struct {
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+	ActiveDeadlineSeconds   *int64 `json:"activeDeadlineSeconds,omitempty"`
+	BackoffLimit            *int32 `json:"backoffLimit,omitempty"`
+	// Labels and Annotations are added to every job, values of the job definition take precedence
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Resources are added to containers which do not request or limit a resource themselves
+	Resources          *v1.ResourceRequirements `json:"resources,omitempty"`
+	ServiceAccountName string                   `json:"serviceAccountName,omitempty"`
+}
+
+// Merge returns the defaults with all settings of override applied on top
+func (defaults Defaults) Merge(override Defaults) Defaults {
+	merged := defaults
+	if override.TTLSecondsAfterFinished != nil {
+		merged.TTLSecondsAfterFinished = override.TTLSecondsAfterFinished
+	}
+	if override.ActiveDeadlineSeconds != nil {
+		merged.ActiveDeadlineSeconds = override.ActiveDeadlineSeconds
+	}
+	if override.BackoffLimit != nil {
+		merged.BackoffLimit = override.BackoffLimit
+	}
+	merged.Labels = mergeMaps(defaults.Labels, override.Labels)
+	merged.Annotations = mergeMaps(defaults.Annotations, override.Annotations)
+	if override.Resources != nil {
+		merged.Resources = override.Resources
+	}
+	if override.ServiceAccountName != "" {
+		merged.ServiceAccountName = override.ServiceAccountName
+	}
+	return merged
+}
+
+// Validate checks the defaults, path is the prefix of the returned errors
+func (defaults Defaults) Validate(path string) error {
+	var errs []error
+	if defaults.TTLSecondsAfterFinished != nil && *defaults.TTLSecondsAfterFinished < 0 {
+		errs = append(errs, fmt.Errorf("%s.ttlSecondsAfterFinished must not be negative", path))
+	}
+	if defaults.ActiveDeadlineSeconds != nil && *defaults.ActiveDeadlineSeconds <= 0 {
+		errs = append(errs, fmt.Errorf("%s.activeDeadlineSeconds must be positive", path))
+	}
+	if defaults.BackoffLimit != nil && *defaults.BackoffLimit < 0 {
+		errs = append(errs, fmt.Errorf("%s.backoffLimit must not be negative", path))
+	}
+	for key, value := range defaults.Labels {
+		for _, msg := range validation.IsQualifiedName(key) {
+			errs = append(errs, fmt.Errorf("%s.labels: invalid key %s: %s", path, key, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(value) {
+			errs = append(errs, fmt.Errorf("%s.labels.%s: invalid value: %s", path, key, msg))
+		}
+	}
+	for key := range defaults.Annotations {
+		for _, msg := range validation.IsQualifiedName(key) {
+			errs = append(errs, fmt.Errorf("%s.annotations: invalid key %s: %s", path, key, msg))
+		}
+	}
+	if defaults.ServiceAccountName != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(defaults.ServiceAccountName) {
+			errs = append(errs, fmt.Errorf("%s.serviceAccountName: %s", path, msg))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func mergeMaps(base, override map[string]string) map[string]string {
+	if len(override) == 0 {
+		return base
+	}
+	merged := make(map[string]string, len(base)+len(override))
+	maps.Copy(merged, base)
+	maps.Copy(merged, override)
+	return merged
+}
+
+// Alert file delivery modes
+const (
+	AlertFileNone      = "none"
+	AlertFileConfigMap = "configmap"
+	AlertFileSecret    = "secret"
+)
+
+// Injection configures how the alert context is passed to the containers of a job
+type Injection struct {
+	// Containers are the names of the containers receiving the alert context, all containers if empty
+	Containers []string `json:"containers,omitempty"`
+	// InitContainers injects the alert context into init containers as well
+	InitContainers *bool `json:"initContainers,omitempty"`
+	// AlertFile delivers the alert as JSON file via a generated ConfigMap or Secret: none, configmap or secret
+	AlertFile string `json:"alertFile,omitempty"`
+	// MountPath is the directory of the alert file
+	MountPath string `json:"mountPath,omitempty"`
+}
+
+// Merge returns the injection settings with all settings of override applied on top
+func (injection Injection) Merge(override Injection) Injection {
+	merged := injection
+	if override.Containers != nil {
+		merged.Containers = override.Containers
+	}
+	if override.InitContainers != nil {
+		merged.InitContainers = override.InitContainers
+	}
+	if override.AlertFile != "" {
+		merged.AlertFile = override.AlertFile
+	}
+	if override.MountPath != "" {
+		merged.MountPath = override.MountPath
+	}
+	return merged
+}
+
+// Validate checks the injection settings, path is the prefix of the returned errors
+func (injection Injection) Validate(path string) error {
+	var errs []error
+	switch injection.AlertFile {
+	case "", AlertFileNone, AlertFileConfigMap, AlertFileSecret:
+	default:
+		errs = append(errs, fmt.Errorf("%s.alertFile must be %s, %s or %s", path, AlertFileNone, AlertFileConfigMap, AlertFileSecret))
+	}
+	if injection.MountPath != "" && !strings.HasPrefix(injection.MountPath, "/") {
+		errs = append(errs, fmt.Errorf("%s.mountPath must be an absolute path", path))
+	}
+	return errors.Join(errs...)
+}
+
+// Logging configures the logger
+type Logging struct {
+	Level      string            `json:"level"`
+	Format     string            `json:"format"`
+	Stacktrace bool              `json:"stacktrace"`
+	Subsystems map[string]string `json:"subsystems"`
+}
+
+// Tracing configures the OpenTelemetry trace export
+type Tracing struct {
+	Exporter    string  `json:"exporter"`
+	Endpoint    string  `json:"endpoint"`
+	Insecure    bool    `json:"insecure"`
+	SampleRatio float64 `json:"sampleRatio"`
+}
+
+// Duration is a time.Duration which is written as Go duration string (e.g. 5s) or as number of seconds
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case float64:
+		*d = Duration(time.Duration(v * float64(time.Second)))
+		return nil
+	case string:
+		return d.set(v)
+	default:
+		return fmt.Errorf("invalid duration: %s", string(data))
+	}
+}
+
+func (d *Duration) set(value string) error {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		*d = Duration(time.Duration(seconds * float64(time.Second)))
+		return nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid duration: %s", value)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Duration returns the value as time.Duration
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+// Default returns the configuration used when neither file nor environment set a value
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Addr:         ":8080",
+			ReadTimeout:  Duration(5 * time.Second),
+			WriteTimeout: Duration(10 * time.Second),
+		},
+		Store: Store{
+			AlertStoreSize: 10,
+		},
+		Defaults: Defaults{
+			TTLSecondsAfterFinished: ptr.To[int32](300),
+		},
+		Injection: Injection{
+			AlertFile: AlertFileNone,
+			MountPath: "/etc/openfero",
+		},
+		CloudEvents: CloudEvents{
+			Source: "openfero",
+		},
+		Events: Events{
+			Types: []string{EventTypeWarning},
+		},
+		Alertmanager: Alertmanager{
+			SilenceDuration:      Duration(time.Hour),
+			FailureAlertDuration: Duration(time.Hour),
+		},
+		Approvals: Approvals{
+			Timeout: Duration(time.Hour),
+		},
+		Maintenance: Maintenance{
+			ConfigMap: "openfero-maintenance",
+		},
+		Scheduler: Scheduler{
+			LeaderElection: true,
+			Lease:          "openfero-scheduler",
+		},
+		Limits: Limits{
+			MaxBodyBytes:   4 << 20,
+			MaxAlerts:      1000,
+			MaxLabels:      64,
+			MaxLabelLength: 2048,
+		},
+		Webhook: Webhook{
+			SyncTimeout: Duration(5 * time.Second),
+		},
+		RateLimit: RateLimit{
+			Burst:      10,
+			MaxBacklog: 100,
+			Priorities: map[string]string{
+				"critical": PriorityHigh,
+				"warning":  PriorityNormal,
+				"info":     PriorityLow,
+			},
+		},
+		Logging: Logging{
+			Level:  "info",
+			Format: log.FormatJSON,
+		},
+		Tracing: Tracing{
+			Exporter:    tracing.ExporterNone,
+			SampleRatio: 1.0,
+		},
+	}
+}
+
+// Loader loads the configuration from the defaults, a YAML file and the environment, in this order of precedence
+type Loader struct {
+	// Path of the YAML file, no file is read if empty
+	Path string
+	// Environ returns the environment variables, defaults to os.Environ
+	Environ func() []string
+	// Override is applied after the environment, e.g. for explicitly set command line flags
+	Override func(*Config) error
+}
+
+// Load reads and validates the configuration
+func (loader Loader) Load() (*Config, error) {
+	config := Default()
+
+	if loader.Path != "" {
+		data, err := os.ReadFile(loader.Path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read configuration file: %w", err)
+		}
+		if err := Parse(data, config); err != nil {
+			return nil, err
+		}
+	}
+
+	environ := loader.Environ
+	if environ == nil {
+		environ = os.Environ
+	}
+	if err := applyEnv(config, environ()); err != nil {
+		return nil, err
+	}
+
+	if loader.Override != nil {
+		if err := loader.Override(config); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Parse decodes a YAML configuration into config. Unknown fields are rejected to detect typos.
+func Parse(data []byte, config *Config) error {
+	jsonBytes, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return fmt.Errorf("could not parse configuration file: %w", err)
+	}
+	if string(jsonBytes) == "null" {
+		return nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(jsonBytes)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return fmt.Errorf("could not parse configuration file: %w", err)
+	}
+	return nil
+}
+
+// Validate checks the configuration and returns all problems found
+func (config *Config) Validate() error {
+	var errs []error
+
+	if config.Server.Addr == "" {
+		errs = append(errs, errors.New("server.addr must not be empty"))
+	}
+	if config.Server.ReadTimeout <= 0 {
+		errs = append(errs, errors.New("server.readTimeout must be positive"))
+	}
+	if config.Server.WriteTimeout <= 0 {
+		errs = append(errs, errors.New("server.writeTimeout must be positive"))
+	}
+
+	users := make(map[string]bool)
+	for i, token := range config.Auth.Tokens {
+		if token.Token == "" {
+			errs = append(errs, fmt.Errorf("auth.tokens[%d].token must not be empty", i))
+		}
+		if token.User == "" {
+			errs = append(errs, fmt.Errorf("auth.tokens[%d].user must not be empty", i))
+		} else if users[token.User] {
+			errs = append(errs, fmt.Errorf("auth.tokens[%d].user %s is not unique", i, token.User))
+		}
+		users[token.User] = true
+	}
+
+	if config.Store.AlertStoreSize <= 0 {
+		errs = append(errs, errors.New("store.alertStoreSize must be positive"))
+	}
+
+	if err := config.Defaults.Validate("defaults"); err != nil {
+		errs = append(errs, err)
+	}
+	if err := config.Injection.Validate("injection"); err != nil {
+		errs = append(errs, err)
+	}
+	if err := config.Hooks.Validate("hooks"); err != nil {
+		errs = append(errs, err)
+	}
+	if err := config.CloudEvents.Validate("cloudEvents"); err != nil {
+		errs = append(errs, err)
+	}
+	if err := config.Notifications.Validate("notifications"); err != nil {
+		errs = append(errs, err)
+	}
+	if config.Alertmanager.URL != "" {
+		if alertmanager, err := url.Parse(config.Alertmanager.URL); err != nil || (alertmanager.Scheme != "http" && alertmanager.Scheme != "https") || alertmanager.Host == "" {
+			errs = append(errs, errors.New("alertmanager.url must be an http or https URL"))
+		}
+	}
+	if config.Alertmanager.SilenceDuration <= 0 {
+		errs = append(errs, errors.New("alertmanager.silenceDuration must be positive"))
+	}
+	if config.Alertmanager.FailureAlertDuration <= 0 {
+		errs = append(errs, errors.New("alertmanager.failureAlertDuration must be positive"))
+	}
+	if config.Approvals.Timeout <= 0 {
+		errs = append(errs, errors.New("approvals.timeout must be positive"))
+	}
+	if err := config.Maintenance.Validate("maintenance"); err != nil {
+		errs = append(errs, err)
+	}
+	if config.Limits.MaxBodyBytes <= 0 {
+		errs = append(errs, errors.New("limits.maxBodyBytes must be positive"))
+	}
+	if config.Limits.MaxAlerts <= 0 {
+		errs = append(errs, errors.New("limits.maxAlerts must be positive"))
+	}
+	if config.Limits.MaxLabels <= 0 {
+		errs = append(errs, errors.New("limits.maxLabels must be positive"))
+	}
+	if config.Limits.MaxLabelLength <= 0 {
+		errs = append(errs, errors.New("limits.maxLabelLength must be positive"))
+	}
+	if config.Webhook.SyncTimeout <= 0 {
+		errs = append(errs, errors.New("webhook.syncTimeout must be positive"))
+	} else if config.Webhook.Synchronous && config.Webhook.SyncTimeout >= config.Server.WriteTimeout {
+		errs = append(errs, errors.New("webhook.syncTimeout must be shorter than server.writeTimeout in synchronous mode"))
+	}
+	if config.RateLimit.JobsPerSecond < 0 {
+		errs = append(errs, errors.New("rateLimit.jobsPerSecond must not be negative"))
+	}
+	if config.RateLimit.JobsPerSecond > 0 && config.RateLimit.Burst <= 0 {
+		errs = append(errs, errors.New("rateLimit.burst must be positive"))
+	}
+	if config.RateLimit.MaxBacklog < 0 {
+		errs = append(errs, errors.New("rateLimit.maxBacklog must not be negative"))
+	}
+	for severity, priority := range config.RateLimit.Priorities {
+		if !ValidPriority(priority) {
+			errs = append(errs, fmt.Errorf("rateLimit.priorities.%s must be %s, %s or %s", severity, PriorityHigh, PriorityNormal, PriorityLow))
+		}
+	}
+	if config.Scheduler.LeaderElection {
+		for _, msg := range validation.IsDNS1123Subdomain(config.Scheduler.Lease) {
+			errs = append(errs, fmt.Errorf("scheduler.lease: %s", msg))
+		}
+	}
+	for _, eventType := range config.Events.Types {
+		if eventType != EventTypeNormal && eventType != EventTypeWarning {
+			errs = append(errs, fmt.Errorf("events.types must only contain %s or %s", EventTypeNormal, EventTypeWarning))
+		}
+	}
+
+	if _, err := log.ParseLevel(config.Logging.Level); err != nil {
+		errs = append(errs, fmt.Errorf("logging.level: %w", err))
+	}
+	if format := strings.ToLower(config.Logging.Format); format != log.FormatJSON && format != log.FormatConsole {
+		errs = append(errs, fmt.Errorf("logging.format must be %s or %s", log.FormatJSON, log.FormatConsole))
+	}
+	for subsystem, level := range config.Logging.Subsystems {
+		if !slices.Contains(log.Subsystems, subsystem) {
+			errs = append(errs, fmt.Errorf("logging.subsystems: unknown subsystem %s", subsystem))
+		}
+		if _, err := log.ParseLevel(level); err != nil {
+			errs = append(errs, fmt.Errorf("logging.subsystems.%s: %w", subsystem, err))
+		}
+	}
+
+	switch strings.ToLower(config.Tracing.Exporter) {
+	case "", tracing.ExporterNone, tracing.ExporterOTLPGRPC, tracing.ExporterOTLPHTTP:
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter must be %s, %s or %s", tracing.ExporterNone, tracing.ExporterOTLPGRPC, tracing.ExporterOTLPHTTP))
+	}
+	if config.Tracing.SampleRatio < 0 || config.Tracing.SampleRatio > 1 {
+		errs = append(errs, errors.New("tracing.sampleRatio must be between 0 and 1"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// RestartRequired returns the sections which changed between old and new but cannot be applied without restart
+func RestartRequired(old, new *Config) []string {
+	var sections []string
+	if !reflect.DeepEqual(old.Server, new.Server) {
+		sections = append(sections, "server")
+	}
+	if old.Store != new.Store {
+		sections = append(sections, "store")
+	}
+	if old.Namespaces != new.Namespaces {
+		sections = append(sections, "namespaces")
+	}
+	if old.Logging.Format != new.Logging.Format || old.Logging.Stacktrace != new.Logging.Stacktrace {
+		sections = append(sections, "logging")
+	}
+	if old.CloudEvents.Sink != new.CloudEvents.Sink || old.CloudEvents.Source != new.CloudEvents.Source {
+		sections = append(sections, "cloudEvents")
+	}
+	if old.Events.Enabled != new.Events.Enabled || old.Events.Namespace != new.Events.Namespace {
+		sections = append(sections, "events")
+	}
+	if old.Tracing != new.Tracing {
+		sections = append(sections, "tracing")
+	}
+	if old.Scheduler != new.Scheduler {
+		sections = append(sections, "scheduler")
+	}
+	return sections
+}
+
+// applyEnv overrides scalar settings from environment variables named OPENFERO_<SECTION>_<FIELD>,
+// e.g. OPENFERO_SERVER_READ_TIMEOUT for server.readTimeout
+func applyEnv(config *Config, environ []string) error {
+	env := make(map[string]string, len(environ))
+	for _, entry := range environ {
+		if key, value, found := strings.Cut(entry, "="); found && strings.HasPrefix(key, EnvPrefix) {
+			env[key] = value
+		}
+	}
+
+	if token := env[apiTokenEnv]; token != "" {
+		config.Auth.Tokens = append(config.Auth.Tokens, Token{User: apiTokenUser, Token: token})
+	}
+
+	sections := reflect.ValueOf(config).Elem()
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Field(i)
+		sectionName := envName(jsonName(sections.Type().Field(i)))
+		for j := 0; j < section.NumField(); j++ {
+			name := EnvPrefix + sectionName + "_" + envName(jsonName(section.Type().Field(j)))
+			value, ok := env[name]
+			if !ok {
+				continue
+			}
+			if err := setValue(section.Field(j), value); err != nil {
+				return fmt.Errorf("invalid value of %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func setValue(field reflect.Value, value string) error {
+	if duration, ok := field.Addr().Interface().(*Duration); ok {
+		return duration.set(value)
+	}
+	switch field.Kind() {
+	case reflect.Pointer:
+		if kind := field.Type().Elem().Kind(); kind == reflect.Struct || kind == reflect.Slice || kind == reflect.Map {
+			return errors.New("can only be set in the configuration file")
+		}
+		allocated := reflect.New(field.Type().Elem())
+		if err := setValue(allocated.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(allocated)
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return errors.New("can only be set in the configuration file")
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	case reflect.Map:
+		if field.Type().Key().Kind() != reflect.String || field.Type().Elem().Kind() != reflect.String {
+			return errors.New("can only be set in the configuration file")
+		}
+		items := make(map[string]string)
+		for _, pair := range strings.Split(value, ",") {
+			if pair = strings.TrimSpace(pair); pair == "" {
+				continue
+			}
+			key, item, found := strings.Cut(pair, "=")
+			if !found {
+				return fmt.Errorf("expected key=value pairs, got %s", pair)
+			}
+			items[strings.TrimSpace(key)] = strings.TrimSpace(item)
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return errors.New("can only be set in the configuration file")
+	}
+	return nil
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return name
+}
+
+// envName converts a camelCase name to SCREAMING_SNAKE_CASE
+func envName(name string) string {
+	var builder strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) && i > 0 {
+			builder.WriteByte('_')
+		}
+		builder.WriteRune(unicode.ToUpper(r))
+	}
+	return builder.String()
+}
@@ -0,0 +1,360 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"k8s.io/utils/ptr"
+)
+
+func TestMain(m *testing.M) {
+	if err := log.SetConfig(log.Config{Level: "debug"}); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// replaceConfig replaces the file at once like the kubelet does for ConfigMap volumes,
+// so Watch never reads a truncated file
+func replaceConfig(t *testing.T, path string, content string) {
+	t.Helper()
+	next := path + ".next"
+	if err := os.WriteFile(next, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(next, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `
+server:
+  addr: ":9090"
+  readTimeout: 15s
+  writeTimeout: 20
+auth:
+  tokens:
+    - user: alice
+      token: secret
+store:
+  alertStoreSize: 50
+policy:
+  disabledAlerts: [Watchdog]
+defaults:
+  ttlSecondsAfterFinished: 600
+logging:
+  level: warn
+  subsystems:
+    webhook: debug
+`)
+
+	tests := []struct {
+		name     string
+		environ  []string
+		override func(*Config) error
+		want     func(*Config)
+	}{
+		{
+			name: "File",
+			want: func(cfg *Config) {
+				cfg.Server = Server{Addr: ":9090", ReadTimeout: Duration(15 * time.Second), WriteTimeout: Duration(20 * time.Second)}
+				cfg.Auth.Tokens = []Token{{User: "alice", Token: "secret"}}
+				cfg.Store.AlertStoreSize = 50
+				cfg.Policy.DisabledAlerts = []string{"Watchdog"}
+				cfg.Defaults.TTLSecondsAfterFinished = ptr.To[int32](600)
+				cfg.Logging.Level = "warn"
+				cfg.Logging.Subsystems = map[string]string{"webhook": "debug"}
+			},
+		},
+		{
+			name: "Environment overrides file",
+			environ: []string{
+				"OPENFERO_SERVER_READ_TIMEOUT=1m",
+				"OPENFERO_STORE_ALERT_STORE_SIZE=5",
+				"OPENFERO_POLICY_DISABLED_ALERTS=Watchdog, InfoInhibitor",
+				"OPENFERO_LOGGING_SUBSYSTEMS=jobs=error",
+				"OPENFERO_NAMESPACES_JOB_DESTINATION=remediation",
+				"OPENFERO_API_TOKEN=admin-secret",
+				"PATH=/usr/bin",
+			},
+			want: func(cfg *Config) {
+				cfg.Server = Server{Addr: ":9090", ReadTimeout: Duration(time.Minute), WriteTimeout: Duration(20 * time.Second)}
+				cfg.Auth.Tokens = []Token{{User: "alice", Token: "secret"}, {User: "admin", Token: "admin-secret"}}
+				cfg.Store.AlertStoreSize = 5
+				cfg.Policy.DisabledAlerts = []string{"Watchdog", "InfoInhibitor"}
+				cfg.Namespaces.JobDestination = "remediation"
+				cfg.Defaults.TTLSecondsAfterFinished = ptr.To[int32](600)
+				cfg.Logging.Level = "warn"
+				cfg.Logging.Subsystems = map[string]string{"jobs": "error"}
+			},
+		},
+		{
+			name:    "Write timeout below sync timeout without synchronous mode",
+			environ: []string{"OPENFERO_SERVER_WRITE_TIMEOUT=3s"},
+			want: func(cfg *Config) {
+				cfg.Server = Server{Addr: ":9090", ReadTimeout: Duration(15 * time.Second), WriteTimeout: Duration(3 * time.Second)}
+				cfg.Auth.Tokens = []Token{{User: "alice", Token: "secret"}}
+				cfg.Store.AlertStoreSize = 50
+				cfg.Policy.DisabledAlerts = []string{"Watchdog"}
+				cfg.Defaults.TTLSecondsAfterFinished = ptr.To[int32](600)
+				cfg.Logging.Level = "warn"
+				cfg.Logging.Subsystems = map[string]string{"webhook": "debug"}
+			},
+		},
+		{
+			name:    "Override takes precedence over environment",
+			environ: []string{"OPENFERO_LOGGING_LEVEL=error"},
+			override: func(cfg *Config) error {
+				cfg.Logging.Level = "debug"
+				return nil
+			},
+			want: func(cfg *Config) {
+				cfg.Server = Server{Addr: ":9090", ReadTimeout: Duration(15 * time.Second), WriteTimeout: Duration(20 * time.Second)}
+				cfg.Auth.Tokens = []Token{{User: "alice", Token: "secret"}}
+				cfg.Store.AlertStoreSize = 50
+				cfg.Policy.DisabledAlerts = []string{"Watchdog"}
+				cfg.Defaults.TTLSecondsAfterFinished = ptr.To[int32](600)
+				cfg.Logging.Level = "debug"
+				cfg.Logging.Subsystems = map[string]string{"webhook": "debug"}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := Loader{Path: path, Environ: func() []string { return tt.environ }, Override: tt.override}
+			got, err := loader.Load()
+			if err != nil {
+				t.Fatalf("Load() returned error: %v", err)
+			}
+			want := Default()
+			tt.want(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Load() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		environ []string
+		wantErr string
+	}{
+		{name: "Unknown field", content: "server:\n  adress: ':8080'\n", wantErr: "unknown field"},
+		{name: "Invalid YAML", content: "server: [", wantErr: "could not parse"},
+		{name: "Invalid duration", content: "server:\n  readTimeout: soon\n", wantErr: "invalid duration"},
+		{name: "Invalid log level", content: "logging:\n  level: verbose\n", wantErr: "logging.level"},
+		{name: "Unknown subsystem", content: "logging:\n  subsystems:\n    database: debug\n", wantErr: "unknown subsystem"},
+		{name: "Invalid exporter", content: "tracing:\n  exporter: zipkin\n", wantErr: "tracing.exporter"},
+		{name: "Negative store size", content: "store:\n  alertStoreSize: -1\n", wantErr: "store.alertStoreSize"},
+		{name: "Token without user", content: "auth:\n  tokens:\n    - token: secret\n", wantErr: "auth.tokens[0].user"},
+		{name: "Invalid default label", content: "defaults:\n  labels:\n    bad key: x\n", wantErr: "defaults.labels"},
+		{name: "Negative backoff limit", environ: []string{"OPENFERO_DEFAULTS_BACKOFF_LIMIT=-1"}, wantErr: "defaults.backoffLimit"},
+		{name: "Duplicate user", content: "auth:\n  tokens:\n    - {user: a, token: first}\n    - {user: a, token: second}\n", wantErr: "not unique"},
+		{name: "Invalid environment value", environ: []string{"OPENFERO_STORE_ALERT_STORE_SIZE=many"}, wantErr: "OPENFERO_STORE_ALERT_STORE_SIZE"},
+		{name: "Hook without alertname", content: "hooks:\n  sources:\n    ci:\n      status: '{.state}'\n", wantErr: "hooks.sources.ci.alertname"},
+		{name: "Invalid hook template", content: "hooks:\n  sources:\n    ci:\n      alertname: '{.name'\n", wantErr: "invalid JSONPath template"},
+		{name: "Invalid hook status value", content: "hooks:\n  sources:\n    ci:\n      alertname: Build\n      statusValues:\n        failed: broken\n", wantErr: "statusValues.failed"},
+		{name: "Invalid hook source name", content: "hooks:\n  sources:\n    a/b:\n      alertname: Build\n", wantErr: "name must consist"},
+		{name: "Invalid CloudEvents sink", environ: []string{"OPENFERO_CLOUD_EVENTS_SINK=broker"}, wantErr: "cloudEvents.sink"},
+		{name: "Invalid CloudEvents type mapping", content: "cloudEvents:\n  types:\n    com.example.build:\n      labels:\n        a: b\n", wantErr: "cloudEvents.types.com.example.build.alertname"},
+		{name: "Invalid Alertmanager URL", environ: []string{"OPENFERO_ALERTMANAGER_URL=alertmanager:9093"}, wantErr: "alertmanager.url"},
+		{name: "Invalid silence duration", content: "alertmanager:\n  silenceDuration: 0s\n", wantErr: "alertmanager.silenceDuration"},
+		{name: "Unknown notification receiver type", content: "notifications:\n  receivers:\n    sre:\n      type: pager\n", wantErr: "notifications.receivers.sre.type"},
+		{name: "Email receiver without recipients", content: "notifications:\n  receivers:\n    sre:\n      type: email\n      email:\n        smarthost: smtp:25\n        from: openfero@example.com\n", wantErr: "notifications.receivers.sre.email.to"},
+		{name: "Invalid notification template", content: "notifications:\n  receivers:\n    sre:\n      type: slack\n      url: https://hooks.slack.com/x\n      text: \"{{ .Job \"\n", wantErr: "notifications.receivers.sre.text"},
+		{name: "Route to unknown receiver", content: "notifications:\n  routes:\n    - receivers: [sre]\n", wantErr: "unknown receiver sre"},
+		{name: "Invalid approval timeout", environ: []string{"OPENFERO_APPROVALS_TIMEOUT=0"}, wantErr: "approvals.timeout"},
+		{name: "Invalid maintenance schedule", content: "maintenance:\n  windows:\n    - name: nightly\n      schedule: '0 25 * * *'\n      duration: 1h\n", wantErr: "maintenance.windows[0].schedule"},
+		{name: "Maintenance window without duration", content: "maintenance:\n  windows:\n    - name: nightly\n      schedule: '0 2 * * *'\n", wantErr: "maintenance.windows[0].duration"},
+		{name: "Maintenance window ending before start", content: "maintenance:\n  windows:\n    - name: upgrade\n      start: 2026-05-02T10:00:00Z\n      end: 2026-05-02T08:00:00Z\n", wantErr: "maintenance.windows[0].end"},
+		{name: "Maintenance window without time", content: "maintenance:\n  windows:\n    - name: upgrade\n", wantErr: "either set schedule or start and end"},
+		{name: "Zero body size", environ: []string{"OPENFERO_LIMITS_MAX_BODY_BYTES=0"}, wantErr: "limits.maxBodyBytes"},
+		{name: "Negative label count", content: "limits:\n  maxLabels: -1\n", wantErr: "limits.maxLabels"},
+		{name: "Sync timeout beyond write timeout", content: "server:\n  writeTimeout: 10s\nwebhook:\n  synchronous: true\n  syncTimeout: 10s\n", wantErr: "webhook.syncTimeout must be shorter"},
+		{name: "Invalid priority", content: "rateLimit:\n  priorities:\n    critical: urgent\n", wantErr: "rateLimit.priorities.critical"},
+		{name: "Rate limit without burst", environ: []string{"OPENFERO_RATE_LIMIT_JOBS_PER_SECOND=5", "OPENFERO_RATE_LIMIT_BURST=0"}, wantErr: "rateLimit.burst"},
+		{name: "Negative rate limit", environ: []string{"OPENFERO_RATE_LIMIT_JOBS_PER_SECOND=-1"}, wantErr: "rateLimit.jobsPerSecond"},
+		{name: "Invalid scheduler lease", environ: []string{"OPENFERO_SCHEDULER_LEASE=Scheduler Lease"}, wantErr: "scheduler.lease"},
+		{name: "Environment for file only setting", environ: []string{"OPENFERO_AUTH_TOKENS=secret"}, wantErr: "only be set in the configuration file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := Loader{Environ: func() []string { return tt.environ }}
+			if tt.content != "" {
+				loader.Path = writeConfig(t, tt.content)
+			}
+			_, err := loader.Load()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	loader := Loader{Path: filepath.Join(t.TempDir(), "missing.yaml")}
+	if _, err := loader.Load(); err == nil {
+		t.Error("Load() did not return an error for a missing file")
+	}
+}
+
+func TestRestartRequired(t *testing.T) {
+	old := Default()
+	changed := Default()
+	changed.Server.Addr = ":9090"
+	changed.Logging.Level = "debug"
+	changed.Policy.DisabledAlerts = []string{"Watchdog"}
+	changed.Tracing.Exporter = "otlphttp"
+	changed.Scheduler.LeaderElection = false
+
+	got := RestartRequired(old, changed)
+	want := []string{"server", "tracing", "scheduler"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RestartRequired() = %v, want %v", got, want)
+	}
+}
+
+func TestRateLimitPriority(t *testing.T) {
+	rateLimit := Default().RateLimit
+	rateLimit.Priorities["page"] = PriorityHigh
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   string
+	}{
+		{name: "Critical", labels: map[string]string{"severity": "critical"}, want: PriorityHigh},
+		{name: "Info", labels: map[string]string{"severity": "info"}, want: PriorityLow},
+		{name: "Configured severity", labels: map[string]string{"severity": "page"}, want: PriorityHigh},
+		{name: "Unknown severity", labels: map[string]string{"severity": "none"}, want: PriorityNormal},
+		{name: "No severity", labels: map[string]string{"alertname": "Test"}, want: PriorityNormal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rateLimit.Priority(tt.labels); got != tt.want {
+				t.Errorf("Priority() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatch(t *testing.T) {
+	path := writeConfig(t, "logging:\n  level: info\n")
+	loader := Loader{Path: path, Environ: func() []string { return nil }}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan *Config, 1)
+	go loader.Watch(ctx, 10*time.Millisecond, func(cfg *Config) {
+		changes <- cfg
+	})
+
+	// An invalid file must not be applied
+	replaceConfig(t, path, "logging:\n  level: verbose\n")
+	select {
+	case cfg := <-changes:
+		t.Fatalf("Watch() applied an invalid configuration: %+v", cfg)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	replaceConfig(t, path, "logging:\n  level: debug\n")
+	select {
+	case cfg := <-changes:
+		if cfg.Logging.Level != "debug" {
+			t.Errorf("Watch() level = %s, want debug", cfg.Logging.Level)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch() did not report the changed configuration")
+	}
+}
+
+func TestDefaultsMerge(t *testing.T) {
+	global := Defaults{
+		TTLSecondsAfterFinished: ptr.To[int32](300),
+		BackoffLimit:            ptr.To[int32](6),
+		Labels:                  map[string]string{"team": "sre", "tier": "ops"},
+		ServiceAccountName:      "openfero-jobs",
+	}
+	override := Defaults{
+		TTLSecondsAfterFinished: ptr.To[int32](0),
+		Labels:                  map[string]string{"team": "platform"},
+	}
+
+	merged := global.Merge(override)
+	want := Defaults{
+		TTLSecondsAfterFinished: ptr.To[int32](0),
+		BackoffLimit:            ptr.To[int32](6),
+		Labels:                  map[string]string{"team": "platform", "tier": "ops"},
+		ServiceAccountName:      "openfero-jobs",
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("Merge() = %+v, want %+v", merged, want)
+	}
+	if global.Labels["team"] != "sre" {
+		t.Error("Merge() modified the global defaults")
+	}
+}
+
+func TestMaintenanceWindow(t *testing.T) {
+	start := time.Date(2026, 5, 2, 8, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+	tests := []struct {
+		name   string
+		window MaintenanceWindow
+		now    time.Time
+		labels map[string]string
+		want   bool
+	}{
+		{name: "Within single window", window: MaintenanceWindow{Start: &start, End: &end}, now: start.Add(time.Hour), want: true},
+		{name: "After single window", window: MaintenanceWindow{Start: &start, End: &end}, now: end, want: false},
+		{name: "Within recurring window", window: MaintenanceWindow{Schedule: "0 22 * * *", Duration: Duration(4 * time.Hour)}, now: time.Date(2026, 5, 3, 1, 0, 0, 0, time.UTC), want: true},
+		{name: "After recurring window", window: MaintenanceWindow{Schedule: "0 22 * * *", Duration: Duration(4 * time.Hour)}, now: time.Date(2026, 5, 3, 2, 30, 0, 0, time.UTC), want: false},
+		{name: "Local time", window: MaintenanceWindow{Schedule: "0 22 * * *", Duration: Duration(time.Hour)}, now: time.Date(2026, 5, 2, 22, 30, 0, 0, time.FixedZone("UTC+2", 2*60*60)), want: false},
+		{name: "Time zone", window: MaintenanceWindow{Schedule: "CRON_TZ=Europe/Berlin 0 22 * * *", Duration: Duration(time.Hour)}, now: time.Date(2026, 5, 2, 20, 30, 0, 0, time.UTC), want: true},
+		{
+			name:   "Matching labels",
+			window: MaintenanceWindow{Start: &start, End: &end, Matchers: map[string]string{"cluster": "prod"}},
+			now:    start,
+			labels: map[string]string{"cluster": "prod", "alertname": "KubeQuotaAlmostFull"},
+			want:   true,
+		},
+		{
+			name:   "Other labels",
+			window: MaintenanceWindow{Start: &start, End: &end, Matchers: map[string]string{"cluster": "prod"}},
+			now:    start,
+			labels: map[string]string{"cluster": "staging"},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maintenance := Maintenance{Windows: []MaintenanceWindow{tt.window}}
+			if _, got := maintenance.ActiveWindow(tt.now, tt.labels); got != tt.want {
+				t.Errorf("ActiveWindow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
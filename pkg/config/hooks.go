@@ -0,0 +1,128 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// sourceNamePattern restricts source names to a single segment of the URL path
+var sourceNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// Hooks configures the generic webhook receiver at /hooks/{source}
+type Hooks struct {
+	// Sources maps the name of a source to the mapping of its payload
+	Sources map[string]HookSource `json:"sources,omitempty"`
+}
+
+// HookSource maps the JSON payload of a source to alerts.
+// Every mapping is a JSONPath template as used by kubectl, e.g. {.repository.name}.
+// Text outside of braces is taken as is, so a mapping without braces is a constant.
+type HookSource struct {
+	// Alerts selects a list in the payload, every item becomes an alert and is the input of the other mappings.
+	// The whole payload is a single alert if empty.
+	Alerts string `json:"alerts,omitempty"`
+	// Alertname selects the definition of the job
+	Alertname string `json:"alertname"`
+	// Status is firing if empty
+	Status string `json:"status,omitempty"`
+	// StatusValues translates values of the status mapping to firing or resolved
+	StatusValues map[string]string `json:"statusValues,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// Validate checks the sources and their mappings
+func (hooks Hooks) Validate(path string) error {
+	var errs []error
+	for _, name := range sortedNames(hooks.Sources) {
+		source := hooks.Sources[name]
+		sourcePath := path + ".sources." + name
+		if !sourceNamePattern.MatchString(name) {
+			errs = append(errs, fmt.Errorf("%s: name must consist of alphanumeric characters, '-', '_' or '.'", sourcePath))
+		}
+		if err := source.Validate(sourcePath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the mappings of the source, path is the prefix of the returned errors
+func (source HookSource) Validate(path string) error {
+	var errs []error
+	if source.Alertname == "" {
+		errs = append(errs, fmt.Errorf("%s.alertname must not be empty", path))
+	}
+	templates := map[string]string{"alerts": source.Alerts, "alertname": source.Alertname, "status": source.Status}
+	for key, template := range source.Labels {
+		templates["labels."+key] = template
+	}
+	for key, template := range source.Annotations {
+		templates["annotations."+key] = template
+	}
+	for _, field := range sortedNames(templates) {
+		if _, err := ParseTemplate(field, templates[field]); err != nil {
+			errs = append(errs, fmt.Errorf("%s.%s: %w", path, field, err))
+		}
+	}
+	for value, status := range source.StatusValues {
+		if status != "firing" && status != "resolved" {
+			errs = append(errs, fmt.Errorf("%s.statusValues.%s must be firing or resolved", path, value))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ParseTemplate parses a JSONPath template of a hook mapping. Missing keys evaluate to an empty string.
+func ParseTemplate(name string, template string) (*jsonpath.JSONPath, error) {
+	parser := jsonpath.New(name).AllowMissingKeys(true)
+	if err := parser.Parse(template); err != nil {
+		return nil, fmt.Errorf("invalid JSONPath template: %w", err)
+	}
+	return parser, nil
+}
+
+func sortedNames[V any](values map[string]V) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CloudEvents configures the CloudEvents receiver at /cloudevents and the emission of job lifecycle events
+type CloudEvents struct {
+	// Types maps event types to alerts like the sources of the generic webhook receiver.
+	// The mappings are evaluated against the event in structured JSON format, e.g. {.subject} or {.data.name}.
+	// Events of other types need the extension attribute alertname.
+	Types map[string]HookSource `json:"types,omitempty"`
+	// Sink is the URL job lifecycle events are sent to, no events are sent if empty
+	Sink string `json:"sink,omitempty"`
+	// Source is the source attribute of the sent events
+	Source string `json:"source,omitempty"`
+}
+
+// Validate checks the type mappings and the sink
+func (cloudEvents CloudEvents) Validate(path string) error {
+	var errs []error
+	for _, eventType := range sortedNames(cloudEvents.Types) {
+		if err := cloudEvents.Types[eventType].Validate(path + ".types." + eventType); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if cloudEvents.Sink != "" {
+		if sink, err := url.Parse(cloudEvents.Sink); err != nil || (sink.Scheme != "http" && sink.Scheme != "https") || sink.Host == "" {
+			errs = append(errs, fmt.Errorf("%s.sink must be an http or https URL", path))
+		}
+	}
+	if cloudEvents.Source == "" {
+		errs = append(errs, fmt.Errorf("%s.source must not be empty", path))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Maintenance stops the creation of jobs, globally or during maintenance windows
+type Maintenance struct {
+	// Paused stops the creation of all jobs
+	Paused bool `json:"paused"`
+	// ConfigMap is the name of the ConfigMap in the namespace of the definitions whose annotation openfero/paused pauses OpenFero
+	ConfigMap string `json:"configMap"`
+	// Windows are the periods during which no jobs are created
+	Windows []MaintenanceWindow `json:"windows"`
+}
+
+// MaintenanceWindow is a recurring period, starting by a cron schedule and lasting for a duration,
+// or a single period between start and end
+type MaintenanceWindow struct {
+	// Name is shown as reason of the skipped jobs
+	Name string `json:"name"`
+	// Schedule is a cron expression of the start of a recurring window, CRON_TZ=<zone> selects the time zone
+	Schedule string   `json:"schedule,omitempty"`
+	Duration Duration `json:"duration,omitempty"`
+	// Start and End of a single window
+	Start *time.Time `json:"start,omitempty"`
+	End   *time.Time `json:"end,omitempty"`
+	// Matchers restrict the window to alerts with these labels, all alerts if empty
+	Matchers map[string]string `json:"matchers,omitempty"`
+}
+
+// Matches returns if the window applies to alerts with the labels
+func (window MaintenanceWindow) Matches(labels map[string]string) bool {
+	for name, value := range window.Matchers {
+		if labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Active returns if the window covers now
+func (window MaintenanceWindow) Active(now time.Time) bool {
+	if window.Schedule == "" {
+		return window.Start != nil && window.End != nil && !now.Before(*window.Start) && now.Before(*window.End)
+	}
+	schedule, err := cron.ParseStandard(window.Schedule)
+	if err != nil {
+		return false
+	}
+	// The window is active if it started within the last duration. Schedules without CRON_TZ are in UTC,
+	// independent of the time zone of now.
+	now = now.UTC()
+	return !schedule.Next(now.Add(-window.Duration.Duration())).After(now)
+}
+
+// Validate checks that the window is either recurring or single
+func (window MaintenanceWindow) Validate(path string) error {
+	var errs []error
+	if window.Name == "" {
+		errs = append(errs, fmt.Errorf("%s.name must not be empty", path))
+	}
+	switch {
+	case window.Schedule != "" && (window.Start != nil || window.End != nil):
+		errs = append(errs, fmt.Errorf("%s must either set schedule or start and end", path))
+	case window.Schedule != "":
+		if _, err := cron.ParseStandard(window.Schedule); err != nil {
+			errs = append(errs, fmt.Errorf("%s.schedule: %w", path, err))
+		}
+		if window.Duration <= 0 {
+			errs = append(errs, fmt.Errorf("%s.duration must be positive", path))
+		}
+	case window.Start == nil || window.End == nil:
+		errs = append(errs, fmt.Errorf("%s must either set schedule or start and end", path))
+	case !window.End.After(*window.Start):
+		errs = append(errs, fmt.Errorf("%s.end must be after start", path))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the windows
+func (maintenance Maintenance) Validate(path string) error {
+	var errs []error
+	names := make(map[string]bool, len(maintenance.Windows))
+	for i, window := range maintenance.Windows {
+		windowPath := fmt.Sprintf("%s.windows[%d]", path, i)
+		if err := window.Validate(windowPath); err != nil {
+			errs = append(errs, err)
+		}
+		if window.Name != "" && names[window.Name] {
+			errs = append(errs, fmt.Errorf("%s.name %s is not unique", windowPath, window.Name))
+		}
+		names[window.Name] = true
+	}
+	return errors.Join(errs...)
+}
+
+// ActiveWindow returns the first window covering now and matching the labels
+func (maintenance Maintenance) ActiveWindow(now time.Time, labels map[string]string) (MaintenanceWindow, bool) {
+	for _, window := range maintenance.Windows {
+		if window.Matches(labels) && window.Active(now) {
+			return window, true
+		}
+	}
+	return MaintenanceWindow{}, false
+}
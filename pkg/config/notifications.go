@@ -0,0 +1,162 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"text/template"
+)
+
+// Types of notification receivers
+const (
+	ReceiverSlack      = "slack"
+	ReceiverMattermost = "mattermost"
+	ReceiverTeams      = "teams"
+	ReceiverWebhook    = "webhook"
+	ReceiverEmail      = "email"
+)
+
+// Job outcomes notifications are routed by
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+)
+
+// Notifications configures the messages sent when jobs succeed or fail
+type Notifications struct {
+	// Receivers maps the name of a receiver to where and how its messages are sent
+	Receivers map[string]Receiver `json:"receivers,omitempty"`
+	// Routes select the receivers of a finished job, all matching routes are notified.
+	// Definitions can replace them in their openfero.yaml.
+	Routes []Route `json:"routes,omitempty"`
+}
+
+// Receiver is a destination of notifications
+type Receiver struct {
+	// Type is slack, mattermost, teams, webhook or email
+	Type string `json:"type"`
+	// URL of the incoming webhook, not used by email
+	URL string `json:"url,omitempty"`
+	// Email configures the SMTP delivery of the receiver type email
+	Email *Email `json:"email,omitempty"`
+	// Title is a Go template of the title or subject, a default is used if empty
+	Title string `json:"title,omitempty"`
+	// Text is a Go template of the message, a default is used if empty
+	Text string `json:"text,omitempty"`
+}
+
+// Email configures the delivery of notifications via SMTP.
+// STARTTLS is used if the server supports it.
+type Email struct {
+	// Smarthost is the host:port of the SMTP server
+	Smarthost string   `json:"smarthost"`
+	From      string   `json:"from"`
+	To        []string `json:"to"`
+	// Username and Password authenticate with PLAIN, no authentication if empty
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// Route selects receivers for the jobs of alerts with matching labels
+type Route struct {
+	// Receivers are the names of the notified receivers
+	Receivers []string `json:"receivers"`
+	// Matchers are labels the alert of the job must have with equal values, all alerts match if empty
+	Matchers map[string]string `json:"matchers,omitempty"`
+	// Outcomes are succeeded or failed, both if empty
+	Outcomes []string `json:"outcomes,omitempty"`
+}
+
+// Matches reports whether the route applies to a job of an alert with the labels and the outcome
+func (route Route) Matches(labels map[string]string, outcome string) bool {
+	if len(route.Outcomes) > 0 && !slices.Contains(route.Outcomes, outcome) {
+		return false
+	}
+	for name, value := range route.Matchers {
+		if labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks the route without checking if its receivers exist
+func (route Route) Validate(path string) error {
+	var errs []error
+	if len(route.Receivers) == 0 {
+		errs = append(errs, fmt.Errorf("%s.receivers must not be empty", path))
+	}
+	for _, outcome := range route.Outcomes {
+		if outcome != OutcomeSucceeded && outcome != OutcomeFailed {
+			errs = append(errs, fmt.Errorf("%s.outcomes must only contain %s or %s", path, OutcomeSucceeded, OutcomeFailed))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the receivers and that the routes only refer to existing receivers
+func (notifications Notifications) Validate(path string) error {
+	var errs []error
+	for _, name := range sortedNames(notifications.Receivers) {
+		if err := notifications.Receivers[name].Validate(path + ".receivers." + name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for i, route := range notifications.Routes {
+		routePath := fmt.Sprintf("%s.routes[%d]", path, i)
+		if err := route.Validate(routePath); err != nil {
+			errs = append(errs, err)
+		}
+		for _, receiver := range route.Receivers {
+			if _, ok := notifications.Receivers[receiver]; !ok {
+				errs = append(errs, fmt.Errorf("%s: unknown receiver %s", routePath, receiver))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the type, destination and templates of the receiver
+func (receiver Receiver) Validate(path string) error {
+	var errs []error
+	switch receiver.Type {
+	case ReceiverSlack, ReceiverMattermost, ReceiverTeams, ReceiverWebhook:
+		if webhook, err := url.Parse(receiver.URL); err != nil || (webhook.Scheme != "http" && webhook.Scheme != "https") || webhook.Host == "" {
+			errs = append(errs, fmt.Errorf("%s.url must be an http or https URL", path))
+		}
+	case ReceiverEmail:
+		if receiver.Email == nil {
+			errs = append(errs, fmt.Errorf("%s.email must be set for receivers of type %s", path, ReceiverEmail))
+			break
+		}
+		if _, _, err := net.SplitHostPort(receiver.Email.Smarthost); err != nil {
+			errs = append(errs, fmt.Errorf("%s.email.smarthost must be host:port", path))
+		}
+		if receiver.Email.From == "" {
+			errs = append(errs, fmt.Errorf("%s.email.from must not be empty", path))
+		}
+		if len(receiver.Email.To) == 0 {
+			errs = append(errs, fmt.Errorf("%s.email.to must not be empty", path))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s.type must be %s, %s, %s, %s or %s", path, ReceiverSlack, ReceiverMattermost, ReceiverTeams, ReceiverWebhook, ReceiverEmail))
+	}
+	if _, err := ParseMessageTemplate("title", receiver.Title); err != nil {
+		errs = append(errs, fmt.Errorf("%s.title: %w", path, err))
+	}
+	if _, err := ParseMessageTemplate("text", receiver.Text); err != nil {
+		errs = append(errs, fmt.Errorf("%s.text: %w", path, err))
+	}
+	return errors.Join(errs...)
+}
+
+// ParseMessageTemplate parses a Go template of a notification
+func ParseMessageTemplate(name string, text string) (*template.Template, error) {
+	tpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tpl, nil
+}
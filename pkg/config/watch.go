@@ -0,0 +1,49 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"time"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// Watch polls the configuration file and calls onChange with the new configuration whenever its content changes.
+// Polling the content instead of using file events also works for ConfigMap volumes, which are updated by swapping symlinks.
+// An invalid file is logged and ignored, so the last valid configuration stays active.
+func (loader Loader) Watch(ctx context.Context, interval time.Duration, onChange func(*Config)) {
+	if loader.Path == "" {
+		return
+	}
+	last, _ := os.ReadFile(loader.Path)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		data, err := os.ReadFile(loader.Path)
+		if err != nil {
+			log.Warn("Could not read configuration file", zap.String("path", loader.Path), zap.String("error", err.Error()))
+			continue
+		}
+		if bytes.Equal(data, last) {
+			continue
+		}
+		last = data
+
+		config, err := loader.Load()
+		if err != nil {
+			log.Error("Invalid configuration file, keeping the current configuration", zap.String("path", loader.Path), zap.String("error", err.Error()))
+			continue
+		}
+		log.Info("Configuration file changed", zap.String("path", loader.Path))
+		onChange(config)
+	}
+}
@@ -100,115 +100,283 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/main.hookMessage"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Create the jobs before answering, overrides webhook.synchronous",
+                        "name": "sync",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "Jobs created, synchronous mode",
+                        "schema": {
+                            "$ref": "#/definitions/main.webhookResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Jobs queued",
+                        "schema": {
+                            "$ref": "#/definitions/main.webhookResponse"
+                        }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid body, status or no valid alert",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/main.webhookError"
+                        }
+                    },
+                    "413": {
+                        "description": "Body or number of alerts exceeds the limits",
+                        "schema": {
+                            "$ref": "#/definitions/main.webhookError"
                         }
                     }
                 }
             }
         },
-        "/assets/{path}": {
-            "get": {
-                "description": "Serve static assets like CSS and JavaScript files",
+        "/alerts/grafana": {
+            "post": {
+                "description": "Process alerts received from the webhook contact point of Grafana unified alerting",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "assets"
+                    "alerts"
                 ],
-                "summary": "Serve static assets",
+                "summary": "Process incoming Grafana alerts",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Asset path",
-                        "name": "path",
-                        "in": "path",
-                        "required": true
+                        "description": "Grafana alert message",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.grafanaMessage"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Create the jobs before answering, overrides webhook.synchronous",
+                        "name": "sync",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Jobs created, synchronous mode",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/main.webhookResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Jobs queued",
+                        "schema": {
+                            "$ref": "#/definitions/main.webhookResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid body, status or no valid alert",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/main.webhookError"
+                        }
+                    },
+                    "413": {
+                        "description": "Body or number of alerts exceeds the limits",
+                        "schema": {
+                            "$ref": "#/definitions/main.webhookError"
                         }
                     }
                 }
             }
         },
-        "/healthz": {
+        "/api/approvals": {
             "get": {
-                "description": "Get the health status of the OpenFero service",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the jobs waiting for approval and the latest decisions",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "approvals"
                 ],
-                "summary": "Get health status",
+                "summary": "List approvals",
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.approval"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
         },
-        "/readiness": {
-            "get": {
-                "description": "Get the readiness status of the OpenFero service",
+        "/api/approvals/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Approve a job waiting for approval, the job is created immediately. If it can not be created, the job stays pending and can be approved again. During a pause or maintenance window the approval is answered with 409 and the job stays pending.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "approvals"
+                ],
+                "summary": "Approve a job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the approval",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment of the decision",
+                        "name": "decision",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/main.approvalDecision"
+                        }
+                    }
                 ],
-                "summary": "Get readiness status",
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.approval"
+                        }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "500": {
+                        "description": "Job could not be created, the approval stays pending",
+                        "schema": {
+                            "$ref": "#/definitions/main.approval"
+                        }
                     }
                 }
             }
         },
-        "/ui": {
-            "get": {
-                "description": "Get the main UI page",
+        "/api/approvals/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reject a job waiting for approval, the job is dropped",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
-                    "text/html"
+                    "application/json"
                 ],
                 "tags": [
-                    "ui"
+                    "approvals"
+                ],
+                "summary": "Reject a job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the approval",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment of the decision",
+                        "name": "decision",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/main.approvalDecision"
+                        }
+                    }
                 ],
-                "summary": "Get UI page",
                 "responses": {
                     "200": {
-                        "description": "HTML page",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.approval"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
                         "schema": {
                             "type": "string"
                         }
@@ -216,65 +384,893 @@ const docTemplate = `{
                 }
             }
         },
-        "/ui/jobs": {
+        "/api/loglevel": {
             "get": {
-                "description": "Get the jobs overview UI page",
+                "description": "Get the global log level and the levels of all subsystems",
                 "produces": [
-                    "text/html"
+                    "application/json"
                 ],
                 "tags": [
-                    "ui"
+                    "logging"
                 ],
-                "summary": "Get jobs UI page",
+                "summary": "Get log levels",
                 "responses": {
                     "200": {
-                        "description": "HTML page",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.logLevels"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the global log level or the level of a subsystem without restart",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "logging"
+                ],
+                "summary": "Change log level",
+                "parameters": [
+                    {
+                        "description": "Log level change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.logLevelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.logLevels"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "main.alert": {
-            "description": "Alert information from Alertmanager",
-            "type": "object",
-            "properties": {
-                "EndsAt": {
-                    "description": "@Description Time when the alert ended",
-                    "type": "string"
-                },
-                "annotations": {
-                    "description": "@Description Key-value pairs of alert annotations",
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
-                },
-                "labels": {
-                    "description": "@Description Key-value pairs of alert labels",
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
+        },
+        "/api/pause": {
+            "get": {
+                "description": "Get whether the creation of all jobs is paused and why",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Get the global pause",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.pauseStatus"
+                        }
                     }
-                },
-                "startsAt": {
-                    "description": "@Description Time when the alert started firing",
-                    "type": "string"
                 }
-            }
-        },
-        "main.hookMessage": {
-            "description": "Webhook message received from Alertmanager",
-            "type": "object",
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Pause or resume the creation of all jobs. Alerts are still recorded while paused.\nThe pause is stored as annotation on the maintenance ConfigMap, so it applies to all instances.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Pause or resume",
+                "parameters": [
+                    {
+                        "description": "Pause or resume",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.pauseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.pauseStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Paused by the configuration",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/runs": {
+            "get": {
+                "description": "List the latest runs of jobs with their state and the outputs reported by the jobs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "runs"
+                ],
+                "summary": "List job runs",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.runRecord"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/workflows": {
+            "get": {
+                "description": "List running workflows and the latest finished ones with the state and outputs of their steps",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workflows"
+                ],
+                "summary": "List workflows",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.workflowStatus"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/assets/{path}": {
+            "get": {
+                "description": "Serve static assets like CSS and JavaScript files",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "assets"
+                ],
+                "summary": "Serve static assets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Asset path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/cloudevents": {
+            "post": {
+                "description": "Map a CloudEvent in binary or structured HTTP mode to alerts by the mapping of its type and process them like alerts from Alertmanager",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Process incoming CloudEvents",
+                "parameters": [
+                    {
+                        "description": "CloudEvent",
+                        "name": "event",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Create the jobs before answering, overrides webhook.synchronous",
+                        "name": "sync",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Jobs created, synchronous mode",
+                        "schema": {
+                            "$ref": "#/definitions/main.webhookResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Jobs queued",
+                        "schema": {
+                            "$ref": "#/definitions/main.webhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid event, status or no valid alert",
+                        "schema": {
+                            "$ref": "#/definitions/main.webhookError"
+                        }
+                    },
+                    "413": {
+                        "description": "Body or number of alerts exceeds the limits",
+                        "schema": {
+                            "$ref": "#/definitions/main.webhookError"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Get the health status of the OpenFero service",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Get health status",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/hooks/{source}": {
+            "post": {
+                "description": "Map a JSON payload to alerts by the mappings configured for the source and process them like alerts from Alertmanager",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Process events of a generic source",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the source",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Event payload",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Create the jobs before answering, overrides webhook.synchronous",
+                        "name": "sync",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Jobs created, synchronous mode",
+                        "schema": {
+                            "$ref": "#/definitions/main.webhookResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Jobs queued",
+                        "schema": {
+                            "$ref": "#/definitions/main.webhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body, status or no valid alert",
+                        "schema": {
+                            "$ref": "#/definitions/main.webhookError"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown source",
+                        "schema": {
+                            "$ref": "#/definitions/main.webhookError"
+                        }
+                    },
+                    "413": {
+                        "description": "Body or number of alerts exceeds the limits",
+                        "schema": {
+                            "$ref": "#/definitions/main.webhookError"
+                        }
+                    }
+                }
+            }
+        },
+        "/readiness": {
+            "get": {
+                "description": "Get the readiness status of the OpenFero service",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Get readiness status",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ui": {
+            "get": {
+                "description": "Get the main UI page",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "ui"
+                ],
+                "summary": "Get UI page",
+                "responses": {
+                    "200": {
+                        "description": "HTML page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ui/approvals": {
+            "get": {
+                "description": "Get the page to approve or reject jobs waiting for approval, without the users and comments of the decisions",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "ui"
+                ],
+                "summary": "Get approvals UI page",
+                "responses": {
+                    "200": {
+                        "description": "HTML page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ui/jobs": {
+            "get": {
+                "description": "Get the jobs overview UI page",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "ui"
+                ],
+                "summary": "Get jobs UI page",
+                "responses": {
+                    "200": {
+                        "description": "HTML page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ui/maintenance": {
+            "get": {
+                "description": "Get the page showing the global pause with a toggle and the maintenance windows",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "ui"
+                ],
+                "summary": "Get maintenance UI page",
+                "responses": {
+                    "200": {
+                        "description": "HTML page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ui/runs": {
+            "get": {
+                "description": "Get the page showing the latest runs of jobs and their outputs",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "ui"
+                ],
+                "summary": "Get runs UI page",
+                "responses": {
+                    "200": {
+                        "description": "HTML page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ui/workflows": {
+            "get": {
+                "description": "Get the page showing the state of workflows and their steps",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "ui"
+                ],
+                "summary": "Get workflows UI page",
+                "responses": {
+                    "200": {
+                        "description": "HTML page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "main.alert": {
+            "description": "Alert information from Alertmanager",
+            "type": "object",
+            "properties": {
+                "EndsAt": {
+                    "description": "@Description Time when the alert ended",
+                    "type": "string"
+                },
+                "annotations": {
+                    "description": "@Description Key-value pairs of alert annotations",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "fingerprint": {
+                    "description": "@Description Fingerprint identifying the alert",
+                    "type": "string"
+                },
+                "generatorURL": {
+                    "description": "@Description URL of the rule which generated the alert",
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "@Description Key-value pairs of alert labels",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "startsAt": {
+                    "description": "@Description Time when the alert started firing",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "@Description Status of the alert (firing/resolved)",
+                    "type": "string",
+                    "example": "firing"
+                }
+            }
+        },
+        "main.alertResult": {
+            "description": "What happened to a single alert of a webhook",
+            "type": "object",
+            "properties": {
+                "alertname": {
+                    "description": "@Description Alertname of the alert",
+                    "type": "string"
+                },
+                "approval": {
+                    "description": "@Description ID of the approval the job waits for",
+                    "type": "string"
+                },
+                "definition": {
+                    "description": "@Description Name of the ConfigMap of the matched definition, empty if none matched or it was not looked up",
+                    "type": "string"
+                },
+                "fingerprint": {
+                    "description": "@Description Fingerprint of the alert",
+                    "type": "string"
+                },
+                "job": {
+                    "description": "@Description Name of the created job, or of the job waiting for approval",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "@Description Reason why no job was created",
+                    "type": "string"
+                },
+                "result": {
+                    "description": "@Description Result of the alert",
+                    "type": "string",
+                    "example": "created"
+                },
+                "workflow": {
+                    "description": "@Description ID of the started workflow, or of the workflow waiting for approval",
+                    "type": "string"
+                }
+            }
+        },
+        "main.approval": {
+            "description": "Job waiting for approval, or the decision about it",
+            "type": "object",
+            "properties": {
+                "alert": {
+                    "description": "@Description Alert the job was rendered for, the first alert for jobs per group",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/main.alert"
+                        }
+                    ]
+                },
+                "alertCount": {
+                    "description": "@Description Number of alerts handled by the job",
+                    "type": "integer"
+                },
+                "alertname": {
+                    "description": "@Description Alertname of the definition",
+                    "type": "string"
+                },
+                "comment": {
+                    "description": "@Description Comment of the decision",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "description": "@Description Time when the job was parked",
+                    "type": "string"
+                },
+                "decidedAt": {
+                    "description": "@Description Time of the decision or expiry",
+                    "type": "string"
+                },
+                "decidedBy": {
+                    "description": "@Description User who approved or rejected the job",
+                    "type": "string"
+                },
+                "definition": {
+                    "description": "@Description Name of the ConfigMap of the job definition",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "@Description Error of the last attempt to create the job after approval",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "@Description Time after which a pending job is dropped",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "@Description ID of the approval",
+                    "type": "string"
+                },
+                "job": {
+                    "description": "@Description Name of the job created on approval",
+                    "type": "string"
+                },
+                "state": {
+                    "description": "@Description State of the approval",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/main.approvalState"
+                        }
+                    ],
+                    "example": "pending"
+                },
+                "workflow": {
+                    "description": "@Description ID of the workflow started on approval",
+                    "type": "string"
+                }
+            }
+        },
+        "main.approvalDecision": {
+            "description": "Decision about a pending job",
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "description": "@Description Reason of the decision, recorded with the approval",
+                    "type": "string"
+                }
+            }
+        },
+        "main.approvalState": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "approved",
+                "rejected",
+                "expired"
+            ],
+            "x-enum-varnames": [
+                "approvalPending",
+                "approvalApproved",
+                "approvalRejected",
+                "approvalExpired"
+            ]
+        },
+        "main.grafanaAlert": {
+            "description": "Alert information from Grafana unified alerting",
+            "type": "object",
+            "properties": {
+                "EndsAt": {
+                    "description": "@Description Time when the alert ended",
+                    "type": "string"
+                },
+                "annotations": {
+                    "description": "@Description Key-value pairs of alert annotations",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "dashboardURL": {
+                    "description": "@Description URL of the dashboard of the alert rule",
+                    "type": "string"
+                },
+                "fingerprint": {
+                    "description": "@Description Fingerprint identifying the alert",
+                    "type": "string"
+                },
+                "generatorURL": {
+                    "description": "@Description URL of the rule which generated the alert",
+                    "type": "string"
+                },
+                "imageURL": {
+                    "description": "@Description URL of a screenshot of the panel",
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "@Description Key-value pairs of alert labels",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "panelURL": {
+                    "description": "@Description URL of the panel of the alert rule",
+                    "type": "string"
+                },
+                "silenceURL": {
+                    "description": "@Description URL to silence the alert",
+                    "type": "string"
+                },
+                "startsAt": {
+                    "description": "@Description Time when the alert started firing",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "@Description Status of the alert (firing/resolved)",
+                    "type": "string",
+                    "example": "firing"
+                },
+                "valueString": {
+                    "description": "@Description Values of the alert rule as text",
+                    "type": "string"
+                },
+                "values": {
+                    "description": "@Description Values of the queries and expressions of the alert rule",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "main.grafanaMessage": {
+            "description": "Webhook message from Grafana unified alerting, an Alertmanager message with Grafana specific fields",
+            "type": "object",
+            "properties": {
+                "alerts": {
+                    "description": "@Description List of alerts in the group",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.grafanaAlert"
+                    }
+                },
+                "commonAnnotations": {
+                    "description": "@Description Annotations common across all alerts",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "commonLabels": {
+                    "description": "@Description Labels common across all alerts",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "externalURL": {
+                    "description": "@Description External URL to the Alertmanager",
+                    "type": "string"
+                },
+                "groupKey": {
+                    "description": "@Description Key used to group alerts",
+                    "type": "string"
+                },
+                "groupLabels": {
+                    "description": "@Description Labels common to all alerts in the group",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "message": {
+                    "description": "@Description Rendered message of the notification",
+                    "type": "string"
+                },
+                "orgId": {
+                    "description": "@Description ID of the Grafana organization",
+                    "type": "integer"
+                },
+                "receiver": {
+                    "description": "@Description Name of the receiver that handled the alert",
+                    "type": "string"
+                },
+                "state": {
+                    "description": "@Description State of the alert group (alerting/ok)",
+                    "type": "string",
+                    "example": "alerting"
+                },
+                "status": {
+                    "description": "@Description Status of the alert group (firing/resolved)",
+                    "type": "string",
+                    "example": "firing"
+                },
+                "title": {
+                    "description": "@Description Rendered title of the notification",
+                    "type": "string"
+                },
+                "truncatedAlerts": {
+                    "description": "@Description Number of alerts Alertmanager dropped from the message because of the max_alerts limit",
+                    "type": "integer"
+                },
+                "version": {
+                    "description": "@Description Version of the Alertmanager message",
+                    "type": "string"
+                }
+            }
+        },
+        "main.hookMessage": {
+            "description": "Webhook message received from Alertmanager",
+            "type": "object",
             "properties": {
                 "alerts": {
                     "description": "@Description List of alerts in the group",
@@ -321,11 +1317,305 @@ const docTemplate = `{
                     "type": "string",
                     "example": "firing"
                 },
+                "truncatedAlerts": {
+                    "description": "@Description Number of alerts Alertmanager dropped from the message because of the max_alerts limit",
+                    "type": "integer"
+                },
                 "version": {
                     "description": "@Description Version of the Alertmanager message",
                     "type": "string"
                 }
             }
+        },
+        "main.logLevelRequest": {
+            "description": "Request to change a log level",
+            "type": "object",
+            "properties": {
+                "level": {
+                    "description": "@Description New log level, an empty level resets a subsystem to the global level and is rejected for the global level",
+                    "type": "string",
+                    "example": "debug"
+                },
+                "subsystem": {
+                    "description": "@Description Subsystem to change, the global level is changed if empty",
+                    "type": "string"
+                }
+            }
+        },
+        "main.logLevels": {
+            "description": "Log levels of OpenFero",
+            "type": "object",
+            "properties": {
+                "level": {
+                    "description": "@Description Global log level",
+                    "type": "string",
+                    "example": "info"
+                },
+                "subsystems": {
+                    "description": "@Description Log levels of subsystems which override the global level",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "main.pauseRequest": {
+            "description": "Request to pause or resume the job creation",
+            "type": "object",
+            "properties": {
+                "paused": {
+                    "description": "@Description true pauses, false resumes the job creation",
+                    "type": "boolean"
+                },
+                "reason": {
+                    "description": "@Description Reason of the pause, shown on the skipped alerts",
+                    "type": "string"
+                }
+            }
+        },
+        "main.pauseStatus": {
+            "description": "Global pause of the job creation",
+            "type": "object",
+            "properties": {
+                "by": {
+                    "description": "@Description User who paused OpenFero via the API",
+                    "type": "string"
+                },
+                "paused": {
+                    "description": "@Description Whether no jobs are created",
+                    "type": "boolean"
+                },
+                "reason": {
+                    "description": "@Description Reason of the pause",
+                    "type": "string"
+                },
+                "since": {
+                    "description": "@Description Time when OpenFero was paused via the API",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "@Description config if paused by maintenance.paused, configMap if paused by the annotation openfero/paused of the maintenance ConfigMap",
+                    "type": "string"
+                }
+            }
+        },
+        "main.runRecord": {
+            "description": "Run of a job created by OpenFero with its result and outputs",
+            "type": "object",
+            "properties": {
+                "alertname": {
+                    "description": "@Description Alertname of the definition",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "description": "@Description Time when the job was created",
+                    "type": "string"
+                },
+                "definition": {
+                    "description": "@Description Name of the ConfigMap of the definition",
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "description": "@Description Time when the job finished",
+                    "type": "string"
+                },
+                "job": {
+                    "description": "@Description Name of the job",
+                    "type": "string"
+                },
+                "namespace": {
+                    "description": "@Description Namespace of the job",
+                    "type": "string"
+                },
+                "outputs": {
+                    "description": "@Description Outputs reported by the job in its termination message or OPENFERO_OUTPUT log lines",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "reason": {
+                    "description": "@Description Reason of a failure",
+                    "type": "string"
+                },
+                "state": {
+                    "description": "@Description State of the run",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/main.runState"
+                        }
+                    ],
+                    "example": "succeeded"
+                },
+                "trigger": {
+                    "description": "@Description What caused the job",
+                    "type": "string",
+                    "example": "alert"
+                }
+            }
+        },
+        "main.runState": {
+            "type": "string",
+            "enum": [
+                "running",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "runRunning",
+                "runSucceeded",
+                "runFailed"
+            ]
+        },
+        "main.stepState": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "succeeded",
+                "failed",
+                "skipped"
+            ],
+            "x-enum-varnames": [
+                "stepPending",
+                "stepRunning",
+                "stepSucceeded",
+                "stepFailed",
+                "stepSkipped"
+            ]
+        },
+        "main.stepStatus": {
+            "description": "Status of a step of a workflow",
+            "type": "object",
+            "properties": {
+                "job": {
+                    "description": "@Description Name of the job of the step",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "@Description Name of the step",
+                    "type": "string"
+                },
+                "outputs": {
+                    "description": "@Description Outputs written by the step to its termination message",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "reason": {
+                    "description": "@Description Reason of a failure",
+                    "type": "string"
+                },
+                "state": {
+                    "description": "@Description State of the step",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/main.stepState"
+                        }
+                    ],
+                    "example": "pending"
+                }
+            }
+        },
+        "main.webhookError": {
+            "description": "Error of a rejected webhook request",
+            "type": "object",
+            "properties": {
+                "details": {
+                    "description": "@Description Problems of the single alerts",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "error": {
+                    "description": "@Description Reason of the rejection",
+                    "type": "string",
+                    "example": "invalid alerts"
+                }
+            }
+        },
+        "main.webhookResponse": {
+            "description": "Response to a webhook with the result of every alert",
+            "type": "object",
+            "properties": {
+                "alerts": {
+                    "description": "@Description Results of the alerts in the order of the notification",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.alertResult"
+                    }
+                },
+                "synchronous": {
+                    "description": "@Description Whether the jobs were created before answering",
+                    "type": "boolean"
+                }
+            }
+        },
+        "main.workflowState": {
+            "type": "string",
+            "enum": [
+                "running",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "workflowRunning",
+                "workflowSucceeded",
+                "workflowFailed"
+            ]
+        },
+        "main.workflowStatus": {
+            "description": "Status of a workflow and its steps",
+            "type": "object",
+            "properties": {
+                "alertname": {
+                    "description": "@Description Alertname of the definition",
+                    "type": "string"
+                },
+                "definition": {
+                    "description": "@Description Name of the ConfigMap of the definition",
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "description": "@Description Time when the last step finished",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "@Description ID of the workflow, set as annotation openfero/workflow on its jobs",
+                    "type": "string"
+                },
+                "startedAt": {
+                    "description": "@Description Time when the workflow started",
+                    "type": "string"
+                },
+                "state": {
+                    "description": "@Description Overall state, failed if any step failed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/main.workflowState"
+                        }
+                    ],
+                    "example": "running"
+                },
+                "steps": {
+                    "description": "@Description Steps in the order of the definition",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.stepStatus"
+                    }
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Bearer token of the administrative API, e.g. \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Field keys used to correlate the log lines of a single alert
+const (
+	RequestIDKey   = "request_id"
+	GroupKeyKey    = "group_key"
+	AlertnameKey   = "alertname"
+	FingerprintKey = "fingerprint"
+	StatusKey      = "status"
+	JobKey         = "job"
+	TraceIDKey     = "trace_id"
+)
+
+type fieldsKey struct{}
+
+type subsystemKey struct{}
+
+// WithSubsystem returns a copy of ctx whose logger uses the level of the given subsystem
+func WithSubsystem(ctx context.Context, subsystem string) context.Context {
+	return context.WithValue(ctx, subsystemKey{}, subsystem)
+}
+
+// WithFields returns a copy of ctx carrying the given fields in addition to the fields already stored in ctx
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	existing := contextFields(ctx)
+	combined := make([]zap.Field, 0, len(existing)+len(fields))
+	combined = append(combined, existing...)
+	combined = append(combined, fields...)
+	return context.WithValue(ctx, fieldsKey{}, combined)
+}
+
+// FromContext returns a logger with all fields stored in ctx and the trace ID of the current span
+func FromContext(ctx context.Context) *zap.Logger {
+	logger := zapLog.WithOptions(zap.AddCallerSkip(-1))
+	if subsystem, ok := ctx.Value(subsystemKey{}).(string); ok {
+		logger = Subsystem(subsystem)
+	}
+	fields := contextFields(ctx)
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		fields = append(fields[:len(fields):len(fields)], zap.String(TraceIDKey, spanContext.TraceID().String()))
+	}
+	if len(fields) == 0 {
+		return logger
+	}
+	return logger.With(fields...)
+}
+
+func contextFields(ctx context.Context) []zap.Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	return fields
+}
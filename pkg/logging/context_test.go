@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func observe(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+	core, logs := observer.New(zapcore.DebugLevel)
+	previous := zapLog
+	zapLog = zap.New(core)
+	t.Cleanup(func() { zapLog = previous })
+	return logs
+}
+
+func TestFromContextCarriesFields(t *testing.T) {
+	logs := observe(t)
+
+	ctx := WithFields(context.Background(), zap.String(RequestIDKey, "abc"))
+	alertCtx := WithFields(ctx, zap.String(AlertnameKey, "TestAlert"))
+	otherCtx := WithFields(ctx, zap.String(AlertnameKey, "OtherAlert"))
+
+	FromContext(alertCtx).Info("alert")
+	FromContext(otherCtx).Info("other")
+	FromContext(ctx).Info("request")
+
+	entries := logs.All()
+	if len(entries) != 3 {
+		t.Fatalf("got %d log entries, want 3", len(entries))
+	}
+	expected := []map[string]interface{}{
+		{RequestIDKey: "abc", AlertnameKey: "TestAlert"},
+		{RequestIDKey: "abc", AlertnameKey: "OtherAlert"},
+		{RequestIDKey: "abc"},
+	}
+	for i, entry := range entries {
+		fields := entry.ContextMap()
+		if len(fields) != len(expected[i]) {
+			t.Errorf("entry %d has fields %v, want %v", i, fields, expected[i])
+		}
+		for key, value := range expected[i] {
+			if fields[key] != value {
+				t.Errorf("entry %d field %s = %v, want %v", i, key, fields[key], value)
+			}
+		}
+	}
+}
+
+func TestFromContextAddsTraceID(t *testing.T) {
+	logs := observe(t)
+
+	traceID := trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{1}})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+
+	FromContext(ctx).Info("traced")
+
+	if got := logs.All()[0].ContextMap()[TraceIDKey]; got != traceID.String() {
+		t.Errorf("trace_id field = %v, want %s", got, traceID)
+	}
+}
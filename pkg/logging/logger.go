@@ -1,28 +1,214 @@
 package logging
 
 import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-var zapLog *zap.Logger
+// Supported log formats
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// Subsystems which can be configured with their own log level
+const (
+	SubsystemWebhook  = "webhook"
+	SubsystemInformer = "informer"
+	SubsystemJobs     = "jobs"
+)
+
+// Subsystems lists all subsystems which support their own log level
+var Subsystems = []string{SubsystemWebhook, SubsystemInformer, SubsystemJobs}
+
+// Config configures the logger
+type Config struct {
+	// Level is the global log level: debug, info, warn or error
+	Level string
+	// Format is the log encoding: json or console
+	Format string
+	// Stacktrace adds stack traces to error logs
+	Stacktrace bool
+	// SubsystemLevels overrides the global level for single subsystems
+	SubsystemLevels map[string]string
+}
+
+var (
+	// base logs every level, the loggers derived from it filter by the configured levels
+	base   *zap.Logger
+	zapLog *zap.Logger
+
+	globalLevel = zap.NewAtomicLevelAt(zap.InfoLevel)
+
+	subsystemMu      sync.RWMutex
+	subsystemLevels  = make(map[string]zap.AtomicLevel)
+	subsystemLoggers = make(map[string]*zap.Logger)
+)
 
 // SetConfig sets the logger configuration
-func SetConfig(config zap.Config) error {
-	var err error
+func SetConfig(config Config) error {
+	level, err := ParseLevel(config.Level)
+	if err != nil {
+		return err
+	}
+
 	encoderConfig := zap.NewProductionEncoderConfig()
-	encoderConfig.StacktraceKey = "" // to hide stacktrace info
 	encoderConfig.TimeKey = "timestamp"
 	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder // format timestamp to ISO8601
-	config.EncoderConfig = encoderConfig
-	config.Encoding = "json" // set encoding to JSON
-	zapLog, err = config.Build(zap.AddCallerSkip(1))
+	if !config.Stacktrace {
+		encoderConfig.StacktraceKey = "" // to hide stacktrace info
+	}
+
+	var encoder zapcore.Encoder
+	switch strings.ToLower(config.Format) {
+	case "", FormatJSON:
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case FormatConsole:
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		return fmt.Errorf("invalid log format specified: %s", config.Format)
+	}
+
+	subsystems := make(map[string]zapcore.Level, len(config.SubsystemLevels))
+	for subsystem, subsystemLevel := range config.SubsystemLevels {
+		if !isSubsystem(subsystem) {
+			return fmt.Errorf("invalid log subsystem specified: %s", subsystem)
+		}
+		parsed, err := ParseLevel(subsystemLevel)
+		if err != nil {
+			return err
+		}
+		subsystems[subsystem] = parsed
+	}
+
+	core := zapcore.NewCore(encoder, zapcore.Lock(os.Stderr), zapcore.DebugLevel)
+	options := []zap.Option{zap.AddCaller(), zap.ErrorOutput(zapcore.Lock(os.Stderr))}
+	if config.Stacktrace {
+		options = append(options, zap.AddStacktrace(zapcore.ErrorLevel))
+	}
+
+	subsystemMu.Lock()
+	defer subsystemMu.Unlock()
+
+	globalLevel.SetLevel(level)
+	base = zap.New(core, options...)
+	zapLog = base.WithOptions(zap.IncreaseLevel(globalLevel), zap.AddCallerSkip(1))
+
+	subsystemLevels = make(map[string]zap.AtomicLevel, len(subsystems))
+	for subsystem, subsystemLevel := range subsystems {
+		subsystemLevels[subsystem] = zap.NewAtomicLevelAt(subsystemLevel)
+	}
+	subsystemLoggers = make(map[string]*zap.Logger)
+	return nil
+}
+
+// ParseLevel parses a log level name
+func ParseLevel(level string) (zapcore.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return zapcore.DebugLevel, nil
+	case "", "info":
+		return zapcore.InfoLevel, nil
+	case "warn", "warning":
+		return zapcore.WarnLevel, nil
+	case "error":
+		return zapcore.ErrorLevel, nil
+	default:
+		return zapcore.InfoLevel, fmt.Errorf("invalid log level specified: %s", level)
+	}
+}
+
+// SetLevel changes the global log level at runtime
+func SetLevel(level string) error {
+	parsed, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	globalLevel.SetLevel(parsed)
+	return nil
+}
+
+// SetSubsystemLevel changes the log level of a subsystem at runtime.
+// An empty level removes the override, so the subsystem follows the global level again.
+func SetSubsystemLevel(subsystem string, level string) error {
+	if !isSubsystem(subsystem) {
+		return fmt.Errorf("invalid log subsystem specified: %s", subsystem)
+	}
+
+	subsystemMu.Lock()
+	defer subsystemMu.Unlock()
+
+	if level == "" {
+		delete(subsystemLevels, subsystem)
+		return nil
+	}
+	parsed, err := ParseLevel(level)
 	if err != nil {
 		return err
 	}
+	if atomicLevel, ok := subsystemLevels[subsystem]; ok {
+		atomicLevel.SetLevel(parsed)
+		return nil
+	}
+	subsystemLevels[subsystem] = zap.NewAtomicLevelAt(parsed)
 	return nil
 }
 
+// Levels returns the global log level and the levels of all subsystems with an override
+func Levels() (string, map[string]string) {
+	subsystemMu.RLock()
+	defer subsystemMu.RUnlock()
+
+	levels := make(map[string]string, len(subsystemLevels))
+	for subsystem, level := range subsystemLevels {
+		levels[subsystem] = level.String()
+	}
+	return globalLevel.String(), levels
+}
+
+// Subsystem returns the logger of a subsystem, which uses the subsystem level if one is set
+func Subsystem(subsystem string) *zap.Logger {
+	subsystemMu.RLock()
+	logger, ok := subsystemLoggers[subsystem]
+	subsystemMu.RUnlock()
+	if ok {
+		return logger
+	}
+
+	subsystemMu.Lock()
+	defer subsystemMu.Unlock()
+	if logger, ok := subsystemLoggers[subsystem]; ok {
+		return logger
+	}
+	logger = base.Named(subsystem).WithOptions(zap.IncreaseLevel(subsystemEnabler(subsystem)))
+	subsystemLoggers[subsystem] = logger
+	return logger
+}
+
+// subsystemEnabler checks the subsystem level on every log call, so level changes apply immediately
+func subsystemEnabler(subsystem string) zapcore.LevelEnabler {
+	return zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+		subsystemMu.RLock()
+		atomicLevel, ok := subsystemLevels[subsystem]
+		subsystemMu.RUnlock()
+		if ok {
+			return atomicLevel.Enabled(level)
+		}
+		return globalLevel.Enabled(level)
+	})
+}
+
+func isSubsystem(subsystem string) bool {
+	return slices.Contains(Subsystems, subsystem)
+}
+
 func Info(message string, fields ...zap.Field) {
 	zapLog.Info(message, fields...)
 }
@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observeLevels configures the logger and redirects its output to an observer
+func observeLevels(t *testing.T, config Config) *observer.ObservedLogs {
+	t.Helper()
+	if err := SetConfig(config); err != nil {
+		t.Fatalf("SetConfig() returned error: %v", err)
+	}
+	core, logs := observer.New(zapcore.DebugLevel)
+	base = zap.New(core)
+	zapLog = base.WithOptions(zap.IncreaseLevel(globalLevel))
+	subsystemLoggers = make(map[string]*zap.Logger)
+	return logs
+}
+
+func TestSetConfigValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{name: "Defaults", config: Config{}},
+		{name: "Console format with warn level", config: Config{Level: "warn", Format: FormatConsole}},
+		{name: "Error level with stack traces", config: Config{Level: "error", Stacktrace: true}},
+		{name: "Subsystem level", config: Config{SubsystemLevels: map[string]string{SubsystemWebhook: "debug"}}},
+		{name: "Invalid level", config: Config{Level: "verbose"}, wantErr: true},
+		{name: "Invalid format", config: Config{Format: "xml"}, wantErr: true},
+		{name: "Invalid subsystem", config: Config{SubsystemLevels: map[string]string{"database": "debug"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetConfig(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetLevelAtRuntime(t *testing.T) {
+	logs := observeLevels(t, Config{Level: "info"})
+
+	Debug("hidden")
+	if err := SetLevel("debug"); err != nil {
+		t.Fatalf("SetLevel() returned error: %v", err)
+	}
+	Debug("visible")
+	if err := SetLevel("error"); err != nil {
+		t.Fatalf("SetLevel() returned error: %v", err)
+	}
+	Warn("hidden")
+
+	if logs.Len() != 1 || logs.All()[0].Message != "visible" {
+		t.Errorf("unexpected log entries: %v", logs.All())
+	}
+}
+
+func TestSubsystemLevels(t *testing.T) {
+	logs := observeLevels(t, Config{Level: "info", SubsystemLevels: map[string]string{SubsystemWebhook: "debug"}})
+
+	Subsystem(SubsystemWebhook).Debug("webhook debug")
+	Subsystem(SubsystemJobs).Debug("jobs debug hidden")
+
+	if err := SetSubsystemLevel(SubsystemJobs, "debug"); err != nil {
+		t.Fatalf("SetSubsystemLevel() returned error: %v", err)
+	}
+	Subsystem(SubsystemJobs).Debug("jobs debug")
+
+	if err := SetSubsystemLevel(SubsystemWebhook, ""); err != nil {
+		t.Fatalf("SetSubsystemLevel() returned error: %v", err)
+	}
+	Subsystem(SubsystemWebhook).Debug("webhook debug hidden")
+
+	var messages []string
+	for _, entry := range logs.All() {
+		messages = append(messages, entry.Message)
+	}
+	if len(messages) != 2 || messages[0] != "webhook debug" || messages[1] != "jobs debug" {
+		t.Errorf("unexpected log messages: %v", messages)
+	}
+
+	level, subsystems := Levels()
+	if level != "info" || len(subsystems) != 1 || subsystems[SubsystemJobs] != "debug" {
+		t.Errorf("Levels() = %s, %v", level, subsystems)
+	}
+
+	if err := SetSubsystemLevel("database", "debug"); err == nil {
+		t.Error("SetSubsystemLevel() accepted an unknown subsystem")
+	}
+}
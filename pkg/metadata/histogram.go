@@ -0,0 +1,130 @@
+package metadata
+
+import (
+	"math"
+	metrics "runtime/metrics"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// maxFallbackBuckets limits the number of buckets for histograms with an unknown unit
+const maxFallbackBuckets = 20
+
+// runtimeHistogramCollector exports a runtime/metrics Float64Histogram as a Prometheus histogram.
+// The runtime uses a large number of fine grained buckets, so they are folded into a smaller
+// set of Prometheus buckets which are chosen based on the unit of the metric.
+type runtimeHistogramCollector struct {
+	name    string
+	desc    *prometheus.Desc
+	buckets []float64
+}
+
+// newRuntimeHistogramCollector creates a collector for the given runtime histogram metric
+func newRuntimeHistogramCollector(metric metrics.Description) *runtimeHistogramCollector {
+	opts := getMetricsOptions(metric)
+	sample := []metrics.Sample{{Name: metric.Name}}
+	metrics.Read(sample)
+
+	var runtimeBuckets []float64
+	if sample[0].Value.Kind() == metrics.KindFloat64Histogram {
+		runtimeBuckets = sample[0].Value.Float64Histogram().Buckets
+	}
+
+	return &runtimeHistogramCollector{
+		name:    metric.Name,
+		desc:    prometheus.NewDesc(prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name), opts.Help, nil, nil),
+		buckets: prometheusBuckets(metric.Name, runtimeBuckets),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *runtimeHistogramCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector
+func (c *runtimeHistogramCollector) Collect(ch chan<- prometheus.Metric) {
+	sample := []metrics.Sample{{Name: c.name}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindFloat64Histogram {
+		return
+	}
+
+	count, sum, buckets := convertHistogram(sample[0].Value.Float64Histogram(), c.buckets)
+	ch <- prometheus.MustNewConstHistogram(c.desc, count, sum, buckets)
+}
+
+// prometheusBuckets returns the upper bounds of the Prometheus buckets used for a runtime histogram
+func prometheusBuckets(name string, runtimeBuckets []float64) []float64 {
+	switch {
+	case strings.HasSuffix(name, ":seconds"):
+		// 1µs up to ~4s
+		return prometheus.ExponentialBuckets(1e-6, 4, 12)
+	case strings.HasSuffix(name, ":bytes"):
+		// 8B up to 2MiB
+		return prometheus.ExponentialBuckets(8, 4, 10)
+	}
+
+	// Unknown unit, thin out the finite runtime bucket boundaries
+	var finite []float64
+	for _, bound := range runtimeBuckets {
+		if !math.IsInf(bound, 0) {
+			finite = append(finite, bound)
+		}
+	}
+	if len(finite) <= maxFallbackBuckets {
+		return finite
+	}
+	step := int(math.Ceil(float64(len(finite)) / maxFallbackBuckets))
+	buckets := make([]float64, 0, maxFallbackBuckets+1)
+	for i := step - 1; i < len(finite); i += step {
+		buckets = append(buckets, finite[i])
+	}
+	if buckets[len(buckets)-1] != finite[len(finite)-1] {
+		buckets = append(buckets, finite[len(finite)-1])
+	}
+	return buckets
+}
+
+// convertHistogram folds a runtime histogram into cumulative counts for the given upper bounds.
+// A runtime bucket is accounted to the first Prometheus bucket which contains its upper boundary,
+// the sum is estimated from the bucket midpoints as the runtime does not track it.
+func convertHistogram(hist *metrics.Float64Histogram, upperBounds []float64) (uint64, float64, map[float64]uint64) {
+	buckets := make(map[float64]uint64, len(upperBounds))
+	for _, bound := range upperBounds {
+		buckets[bound] = 0
+	}
+
+	var count uint64
+	var sum float64
+	for i, bucketCount := range hist.Counts {
+		if bucketCount == 0 {
+			continue
+		}
+		lower, upper := hist.Buckets[i], hist.Buckets[i+1]
+		count += bucketCount
+		sum += bucketMidpoint(lower, upper) * float64(bucketCount)
+
+		// Counts are cumulative, so every bucket with a bound above the runtime bucket gets them
+		for _, bound := range upperBounds {
+			if upper <= bound {
+				buckets[bound] += bucketCount
+			}
+		}
+	}
+	return count, sum, buckets
+}
+
+// bucketMidpoint returns the middle of a runtime bucket, falling back to the finite edge for open buckets
+func bucketMidpoint(lower, upper float64) float64 {
+	switch {
+	case math.IsInf(lower, -1) && math.IsInf(upper, 1):
+		return 0
+	case math.IsInf(lower, -1):
+		return upper
+	case math.IsInf(upper, 1):
+		return lower
+	}
+	return (lower + upper) / 2
+}
@@ -0,0 +1,95 @@
+package metadata
+
+import (
+	"math"
+	metrics "runtime/metrics"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestConvertHistogram(t *testing.T) {
+	hist := &metrics.Float64Histogram{
+		Counts:  []uint64{1, 2, 0, 3, 4},
+		Buckets: []float64{math.Inf(-1), 1, 2, 3, 4, math.Inf(1)},
+	}
+
+	count, sum, buckets := convertHistogram(hist, []float64{2, 4})
+
+	if count != 10 {
+		t.Errorf("convertHistogram() count = %d, want 10", count)
+	}
+	// 1*1 (open lower bucket) + 2*1.5 + 3*3.5 + 4*4 (open upper bucket)
+	if want := 1 + 3 + 10.5 + 16.0; sum != want {
+		t.Errorf("convertHistogram() sum = %v, want %v", sum, want)
+	}
+	if buckets[2] != 3 {
+		t.Errorf("convertHistogram() bucket le=2 = %d, want 3", buckets[2])
+	}
+	if buckets[4] != 6 {
+		t.Errorf("convertHistogram() bucket le=4 = %d, want 6", buckets[4])
+	}
+}
+
+func TestPrometheusBuckets(t *testing.T) {
+	tests := []struct {
+		name           string
+		metric         string
+		runtimeBuckets []float64
+		wantLen        int
+	}{
+		{
+			name:    "Seconds use exponential buckets",
+			metric:  "/gc/pauses:seconds",
+			wantLen: 12,
+		},
+		{
+			name:    "Bytes use exponential buckets",
+			metric:  "/gc/heap/allocs-by-size:bytes",
+			wantLen: 10,
+		},
+		{
+			name:           "Unknown unit keeps few finite runtime buckets",
+			metric:         "/example/values:items",
+			runtimeBuckets: []float64{math.Inf(-1), 1, 2, 3, math.Inf(1)},
+			wantLen:        3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buckets := prometheusBuckets(tt.metric, tt.runtimeBuckets)
+			if len(buckets) != tt.wantLen {
+				t.Errorf("prometheusBuckets(%q) returned %d buckets, want %d", tt.metric, len(buckets), tt.wantLen)
+			}
+		})
+	}
+
+	runtimeBuckets := make([]float64, 0, 101)
+	for i := 0; i <= 100; i++ {
+		runtimeBuckets = append(runtimeBuckets, float64(i))
+	}
+	buckets := prometheusBuckets("/example/values:items", runtimeBuckets)
+	if len(buckets) > maxFallbackBuckets+1 || buckets[len(buckets)-1] != 100 {
+		t.Errorf("prometheusBuckets() thinned buckets = %v", buckets)
+	}
+}
+
+func TestRuntimeHistogramCollector(t *testing.T) {
+	for _, meta := range metrics.All() {
+		if meta.Name != "/gc/pauses:seconds" {
+			continue
+		}
+		collector := newRuntimeHistogramCollector(meta)
+		registry := prometheus.NewPedanticRegistry()
+		if err := registry.Register(collector); err != nil {
+			t.Fatalf("registering collector failed: %v", err)
+		}
+		if count := testutil.CollectAndCount(collector, "gc_pauses_seconds"); count != 1 {
+			t.Errorf("collector exported %d metrics, want 1", count)
+		}
+		return
+	}
+	t.Skip("runtime does not provide /gc/pauses:seconds")
+}
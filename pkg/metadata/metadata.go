@@ -19,26 +19,121 @@ const (
 )
 
 var (
-	JobsCreatedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+	JobsCreatedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
 
 		Name: "openfero_jobs_created_total",
 
 		Help: "Total number of jobs created",
-	})
+	}, []string{"definition", "alertname"})
 
-	JobsSucceededTotal = prometheus.NewCounter(prometheus.CounterOpts{
+	JobsSucceededTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
 
 		Name: "openfero_jobs_succeeded_total",
 
 		Help: "Total number of jobs succeeded",
-	})
+	}, []string{"definition", "alertname"})
 
-	JobsFailedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+	JobsFailedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
 
 		Name: "openfero_jobs_failed_total",
 
 		Help: "Total number of jobs failed",
+	}, []string{"definition", "alertname", "reason"})
+
+	AlertsReceivedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+
+		Name: "openfero_alerts_received_total",
+
+		Help: "Total number of alerts received",
+	}, []string{"alertname", "status"})
+
+	AlertsWithoutDefinitionTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+
+		Name: "openfero_alerts_without_definition_total",
+
+		Help: "Total number of alerts for which no job definition was found",
+	}, []string{"alertname", "status"})
+
+	JobsSkippedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+
+		Name: "openfero_jobs_skipped_total",
+
+		Help: "Total number of jobs which were not created, partitioned by reason",
+	}, []string{"alertname", "definition", "reason"})
+
+	JobDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+
+		Name: "openfero_job_duration_seconds",
+
+		Help: "Duration of finished jobs from start to completion or failure",
+
+		Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+	}, []string{"definition", "status"})
+
+	AlertToJobStartSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+
+		Name: "openfero_alert_to_job_start_seconds",
+
+		Help: "Time between the alert startsAt timestamp and the creation of its job",
+
+		Buckets: prometheus.ExponentialBuckets(0.5, 2, 14),
+	}, []string{"alertname", "definition"})
+
+	JobQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+
+		Name: "openfero_job_queue_depth",
+
+		Help: "Number of alerts waiting for their job to be created",
 	})
+
+	JobCreationBacklog = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+
+		Name: "openfero_job_creation_backlog",
+
+		Help: "Number of jobs waiting for the rate limit of the job creation, partitioned by priority",
+	}, []string{"priority"})
+
+	JobCreationWaitSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+
+		Name: "openfero_job_creation_wait_seconds",
+
+		Help: "Time jobs waited for the rate limit of the job creation, partitioned by priority",
+
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 14),
+	}, []string{"priority"})
+
+	JobsShedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+
+		Name: "openfero_jobs_shed_total",
+
+		Help: "Total number of jobs dropped because the backlog of the job creation was full, partitioned by priority",
+	}, []string{"priority"})
+
+	NotificationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+
+		Name: "openfero_notifications_total",
+
+		Help: "Total number of notifications about finished jobs, partitioned by receiver and result",
+	}, []string{"receiver", "result"})
+
+	InformerSynced = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+
+		Name: "openfero_informer_synced",
+
+		Help: "Whether the informer cache has synced (1) or not (0)",
+	}, []string{"informer"})
+)
+
+// Reasons used for the reason label of JobsSkippedTotal
+const (
+	SkipReasonDisabled     = "disabled"
+	SkipReasonDeduplicated = "deduplicated"
+	SkipReasonPolicy       = "policy"
+	SkipReasonRejected     = "rejected"
+	SkipReasonExpired      = "expired"
+	SkipReasonPaused       = "paused"
+	SkipReasonMaintenance  = "maintenance"
+	SkipReasonShed         = "shed"
 )
 
 // Function to get metrics values from runtime/metrics package as float64
@@ -55,7 +150,7 @@ func GetSingleMetricFloat(metricName string) float64 {
 }
 
 // function to return differemt sample values as float 64
-// curently it handles single values, in future it will handle histograms
+// histograms are not handled here, they are exported by the runtimeHistogramCollector
 func getFloat64(sample metrics.Sample) float64 {
 	var floatVal float64
 	// Handle each sample.
@@ -67,8 +162,7 @@ func getFloat64(sample metrics.Sample) float64 {
 	case metrics.KindBad:
 		log.Error("bug in runtime/metrics package!")
 	case metrics.KindFloat64Histogram:
-		// Handle histogram kind if needed
-		floatVal = 0.0 // Placeholder, update with actual logic if needed
+		log.Error(fmt.Sprintf("%s: histogram metrics can not be read as a single value", sample.Name))
 	default:
 		log.Error(fmt.Sprintf("%s: unexpected metric Kind: %v", sample.Name, sample.Value.Kind()))
 	}
@@ -96,13 +190,27 @@ func AddMetricsToPrometheusRegistry() {
 	prometheus.MustRegister(JobsCreatedTotal)
 	prometheus.MustRegister(JobsSucceededTotal)
 	prometheus.MustRegister(JobsFailedTotal)
+	prometheus.MustRegister(AlertsReceivedTotal)
+	prometheus.MustRegister(AlertsWithoutDefinitionTotal)
+	prometheus.MustRegister(JobsSkippedTotal)
+	prometheus.MustRegister(JobDurationSeconds)
+	prometheus.MustRegister(AlertToJobStartSeconds)
+	prometheus.MustRegister(JobQueueDepth)
+	prometheus.MustRegister(JobCreationBacklog)
+	prometheus.MustRegister(JobCreationWaitSeconds)
+	prometheus.MustRegister(JobsShedTotal)
+	prometheus.MustRegister(InformerSynced)
+	prometheus.MustRegister(NotificationsTotal)
 	// Get descriptions for all supported metrics.
 	metricsMeta := metrics.All()
 	// Register metrics and retrieve the values in prometheus client
 	for i := range metricsMeta {
 		meta := metricsMeta[i]
 		opts := getMetricsOptions(metricsMeta[i])
-		if meta.Cumulative {
+		if meta.Kind == metrics.KindFloat64Histogram {
+			// Register as a histogram
+			prometheus.MustRegister(newRuntimeHistogramCollector(meta))
+		} else if meta.Cumulative {
 			// Register as a counter
 			funcCounter := prometheus.NewCounterFunc(prometheus.CounterOpts(opts), func() float64 {
 				return GetSingleMetricFloat(meta.Name)
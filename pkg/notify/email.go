@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+)
+
+// sendEmail delivers the message as plain text mail via the smarthost of the settings
+func sendEmail(ctx context.Context, settings config.Email, message Message) error {
+	host, _, err := net.SplitHostPort(settings.Smarthost)
+	if err != nil {
+		return fmt.Errorf("invalid smarthost %s: %w", settings.Smarthost, err)
+	}
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", settings.Smarthost)
+	if err != nil {
+		return fmt.Errorf("error connecting to %s: %w", settings.Smarthost, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("error starting SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("error starting TLS: %w", err)
+		}
+	}
+	if settings.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", settings.Username, settings.Password, host)); err != nil {
+			return fmt.Errorf("error authenticating: %w", err)
+		}
+	}
+	if err := client.Mail(settings.From); err != nil {
+		return fmt.Errorf("error sending MAIL FROM: %w", err)
+	}
+	for _, to := range settings.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("error sending RCPT TO %s: %w", to, err)
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("error sending DATA: %w", err)
+	}
+	if _, err := writer.Write(emailBody(settings, message, time.Now())); err != nil {
+		return fmt.Errorf("error sending message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error sending message: %w", err)
+	}
+	return client.Quit()
+}
+
+// emailBody returns the headers and the text of the mail with CRLF line endings
+func emailBody(settings config.Email, message Message, date time.Time) []byte {
+	var body strings.Builder
+	headers := [][2]string{
+		{"From", settings.From},
+		{"To", strings.Join(settings.To, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", message.Title)},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=utf-8"},
+		{"Content-Transfer-Encoding", "8bit"},
+	}
+	for _, header := range headers {
+		fmt.Fprintf(&body, "%s: %s\r\n", header[0], header[1])
+	}
+	body.WriteString("\r\n")
+	text := strings.ReplaceAll(message.Text, "\r\n", "\n")
+	body.WriteString(strings.ReplaceAll(text, "\n", "\r\n"))
+	body.WriteString("\r\n")
+	return []byte(body.String())
+}
@@ -0,0 +1,192 @@
+// Package notify sends messages about finished jobs to chat webhooks, generic webhooks and email
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/OpenFero/openfero/pkg/config"
+)
+
+// Default templates of receivers without title or text
+const (
+	DefaultTitle = `[OpenFero] Remediation of {{ .Alertname }} {{ .Outcome }}`
+	DefaultText  = `Job {{ .Namespace }}/{{ .Job }} of definition {{ .Definition }} {{ .Outcome }}{{ if .Reason }}: {{ .Reason }}{{ end }}` +
+		`{{ range $key, $value := .Outputs }}` + "\n" + `{{ $key }}: {{ $value }}{{ end }}`
+)
+
+// Colors of the message by outcome
+const (
+	colorSucceeded = "#2EB886"
+	colorFailed    = "#A30200"
+)
+
+// maxErrorBody limits how much of an error response is included in the returned error
+const maxErrorBody = 512
+
+// Event is a finished job a notification is sent for, it is the data of the templates
+type Event struct {
+	Job        string `json:"job"`
+	Namespace  string `json:"namespace"`
+	Definition string `json:"definition,omitempty"`
+	Alertname  string `json:"alertname,omitempty"`
+	GroupKey   string `json:"groupKey,omitempty"`
+	// Outcome is succeeded or failed
+	Outcome string `json:"outcome"`
+	// Reason of a failure, e.g. DeadlineExceeded
+	Reason      string            `json:"reason,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Outputs reported by the job, e.g. {"message": "restarted 3 pods"}
+	Outputs map[string]string `json:"outputs,omitempty"`
+}
+
+// Message is a notification rendered by the templates of a receiver
+type Message struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// Render executes the templates of the receiver with the event
+func Render(receiver config.Receiver, event Event) (Message, error) {
+	title, err := render("title", receiver.Title, DefaultTitle, event)
+	if err != nil {
+		return Message{}, err
+	}
+	text, err := render("text", receiver.Text, DefaultText, event)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{Title: title, Text: text}, nil
+}
+
+func render(name string, text string, defaultText string, event Event) (string, error) {
+	if text == "" {
+		text = defaultText
+	}
+	tpl, err := config.ParseMessageTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+	var rendered strings.Builder
+	if err := tpl.Execute(&rendered, event); err != nil {
+		return "", fmt.Errorf("error executing %s template: %w", name, err)
+	}
+	return rendered.String(), nil
+}
+
+// Sender delivers notifications to receivers
+type Sender struct {
+	httpClient *http.Client
+}
+
+// New returns a sender using httpClient for webhooks
+func New(httpClient *http.Client) *Sender {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Sender{httpClient: httpClient}
+}
+
+// Send renders the notification of the event and delivers it to the receiver
+func (sender *Sender) Send(ctx context.Context, receiver config.Receiver, event Event) error {
+	message, err := Render(receiver, event)
+	if err != nil {
+		return err
+	}
+	switch receiver.Type {
+	case config.ReceiverSlack, config.ReceiverMattermost:
+		return sender.post(ctx, receiver.URL, slackPayload(message, event))
+	case config.ReceiverTeams:
+		return sender.post(ctx, receiver.URL, teamsPayload(message))
+	case config.ReceiverWebhook:
+		return sender.post(ctx, receiver.URL, webhookPayload{Message: message, Event: event})
+	case config.ReceiverEmail:
+		if receiver.Email == nil {
+			return fmt.Errorf("receiver of type %s without email settings", config.ReceiverEmail)
+		}
+		return sendEmail(ctx, *receiver.Email, message)
+	default:
+		return fmt.Errorf("unknown receiver type %s", receiver.Type)
+	}
+}
+
+// post sends the payload as JSON to an incoming webhook
+func (sender *Sender) post(ctx context.Context, url string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := sender.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBody))
+		return fmt.Errorf("webhook returned %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// slackAttachment is understood by the incoming webhooks of Slack and Mattermost
+type slackAttachment struct {
+	Fallback string `json:"fallback"`
+	Color    string `json:"color"`
+	Title    string `json:"title"`
+	Text     string `json:"text"`
+}
+
+type slackMessage struct {
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+func slackPayload(message Message, event Event) slackMessage {
+	color := colorSucceeded
+	if event.Outcome == config.OutcomeFailed {
+		color = colorFailed
+	}
+	return slackMessage{Attachments: []slackAttachment{{
+		Fallback: message.Title,
+		Color:    color,
+		Title:    message.Title,
+		Text:     message.Text,
+	}}}
+}
+
+// teamsPayload returns an Adaptive Card as accepted by Teams incoming webhooks and workflows
+func teamsPayload(message Message) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []interface{}{map[string]interface{}{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]interface{}{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body": []interface{}{
+					map[string]interface{}{"type": "TextBlock", "text": message.Title, "weight": "Bolder", "size": "Medium", "wrap": true},
+					map[string]interface{}{"type": "TextBlock", "text": message.Text, "wrap": true},
+				},
+			},
+		}},
+	}
+}
+
+// webhookPayload is the body sent to generic webhooks, the rendered message together with the event
+type webhookPayload struct {
+	Message
+	Event
+}
@@ -0,0 +1,248 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+)
+
+var testEvent = Event{
+	Job:        "quota-abc12",
+	Namespace:  "default",
+	Definition: "openfero-kubequotaalmostfull-firing",
+	Alertname:  "KubeQuotaAlmostFull",
+	Outcome:    config.OutcomeFailed,
+	Reason:     "DeadlineExceeded",
+	Labels:     map[string]string{"namespace": "shop"},
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		receiver config.Receiver
+		outputs  map[string]string
+		want     Message
+	}{
+		{
+			name: "Default templates",
+			want: Message{
+				Title: "[OpenFero] Remediation of KubeQuotaAlmostFull failed",
+				Text:  "Job default/quota-abc12 of definition openfero-kubequotaalmostfull-firing failed: DeadlineExceeded",
+			},
+		},
+		{
+			name:     "Custom templates",
+			receiver: config.Receiver{Title: "{{ .Alertname }} in {{ .Labels.namespace }}", Text: "{{ .Job }} {{ .Labels.missing }}"},
+			want:     Message{Title: "KubeQuotaAlmostFull in shop", Text: "quota-abc12 "},
+		},
+		{
+			name:    "Outputs",
+			outputs: map[string]string{"message": "restarted 3 pods", "freed": "12GB"},
+			want: Message{
+				Title: "[OpenFero] Remediation of KubeQuotaAlmostFull failed",
+				Text:  "Job default/quota-abc12 of definition openfero-kubequotaalmostfull-firing failed: DeadlineExceeded\nfreed: 12GB\nmessage: restarted 3 pods",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := testEvent
+			event.Outputs = tt.outputs
+			got, err := Render(tt.receiver, event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendWebhooks(t *testing.T) {
+	tests := []struct {
+		name         string
+		receiverType string
+		status       int
+		check        func(t *testing.T, body map[string]interface{})
+		wantErr      bool
+	}{
+		{
+			name:         "Slack",
+			receiverType: config.ReceiverSlack,
+			status:       http.StatusOK,
+			check: func(t *testing.T, body map[string]interface{}) {
+				attachment := body["attachments"].([]interface{})[0].(map[string]interface{})
+				if attachment["color"] != colorFailed || attachment["title"] != "[OpenFero] Remediation of KubeQuotaAlmostFull failed" {
+					t.Errorf("unexpected attachment: %v", attachment)
+				}
+			},
+		},
+		{
+			name:         "Mattermost",
+			receiverType: config.ReceiverMattermost,
+			status:       http.StatusOK,
+			check: func(t *testing.T, body map[string]interface{}) {
+				if len(body["attachments"].([]interface{})) != 1 {
+					t.Errorf("unexpected body: %v", body)
+				}
+			},
+		},
+		{
+			name:         "Teams",
+			receiverType: config.ReceiverTeams,
+			status:       http.StatusAccepted,
+			check: func(t *testing.T, body map[string]interface{}) {
+				attachment := body["attachments"].([]interface{})[0].(map[string]interface{})
+				if body["type"] != "message" || attachment["contentType"] != "application/vnd.microsoft.card.adaptive" {
+					t.Errorf("unexpected body: %v", body)
+				}
+			},
+		},
+		{
+			name:         "Generic webhook",
+			receiverType: config.ReceiverWebhook,
+			status:       http.StatusNoContent,
+			check: func(t *testing.T, body map[string]interface{}) {
+				if body["job"] != "quota-abc12" || body["outcome"] != "failed" || body["reason"] != "DeadlineExceeded" || body["title"] == "" || body["text"] == "" {
+					t.Errorf("unexpected body: %v", body)
+				}
+			},
+		},
+		{
+			name:         "Error response",
+			receiverType: config.ReceiverWebhook,
+			status:       http.StatusInternalServerError,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("invalid JSON: %v", err)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := New(nil).Send(context.Background(), config.Receiver{Type: tt.receiverType, URL: server.URL}, testEvent)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.check != nil {
+				tt.check(t, body)
+			}
+		})
+	}
+}
+
+// serveSMTP answers a single SMTP session on the listener and returns the received message
+func serveSMTP(t *testing.T, listener net.Listener) <-chan string {
+	t.Helper()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+
+		reply("220 localhost ESMTP")
+		var data strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"):
+				reply("250-localhost")
+				reply("250 8BITMIME")
+			case strings.HasPrefix(command, "MAIL FROM"), strings.HasPrefix(command, "RCPT TO"):
+				data.WriteString(strings.TrimSpace(line) + "\n")
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 Bye")
+				received <- data.String()
+				return
+			default:
+				reply("502 Command not implemented")
+			}
+		}
+	}()
+	return received
+}
+
+func TestSendEmail(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	received := serveSMTP(t, listener)
+
+	receiver := config.Receiver{
+		Type: config.ReceiverEmail,
+		Email: &config.Email{
+			Smarthost: listener.Addr().String(),
+			From:      "openfero@example.com",
+			To:        []string{"sre@example.com", "oncall@example.com"},
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := New(nil).Send(ctx, receiver, testEvent); err != nil {
+		t.Fatal(err)
+	}
+
+	var mail string
+	select {
+	case mail = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SMTP server did not receive a message")
+	}
+	for _, want := range []string{
+		"MAIL FROM:<openfero@example.com>",
+		"RCPT TO:<sre@example.com>",
+		"RCPT TO:<oncall@example.com>",
+		"Subject: [OpenFero] Remediation of KubeQuotaAlmostFull failed\r\n",
+		"To: sre@example.com, oncall@example.com\r\n",
+		"\r\n\r\nJob default/quota-abc12 of definition openfero-kubequotaalmostfull-firing failed: DeadlineExceeded\r\n",
+	} {
+		if !strings.Contains(mail, want) {
+			t.Errorf("mail does not contain %q:\n%s", want, mail)
+		}
+	}
+}
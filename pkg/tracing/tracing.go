@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Supported span exporters
+const (
+	ExporterNone     = "none"
+	ExporterOTLPGRPC = "otlpgrpc"
+	ExporterOTLPHTTP = "otlphttp"
+)
+
+const serviceName = "openfero"
+
+// Config holds the tracing configuration
+type Config struct {
+	// Exporter is one of none, otlpgrpc or otlphttp
+	Exporter string
+	// Endpoint of the OTLP collector, e.g. localhost:4317. If empty the OTEL_EXPORTER_OTLP_* environment variables are used
+	Endpoint string
+	// Insecure disables TLS for the connection to the collector
+	Insecure bool
+	// SampleRatio is the ratio of traces sampled when no parent decision exists
+	SampleRatio float64
+}
+
+// ShutdownFunc flushes and stops the tracer provider
+type ShutdownFunc func(context.Context) error
+
+// Init configures the global tracer provider and propagator
+func Init(ctx context.Context, cfg Config, version string) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		log.Debug("Tracing is disabled")
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("could not create tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	log.Info("Tracing enabled", zap.String("exporter", cfg.Exporter), zap.String("endpoint", cfg.Endpoint))
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(cfg.Exporter) {
+	case "", ExporterNone:
+		return nil, nil
+	case ExporterOTLPGRPC:
+		var opts []otlptracegrpc.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case ExporterOTLPHTTP:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("invalid tracing exporter specified: %s", cfg.Exporter)
+	}
+}
+
+// Tracer returns the OpenFero tracer of the global tracer provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(metadata.OtelScope)
+}
+
+// Traceparent returns the W3C traceparent header value of the span in the context.
+// It returns an empty string if the context holds no valid span.
+func Traceparent(ctx context.Context) string {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ""
+	}
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+)
+
+func TestMain(m *testing.M) {
+	if err := log.SetConfig(log.Config{Level: "debug"}); err != nil {
+		panic(err)
+	}
+	m.Run()
+}
+
+func TestInitExportsToCollector(t *testing.T) {
+	// In-process OTLP/HTTP collector counting received trace exports
+	var exports atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/v1/traces" {
+			exports.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	shutdown, err := Init(context.Background(), Config{
+		Exporter:    ExporterOTLPHTTP,
+		Endpoint:    strings.TrimPrefix(collector.URL, "http://"),
+		Insecure:    true,
+		SampleRatio: 1,
+	}, "test")
+	if err != nil {
+		t.Fatalf("Init() returned error: %v", err)
+	}
+
+	ctx, span := Tracer().Start(context.Background(), "test")
+	traceparent := Traceparent(ctx)
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown returned error: %v", err)
+	}
+	if exports.Load() == 0 {
+		t.Error("collector did not receive any spans")
+	}
+	if !strings.HasPrefix(traceparent, "00-"+span.SpanContext().TraceID().String()) {
+		t.Errorf("Traceparent() = %q, want trace ID %s", traceparent, span.SpanContext().TraceID())
+	}
+}
+
+func TestInitInvalidExporter(t *testing.T) {
+	if _, err := Init(context.Background(), Config{Exporter: "zipkin"}, "test"); err == nil {
+		t.Error("Init() with invalid exporter did not return an error")
+	}
+}
+
+func TestTraceparentWithoutSpan(t *testing.T) {
+	if traceparent := Traceparent(context.Background()); traceparent != "" {
+		t.Errorf("Traceparent() = %q, want empty string", traceparent)
+	}
+}
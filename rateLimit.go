@@ -0,0 +1,187 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"golang.org/x/time/rate"
+)
+
+var errJobShed = errors.New("backlog of the job creation is full, job shed")
+
+// priorityRank orders the priorities, jobs of a higher rank are created first
+var priorityRank = map[string]int{
+	config.PriorityLow:    0,
+	config.PriorityNormal: 1,
+	config.PriorityHigh:   2,
+}
+
+// jobPriority returns the priority of the definition, or the highest priority of the alerts by their severity
+func jobPriority(data alertContext, settings definitionSettings) string {
+	if settings.Priority != "" {
+		return settings.Priority
+	}
+	alerts := data.alerts
+	if alerts == nil {
+		alerts = []alert{data.alert}
+	}
+	priority := config.PriorityLow
+	for _, alert := range alerts {
+		if alertPriority := settings.rateLimit.Priority(alert.Labels); priorityRank[alertPriority] > priorityRank[priority] {
+			priority = alertPriority
+		}
+	}
+	return priority
+}
+
+// jobWaiter is a job in the backlog waiting for its creation
+type jobWaiter struct {
+	priority string
+	seq      uint64
+	queuedAt time.Time
+	// ready receives nil when the job may be created or errJobShed when it was dropped
+	ready chan error
+}
+
+// jobLimiter limits the rate of job creations with a token bucket. Jobs waiting for a token form a backlog
+// which is served by priority and then in order of arrival, so critical remediations jump the queue.
+// If the backlog exceeds its maximum, the latest job of the lowest priority is shed. Jobs of high priority are never shed.
+type jobLimiter struct {
+	mu          sync.Mutex
+	limiter     *rate.Limiter
+	maxBacklog  int
+	backlog     []*jobWaiter
+	seq         uint64
+	dispatching bool
+}
+
+func newJobLimiter(cfg config.RateLimit) *jobLimiter {
+	return &jobLimiter{
+		limiter:    rate.NewLimiter(jobRateLimit(cfg), cfg.Burst),
+		maxBacklog: cfg.MaxBacklog,
+	}
+}
+
+// jobRateLimit returns the rate of job creations, jobsPerSecond 0 disables the limit
+func jobRateLimit(cfg config.RateLimit) rate.Limit {
+	if cfg.JobsPerSecond > 0 {
+		return rate.Limit(cfg.JobsPerSecond)
+	}
+	return rate.Inf
+}
+
+// update applies a changed configuration, jobs in the backlog are kept
+func (limiter *jobLimiter) update(cfg config.RateLimit) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	limiter.limiter.SetLimit(jobRateLimit(cfg))
+	limiter.limiter.SetBurst(cfg.Burst)
+	limiter.maxBacklog = cfg.MaxBacklog
+}
+
+// wait blocks until a job of the priority may be created. It returns errJobShed if the job was dropped
+// from the full backlog and the error of ctx if it is done first.
+func (limiter *jobLimiter) wait(ctx context.Context, priority string) error {
+	if limiter == nil {
+		return nil
+	}
+	limiter.mu.Lock()
+	if len(limiter.backlog) == 0 && limiter.limiter.Allow() {
+		limiter.mu.Unlock()
+		metadata.JobCreationWaitSeconds.WithLabelValues(priority).Observe(0)
+		return nil
+	}
+	limiter.seq++
+	waiter := &jobWaiter{priority: priority, seq: limiter.seq, queuedAt: time.Now(), ready: make(chan error, 1)}
+	limiter.backlog = append(limiter.backlog, waiter)
+	metadata.JobCreationBacklog.WithLabelValues(priority).Inc()
+	if limiter.maxBacklog > 0 && len(limiter.backlog) > limiter.maxBacklog {
+		limiter.shed()
+	}
+	if !limiter.dispatching {
+		limiter.dispatching = true
+		go limiter.dispatch()
+	}
+	limiter.mu.Unlock()
+
+	select {
+	case err := <-waiter.ready:
+		return err
+	case <-ctx.Done():
+		limiter.mu.Lock()
+		removed := limiter.remove(waiter)
+		limiter.mu.Unlock()
+		if !removed {
+			// The job was granted or shed in the meantime
+			return <-waiter.ready
+		}
+		return ctx.Err()
+	}
+}
+
+// dispatch grants the tokens of the rate limit to the jobs of the backlog until it is empty
+func (limiter *jobLimiter) dispatch() {
+	for {
+		limiter.mu.Lock()
+		if len(limiter.backlog) == 0 {
+			limiter.dispatching = false
+			limiter.mu.Unlock()
+			return
+		}
+		limiter.mu.Unlock()
+
+		// Waiting without a deadline never fails, the limit is read again for every token
+		_ = limiter.limiter.Wait(context.Background())
+
+		limiter.mu.Lock()
+		if len(limiter.backlog) > 0 {
+			next := slices.MinFunc(limiter.backlog, func(a, b *jobWaiter) int {
+				if rank := priorityRank[b.priority] - priorityRank[a.priority]; rank != 0 {
+					return rank
+				}
+				return cmp.Compare(a.seq, b.seq)
+			})
+			limiter.remove(next)
+			metadata.JobCreationWaitSeconds.WithLabelValues(next.priority).Observe(time.Since(next.queuedAt).Seconds())
+			next.ready <- nil
+		}
+		limiter.mu.Unlock()
+	}
+}
+
+// shed drops the latest job of the lowest priority below high, must be called with the lock held
+func (limiter *jobLimiter) shed() {
+	var victim *jobWaiter
+	for _, waiter := range limiter.backlog {
+		if waiter.priority == config.PriorityHigh {
+			continue
+		}
+		if victim == nil || priorityRank[waiter.priority] < priorityRank[victim.priority] ||
+			(priorityRank[waiter.priority] == priorityRank[victim.priority] && waiter.seq > victim.seq) {
+			victim = waiter
+		}
+	}
+	if victim == nil {
+		return
+	}
+	limiter.remove(victim)
+	metadata.JobsShedTotal.WithLabelValues(victim.priority).Inc()
+	victim.ready <- errJobShed
+}
+
+// remove takes the job out of the backlog and reports whether it was waiting, must be called with the lock held
+func (limiter *jobLimiter) remove(waiter *jobWaiter) bool {
+	index := slices.Index(limiter.backlog, waiter)
+	if index < 0 {
+		return false
+	}
+	limiter.backlog = slices.Delete(limiter.backlog, index, index+1)
+	metadata.JobCreationBacklog.WithLabelValues(waiter.priority).Dec()
+	return true
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestJobPriority(t *testing.T) {
+	critical := alert{Labels: map[string]string{"alertname": "Test", "severity": "critical"}}
+	info := alert{Labels: map[string]string{"alertname": "Test", "severity": "info"}}
+
+	tests := []struct {
+		name     string
+		data     alertContext
+		priority string
+		want     string
+	}{
+		{name: "Severity of the alert", data: alertContext{alert: info}, want: config.PriorityLow},
+		{name: "Without severity", data: alertContext{alert: alert{Labels: map[string]string{"alertname": "Test"}}}, want: config.PriorityNormal},
+		{name: "Highest severity of the group", data: alertContext{alert: info, alerts: []alert{info, critical}}, want: config.PriorityHigh},
+		{name: "Priority of the definition", data: alertContext{alert: critical}, priority: config.PriorityLow, want: config.PriorityLow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := definitionSettings{Priority: tt.priority}.withGlobal(config.Default())
+			if got := jobPriority(tt.data, settings); got != tt.want {
+				t.Errorf("jobPriority() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// enqueue waits for a job of the priority in the background and returns the result of the wait
+func enqueue(ctx context.Context, t *testing.T, limiter *jobLimiter, priority string, granted chan<- string) <-chan error {
+	t.Helper()
+	result := make(chan error, 1)
+	limiter.mu.Lock()
+	queued := limiter.seq
+	limiter.mu.Unlock()
+	go func() {
+		err := limiter.wait(ctx, priority)
+		if err == nil && granted != nil {
+			granted <- priority
+		}
+		result <- err
+	}()
+	// Wait until the job is queued, so the order of arrival is known
+	deadline := time.Now().Add(time.Second)
+	for {
+		limiter.mu.Lock()
+		seq := limiter.seq
+		limiter.mu.Unlock()
+		if seq > queued || time.Now().After(deadline) {
+			return result
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestJobLimiterPriority(t *testing.T) {
+	limiter := newJobLimiter(config.RateLimit{JobsPerSecond: 10, Burst: 1})
+	if err := limiter.wait(context.Background(), config.PriorityLow); err != nil {
+		t.Fatalf("first job not created at once: %v", err)
+	}
+
+	granted := make(chan string, 3)
+	var results []<-chan error
+	for _, priority := range []string{config.PriorityLow, config.PriorityNormal, config.PriorityHigh} {
+		results = append(results, enqueue(context.Background(), t, limiter, priority, granted))
+	}
+	for _, result := range results {
+		if err := <-result; err != nil {
+			t.Fatalf("wait() returned error: %v", err)
+		}
+	}
+	close(granted)
+
+	var order []string
+	for priority := range granted {
+		order = append(order, priority)
+	}
+	want := []string{config.PriorityHigh, config.PriorityNormal, config.PriorityLow}
+	for i := range want {
+		if i >= len(order) || order[i] != want[i] {
+			t.Fatalf("jobs created in order %v, want %v", order, want)
+		}
+	}
+}
+
+func TestJobLimiterShed(t *testing.T) {
+	limiter := newJobLimiter(config.RateLimit{JobsPerSecond: 0.1, Burst: 1, MaxBacklog: 2})
+	shed := func(priority string) float64 {
+		return testutil.ToFloat64(metadata.JobsShedTotal.WithLabelValues(priority))
+	}
+	lowBefore, normalBefore, highBefore := shed(config.PriorityLow), shed(config.PriorityNormal), shed(config.PriorityHigh)
+	if err := limiter.wait(context.Background(), config.PriorityLow); err != nil {
+		t.Fatalf("first job not created at once: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	low := enqueue(ctx, t, limiter, config.PriorityLow, nil)
+	normal := enqueue(ctx, t, limiter, config.PriorityNormal, nil)
+	high := enqueue(ctx, t, limiter, config.PriorityHigh, nil)
+	if err := <-low; !errors.Is(err, errJobShed) {
+		t.Errorf("low priority job: wait() = %v, want %v", err, errJobShed)
+	}
+
+	latest := enqueue(ctx, t, limiter, config.PriorityNormal, nil)
+	if err := <-latest; !errors.Is(err, errJobShed) {
+		t.Errorf("latest normal priority job: wait() = %v, want %v", err, errJobShed)
+	}
+
+	highest := enqueue(ctx, t, limiter, config.PriorityHigh, nil)
+	if err := <-normal; !errors.Is(err, errJobShed) {
+		t.Errorf("normal priority job: wait() = %v, want %v", err, errJobShed)
+	}
+
+	if got := shed(config.PriorityLow) - lowBefore; got != 1 {
+		t.Errorf("shed counter for low priority increased by %v, want 1", got)
+	}
+	if got := shed(config.PriorityNormal) - normalBefore; got != 2 {
+		t.Errorf("shed counter for normal priority increased by %v, want 2", got)
+	}
+	if got := shed(config.PriorityHigh) - highBefore; got != 0 {
+		t.Errorf("shed counter for high priority increased by %v, want 0", got)
+	}
+
+	cancel()
+	for _, result := range []<-chan error{high, highest} {
+		if err := <-result; !errors.Is(err, context.Canceled) {
+			t.Errorf("high priority job: wait() = %v, want %v", err, context.Canceled)
+		}
+	}
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	if len(limiter.backlog) != 0 {
+		t.Errorf("backlog holds %d jobs after cancel, want none", len(limiter.backlog))
+	}
+}
+
+func TestJobLimiterUnlimited(t *testing.T) {
+	limiter := newJobLimiter(config.Default().RateLimit)
+	for range 100 {
+		if err := limiter.wait(context.Background(), config.PriorityLow); err != nil {
+			t.Fatalf("wait() returned error: %v", err)
+		}
+	}
+	var unset *jobLimiter
+	if err := unset.wait(context.Background(), config.PriorityLow); err != nil {
+		t.Errorf("wait() without limiter returned error: %v", err)
+	}
+}
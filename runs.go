@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"go.uber.org/zap"
+
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+// runState is the state of a job run
+type runState string
+
+const (
+	runRunning   runState = "running"
+	runSucceeded runState = "succeeded"
+	runFailed    runState = "failed"
+)
+
+// @Description Run of a job created by OpenFero with its result and outputs
+type runRecord struct {
+	// @Description Name of the job
+	Job string `json:"job"`
+	// @Description Namespace of the job
+	Namespace string `json:"namespace"`
+	// @Description Name of the ConfigMap of the definition
+	Definition string `json:"definition,omitempty"`
+	// @Description Alertname of the definition
+	Alertname string `json:"alertname,omitempty"`
+	// @Description What caused the job
+	Trigger string `json:"trigger" enum:"alert,schedule" example:"alert"`
+	// @Description State of the run
+	State runState `json:"state" enum:"running,succeeded,failed" example:"succeeded"`
+	// @Description Reason of a failure
+	Reason string `json:"reason,omitempty"`
+	// @Description Outputs reported by the job in its termination message or OPENFERO_OUTPUT log lines
+	Outputs map[string]string `json:"outputs,omitempty"`
+	// @Description Time when the job was created
+	CreatedAt time.Time `json:"createdAt"`
+	// @Description Time when the job finished
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// runHistory keeps the runs of the latest jobs. It is a job listener, the runs are kept in memory.
+type runHistory struct {
+	mu          sync.Mutex
+	runs        []*runRecord
+	historySize int
+}
+
+func newRunHistory(historySize int) *runHistory {
+	return &runHistory{historySize: historySize}
+}
+
+// jobCreated records the run of the created job as running
+func (history *runHistory) jobCreated(_ context.Context, run *jobRun) {
+	history.mu.Lock()
+	defer history.mu.Unlock()
+	history.runs = append(history.runs, newRunRecord(run.job))
+	history.trim()
+}
+
+// jobFinished records the result and the outputs of the run. Jobs created before a restart are added when they finish.
+func (history *runHistory) jobFinished(run *jobRun, outcome jobOutcome, reason string) {
+	history.mu.Lock()
+	defer history.mu.Unlock()
+	record := history.find(run.job.Namespace, run.job.Name)
+	if record == nil {
+		record = newRunRecord(run.job)
+		history.runs = append(history.runs, record)
+	}
+	now := time.Now()
+	record.State = runSucceeded
+	if outcome == jobOutcomeFailed {
+		record.State = runFailed
+		record.Reason = reason
+	}
+	record.Outputs = run.outputs
+	record.FinishedAt = &now
+	history.trim()
+}
+
+func newRunRecord(job *batchv1.Job) *runRecord {
+	createdAt := job.CreationTimestamp.Time
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	trigger := job.Annotations[triggerAnnotation]
+	if trigger == "" {
+		trigger = triggerAlert
+	}
+	return &runRecord{
+		Job:        job.Name,
+		Namespace:  job.Namespace,
+		Definition: job.Annotations[definitionAnnotation],
+		Alertname:  job.Annotations[alertnameAnnotation],
+		Trigger:    trigger,
+		State:      runRunning,
+		CreatedAt:  createdAt,
+	}
+}
+
+// trim drops the oldest runs beyond the history size, must be called with the lock held
+func (history *runHistory) trim() {
+	if len(history.runs) > history.historySize {
+		history.runs = slices.Delete(history.runs, 0, len(history.runs)-history.historySize)
+	}
+}
+
+// find returns the run of the job, must be called with the lock held
+func (history *runHistory) find(namespace string, name string) *runRecord {
+	for _, record := range history.runs {
+		if record.Namespace == namespace && record.Job == name {
+			return record
+		}
+	}
+	return nil
+}
+
+// list returns the runs, the latest created first
+func (history *runHistory) list() []runRecord {
+	history.mu.Lock()
+	defer history.mu.Unlock()
+	records := make([]runRecord, 0, len(history.runs))
+	for _, record := range history.runs {
+		records = append(records, *record)
+	}
+	slices.SortStableFunc(records, func(a, b runRecord) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return records
+}
+
+// @Summary List job runs
+// @Description List the latest runs of jobs with their state and the outputs reported by the jobs
+// @Tags runs
+// @Produce json
+// @Success 200 {array} runRecord
+// @Router /api/runs [get]
+func (server *clientsetStruct) runsGetHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, server.runs.list())
+}
+
+// @Summary Get runs UI page
+// @Description Get the page showing the latest runs of jobs and their outputs
+// @Tags ui
+// @Produce html
+// @Success 200 {string} string "HTML page"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /ui/runs [get]
+func (server *clientsetStruct) runsUIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(contentType, "text/html")
+
+	tmpl, err := template.ParseFiles(
+		"web/templates/runs.html.templ",
+		"web/templates/navbar.html.templ",
+	)
+	if err != nil {
+		log.Error("error parsing template", zap.String("error", err.Error()))
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Title      string
+		ShowSearch bool
+		Runs       []runRecord
+	}{
+		Title:      "Runs",
+		ShowSearch: false,
+		Runs:       server.runs.list(),
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		log.Error("error executing template", zap.String("error", err.Error()))
+		http.Error(w, "", http.StatusInternalServerError)
+	}
+}
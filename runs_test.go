@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRunHistory(t *testing.T) {
+	history := newRunHistory(2)
+	lifecycle := newJobLifecycle(history)
+	lifecycle.readOutputs = func(_ context.Context, job *batchv1.Job) (map[string]string, error) {
+		return map[string]string{"message": "restarted 3 pods"}, nil
+	}
+
+	job := finishedJob("a", batchv1.JobFailed, "BackoffLimitExceeded")
+	lifecycle.created(context.Background(), &jobRun{job: job})
+	if runs := history.list(); len(runs) != 1 || runs[0].State != runRunning || runs[0].Alertname != "TestAlert" {
+		t.Fatalf("list() = %+v, want the running job", runs)
+	}
+
+	lifecycle.finished(job, jobOutcomeFailed, "BackoffLimitExceeded")
+	deadline := time.Now().Add(5 * time.Second)
+	for history.list()[0].State == runRunning {
+		if time.Now().After(deadline) {
+			t.Fatal("run did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	run := history.list()[0]
+	if run.State != runFailed || run.Reason != "BackoffLimitExceeded" || run.Outputs["message"] != "restarted 3 pods" || run.FinishedAt == nil {
+		t.Errorf("unexpected run: %+v", run)
+	}
+
+	// Jobs created before a restart are added when they finish, only the latest runs are kept
+	older := finishedJob("b", batchv1.JobComplete, "")
+	older.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	history.jobFinished(&jobRun{job: older}, jobOutcomeSucceeded, "")
+	history.jobCreated(context.Background(), &jobRun{job: finishedJob("c", batchv1.JobComplete, "")})
+	runs := history.list()
+	if len(runs) != 2 || runs[0].Job != "job-c" || runs[1].Job != "job-b" || runs[1].State != runSucceeded {
+		t.Errorf("list() = %+v, want the runs of job-c and job-b", runs)
+	}
+
+	server := &clientsetStruct{runs: history}
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/runs", nil)
+	server.runsGetHandler(recorder, req)
+	records := []runRecord{}
+	if err := json.NewDecoder(recorder.Body).Decode(&records); err != nil || len(records) != 2 {
+		t.Errorf("GET /api/runs returned %d runs, error %v", len(records), err)
+	}
+
+	recorder = httptest.NewRecorder()
+	server.runsUIHandler(recorder, httptest.NewRequest(http.MethodGet, "/ui/runs", nil))
+	if !strings.Contains(recorder.Body.String(), "job-c") {
+		t.Errorf("runs page does not show the runs:\n%s", recorder.Body.String())
+	}
+}
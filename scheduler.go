@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// Triggers of jobs, recorded in the annotation openfero/trigger
+const (
+	triggerAlert    = "alert"
+	triggerSchedule = "schedule"
+)
+
+// schedulerSyncInterval is how often the scheduler picks up added, changed and removed schedules
+const schedulerSyncInterval = 30 * time.Second
+
+// Timing of the leader election
+const (
+	leaseDuration = 15 * time.Second
+	renewDeadline = 10 * time.Second
+	retryPeriod   = 2 * time.Second
+)
+
+// scheduleSpec runs a definition on a cron schedule in addition to the alerts
+type scheduleSpec struct {
+	// Cron is the cron expression, CRON_TZ=<zone> selects the time zone, UTC by default
+	Cron string `json:"cron"`
+	// Labels of the alert the scheduled jobs are created for, the alertname defaults to the job definition named like the ConfigMap
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+func (spec *scheduleSpec) validate() error {
+	if spec == nil {
+		return nil
+	}
+	if spec.Cron == "" {
+		return errors.New("schedule.cron must not be empty")
+	}
+	if _, err := cron.ParseStandard(spec.Cron); err != nil {
+		return fmt.Errorf("schedule.cron: %w", err)
+	}
+	return nil
+}
+
+// scheduledEntry is a scheduled definition registered with cron
+type scheduledEntry struct {
+	spec scheduleSpec
+	id   cron.EntryID
+}
+
+// scheduler runs definitions with a schedule. It reuses the path of alerts, so policy, maintenance,
+// approvals and job rendering apply to scheduled jobs as well.
+type scheduler struct {
+	server  *clientsetStruct
+	mu      sync.Mutex
+	cron    *cron.Cron
+	entries map[string]scheduledEntry
+}
+
+func newScheduler(server *clientsetStruct) *scheduler {
+	return &scheduler{server: server}
+}
+
+// runWithLeaderElection runs the scheduler while this instance holds the lease, so scheduled jobs are
+// created once even with several instances. It returns when ctx is cancelled.
+func (scheduler *scheduler) runWithLeaderElection(ctx context.Context, clientset kubernetes.Interface, namespace string, lease string) {
+	logger := log.Subsystem(log.SubsystemJobs)
+	identity, err := os.Hostname()
+	if err != nil {
+		identity = stringWithCharset(10, charset)
+	}
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta:  metav1.ObjectMeta{Name: lease, Namespace: namespace},
+		Client:     clientset.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+	}
+	for ctx.Err() == nil {
+		leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+			Lock:            lock,
+			LeaseDuration:   leaseDuration,
+			RenewDeadline:   renewDeadline,
+			RetryPeriod:     retryPeriod,
+			ReleaseOnCancel: true,
+			Name:            lease,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: func(ctx context.Context) {
+					logger.Info("Became leader, starting scheduler", zap.String("identity", identity))
+					scheduler.run(ctx)
+				},
+				OnStoppedLeading: func() {
+					logger.Info("Stopped leading, scheduler stopped", zap.String("identity", identity))
+				},
+				OnNewLeader: func(leader string) {
+					logger.Debug("Scheduler leader elected", zap.String("leader", leader))
+				},
+			},
+		})
+	}
+}
+
+// run schedules the definitions until ctx is cancelled
+func (scheduler *scheduler) run(ctx context.Context) {
+	scheduler.mu.Lock()
+	// Schedules without CRON_TZ run in UTC, independent of the time zone of the container
+	scheduler.cron = cron.New(cron.WithLocation(time.UTC))
+	scheduler.entries = make(map[string]scheduledEntry)
+	scheduler.mu.Unlock()
+
+	scheduler.sync()
+	scheduler.cron.Start()
+	ticker := time.NewTicker(schedulerSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			<-scheduler.cron.Stop().Done()
+			return
+		case <-ticker.C:
+			scheduler.sync()
+		}
+	}
+}
+
+// sync registers the schedules of all definitions and removes those of changed and deleted definitions
+func (scheduler *scheduler) sync() {
+	logger := log.Subsystem(log.SubsystemJobs)
+	schedules := make(map[string]scheduleSpec)
+	for _, obj := range scheduler.server.configMapStore.List() {
+		configMap := obj.(*v1.ConfigMap)
+		if _, ok := configMap.Data[definitionSettingsKey]; !ok {
+			continue
+		}
+		settings, err := parseDefinitionSettings(configMap)
+		if err != nil || settings.Schedule == nil {
+			continue
+		}
+		schedules[configMap.Name] = *settings.Schedule
+	}
+
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+	for name, entry := range scheduler.entries {
+		if spec, ok := schedules[name]; ok && spec.Cron == entry.spec.Cron && maps.Equal(spec.Labels, entry.spec.Labels) {
+			delete(schedules, name)
+			continue
+		}
+		scheduler.cron.Remove(entry.id)
+		delete(scheduler.entries, name)
+		logger.Info("Schedule removed", zap.String("definition", name))
+	}
+	for name, spec := range schedules {
+		id, err := scheduler.cron.AddFunc(spec.Cron, func() { scheduler.server.runScheduled(name, spec) })
+		if err != nil {
+			logger.Error("error adding schedule", zap.String("definition", name), zap.String("error", err.Error()))
+			continue
+		}
+		scheduler.entries[name] = scheduledEntry{spec: spec, id: id}
+		logger.Info("Schedule added", zap.String("definition", name), zap.String("cron", spec.Cron))
+	}
+}
+
+// runScheduled creates the jobs of a scheduled definition like for an alert with the labels of the schedule
+func (server *clientsetStruct) runScheduled(definition string, spec scheduleSpec) {
+	ctx := log.WithSubsystem(context.Background(), log.SubsystemJobs)
+	logger := log.FromContext(ctx).With(zap.String("definition", definition))
+
+	message, err := server.scheduledMessage(definition, spec)
+	if err != nil {
+		logger.Error("error running scheduled definition", zap.String("error", err.Error()))
+		return
+	}
+	logger.Info("Running scheduled definition")
+	server.createResponseJob(ctx, &message, 0, message.Status)
+}
+
+// scheduledMessage returns a notification with a single alert for the scheduled definition.
+// The status and alertname are taken from the name of the definition, openfero-<alertname>-<status>.
+func (server *clientsetStruct) scheduledMessage(definition string, spec scheduleSpec) (hookMessage, error) {
+	obj, exists, err := server.configMapStore.GetByKey(server.configmapNamespace + "/" + definition)
+	if err != nil {
+		return hookMessage{}, err
+	}
+	if !exists {
+		return hookMessage{}, errors.New("definition does not exist anymore")
+	}
+	configMap := obj.(*v1.ConfigMap)
+
+	var status string
+	for _, candidate := range []string{"firing", "resolved"} {
+		if strings.HasSuffix(definition, "-"+candidate) {
+			status = candidate
+		}
+	}
+	if status == "" || !strings.HasPrefix(definition, "openfero-") {
+		return hookMessage{}, errors.New("name of scheduled definition must be openfero-<alertname>-<status>")
+	}
+	labels := make(map[string]string, len(spec.Labels)+1)
+	maps.Copy(labels, spec.Labels)
+	if labels["alertname"] == "" {
+		for key := range configMap.Data {
+			if strings.ToLower("openfero-"+key+"-"+status) == definition {
+				labels["alertname"] = key
+			}
+		}
+	}
+	if labels["alertname"] == "" {
+		return hookMessage{}, errors.New("no job definition named like the definition, set schedule.labels.alertname")
+	}
+	if strings.ToLower("openfero-"+labels["alertname"]+"-"+status) != definition {
+		return hookMessage{}, fmt.Errorf("alertname %s does not belong to the definition", labels["alertname"])
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	return hookMessage{
+		Version:     "4",
+		GroupKey:    triggerSchedule + "/" + definition,
+		Status:      status,
+		Receiver:    triggerSchedule,
+		GroupLabels: map[string]string{"alertname": labels["alertname"]},
+		Alerts: []alert{{
+			Labels:      labels,
+			Annotations: map[string]string{},
+			Status:      status,
+			StartsAt:    now,
+		}},
+		trigger: triggerSchedule,
+	}, nil
+}
+
+// runScheduler runs the scheduler with leader election if configured, it returns when ctx is cancelled
+func runScheduler(ctx context.Context, server *clientsetStruct, clientset kubernetes.Interface, cfg config.Scheduler) {
+	scheduler := newScheduler(server)
+	if !cfg.LeaderElection {
+		scheduler.run(ctx)
+		return
+	}
+	scheduler.runWithLeaderElection(ctx, clientset, server.configmapNamespace, cfg.Lease)
+}
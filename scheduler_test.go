@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	"github.com/robfig/cron/v3"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+const scheduledJobDefinition = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: cleanup
+spec:
+  template:
+    spec:
+      containers:
+        - name: main
+          image: busybox
+      restartPolicy: Never
+`
+
+func scheduledDefinition(name string, alertname string, settings string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Data:       map[string]string{alertname: scheduledJobDefinition, definitionSettingsKey: settings},
+	}
+}
+
+func TestScheduledMessage(t *testing.T) {
+	tests := []struct {
+		name          string
+		configMap     *v1.ConfigMap
+		definition    string
+		spec          scheduleSpec
+		wantAlertname string
+		wantStatus    string
+		wantErr       string
+	}{
+		{
+			name:          "Alertname of the job definition",
+			configMap:     scheduledDefinition("openfero-nightlycleanup-firing", "NightlyCleanup", ""),
+			definition:    "openfero-nightlycleanup-firing",
+			wantAlertname: "NightlyCleanup",
+			wantStatus:    "firing",
+		},
+		{
+			name:          "Alertname of the labels",
+			configMap:     scheduledDefinition("openfero-nightlycleanup-resolved", "cleanup", ""),
+			definition:    "openfero-nightlycleanup-resolved",
+			spec:          scheduleSpec{Labels: map[string]string{"alertname": "NightlyCleanup", "namespace": "shop"}},
+			wantAlertname: "NightlyCleanup",
+			wantStatus:    "resolved",
+		},
+		{
+			name:       "Alertname of another definition",
+			configMap:  scheduledDefinition("openfero-nightlycleanup-firing", "NightlyCleanup", ""),
+			definition: "openfero-nightlycleanup-firing",
+			spec:       scheduleSpec{Labels: map[string]string{"alertname": "Other"}},
+			wantErr:    "does not belong to the definition",
+		},
+		{
+			name:       "No job definition",
+			configMap:  scheduledDefinition("openfero-nightlycleanup-firing", "cleanup", ""),
+			definition: "openfero-nightlycleanup-firing",
+			wantErr:    "set schedule.labels.alertname",
+		},
+		{
+			name:       "Invalid name",
+			configMap:  scheduledDefinition("cleanup", "cleanup", ""),
+			definition: "cleanup",
+			wantErr:    "must be openfero-<alertname>-<status>",
+		},
+		{name: "Deleted definition", definition: "openfero-nightlycleanup-firing", wantErr: "does not exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+			if tt.configMap != nil {
+				if err := store.Add(tt.configMap); err != nil {
+					t.Fatal(err)
+				}
+			}
+			server := &clientsetStruct{configmapNamespace: "default", configMapStore: store}
+			message, err := server.scheduledMessage(tt.definition, tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("scheduledMessage() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if message.Status != tt.wantStatus || message.Alerts[0].Labels["alertname"] != tt.wantAlertname || message.trigger != triggerSchedule {
+				t.Errorf("unexpected message: %+v", message)
+			}
+		})
+	}
+}
+
+func TestSchedulerSync(t *testing.T) {
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	server := &clientsetStruct{configmapNamespace: "default", configMapStore: store}
+	scheduler := newScheduler(server)
+	scheduler.cron = cron.New()
+	scheduler.entries = make(map[string]scheduledEntry)
+
+	definition := scheduledDefinition("openfero-nightlycleanup-firing", "NightlyCleanup", "schedule:\n  cron: '0 3 * * *'\n")
+	for _, configMap := range []*v1.ConfigMap{
+		definition,
+		scheduledDefinition("openfero-kubequotaalmostfull-firing", "KubeQuotaAlmostFull", "mode: perAlert\n"),
+		scheduledDefinition("openfero-invalid-firing", "Invalid", "schedule:\n  cron: 'daily'\n"),
+	} {
+		if err := store.Add(configMap); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scheduler.sync()
+	if len(scheduler.entries) != 1 || scheduler.entries[definition.Name].spec.Cron != "0 3 * * *" {
+		t.Fatalf("entries = %+v, want the schedule of %s", scheduler.entries, definition.Name)
+	}
+
+	changed := definition.DeepCopy()
+	changed.Data[definitionSettingsKey] = "schedule:\n  cron: '0 4 * * *'\n"
+	if err := store.Update(changed); err != nil {
+		t.Fatal(err)
+	}
+	scheduler.sync()
+	if len(scheduler.cron.Entries()) != 1 || scheduler.entries[definition.Name].spec.Cron != "0 4 * * *" {
+		t.Errorf("entries = %+v after change, want the new schedule only", scheduler.entries)
+	}
+
+	if err := store.Delete(changed); err != nil {
+		t.Fatal(err)
+	}
+	scheduler.sync()
+	if len(scheduler.entries) != 0 || len(scheduler.cron.Entries()) != 0 {
+		t.Errorf("entries = %+v after delete, want none", scheduler.entries)
+	}
+}
+
+func TestRunScheduled(t *testing.T) {
+	alertStore = make([]alertStoreEntry, 0, 10)
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	if err := store.Add(scheduledDefinition("openfero-nightlycleanup-firing", "NightlyCleanup", "schedule:\n  cron: '0 3 * * *'\n")); err != nil {
+		t.Fatal(err)
+	}
+	clientset := fake.NewClientset()
+	runs := newRunHistory(10)
+	server := &clientsetStruct{
+		clientset:               clientset,
+		configmapNamespace:      "default",
+		jobDestinationNamespace: "default",
+		configMapStore:          store,
+		jobStore:                cache.NewStore(cache.MetaNamespaceKeyFunc),
+		lifecycle:               newJobLifecycle(runs),
+	}
+
+	server.runScheduled("openfero-nightlycleanup-firing", scheduleSpec{Cron: "0 3 * * *"})
+
+	jobs, err := clientset.BatchV1().Jobs("default").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs.Items) != 1 || jobs.Items[0].Annotations[triggerAnnotation] != triggerSchedule {
+		t.Fatalf("created jobs = %+v, want one job with trigger %s", jobs.Items, triggerSchedule)
+	}
+	if records := runs.list(); len(records) != 1 || records[0].Trigger != triggerSchedule || records[0].Alertname != "NightlyCleanup" {
+		t.Errorf("runs = %+v, want the scheduled run", records)
+	}
+}
+
+func TestRunSchedulerLeaderElection(t *testing.T) {
+	clientset := fake.NewClientset()
+	server := &clientsetStruct{configmapNamespace: "default", configMapStore: cache.NewStore(cache.MetaNamespaceKeyFunc)}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runScheduler(ctx, server, clientset, config.Scheduler{LeaderElection: true, Lease: "openfero-scheduler"})
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		lease, err := clientset.CoordinationV1().Leases("default").Get(context.Background(), "openfero-scheduler", metav1.GetOptions{})
+		if err == nil && lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity != "" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("scheduler did not acquire the lease")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+}
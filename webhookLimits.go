@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// maxWebhookErrorDetails limits the problems listed in the answer to a rejected webhook
+const maxWebhookErrorDetails = 20
+
+// @Description Error of a rejected webhook request
+type webhookError struct {
+	// @Description Reason of the rejection
+	Error string `json:"error" example:"invalid alerts"`
+	// @Description Problems of the single alerts
+	Details []string `json:"details,omitempty"`
+}
+
+// writeWebhookError answers a rejected webhook request with the error as JSON
+func writeWebhookError(ctx context.Context, httpwriter http.ResponseWriter, status int, message string, details ...string) {
+	log.FromContext(ctx).Warn("Webhook rejected", zap.Int("status", status), zap.String("error", message), zap.Strings("details", details))
+	trace.SpanFromContext(ctx).SetStatus(codes.Error, message)
+	if len(details) > maxWebhookErrorDetails {
+		details = append(details[:maxWebhookErrorDetails:maxWebhookErrorDetails], fmt.Sprintf("and %d more", len(details)-maxWebhookErrorDetails))
+	}
+	writeJSON(httpwriter, status, webhookError{Error: message, Details: details})
+}
+
+// decodeWebhook decodes the JSON body of a webhook request into message. Bodies larger than the limit are answered
+// with 413, invalid bodies, unknown fields and data after the JSON value with 400.
+func decodeWebhook(ctx context.Context, httpwriter http.ResponseWriter, httprequest *http.Request, message interface{}, limits config.Limits) bool {
+	body := http.MaxBytesReader(httpwriter, httprequest.Body, limits.MaxBodyBytes)
+	defer body.Close()
+
+	dec := json.NewDecoder(body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(message)
+	var maxBytesErr *http.MaxBytesError
+	if err == nil {
+		// The body must hold a single JSON value
+		err = dec.Decode(&json.RawMessage{})
+		switch {
+		case errors.Is(err, io.EOF):
+			err = nil
+		case !errors.As(err, &maxBytesErr):
+			err = errors.New("unexpected data after the JSON value")
+		}
+	}
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		writeWebhookError(ctx, httpwriter, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", maxBytesErr.Limit))
+		return false
+	case err != nil:
+		writeWebhookError(ctx, httpwriter, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// rejectedAlert is an invalid alert removed from its message, its result is reported at its position
+type rejectedAlert struct {
+	index  int
+	result alertResult
+}
+
+// acceptHookMessage checks a decoded message. Messages with more alerts than the limit are answered with 413,
+// messages with an invalid status or without any valid alert with 400. Invalid alerts of an otherwise valid
+// message are removed from it and reported as skipped, so they do not cost the valid alerts their jobs.
+// Alertmanager does not resend notifications answered with 4xx.
+func acceptHookMessage(ctx context.Context, httpwriter http.ResponseWriter, message *hookMessage, limits config.Limits) bool {
+	if len(message.Alerts) > limits.MaxAlerts {
+		writeWebhookError(ctx, httpwriter, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("message holds %d alerts, at most %d are accepted", len(message.Alerts), limits.MaxAlerts))
+		return false
+	}
+	if !checkAlertStatus(message.Status) {
+		writeWebhookError(ctx, httpwriter, http.StatusBadRequest, "status must be firing or resolved")
+		return false
+	}
+
+	valid := make([]alert, 0, len(message.Alerts))
+	var rejected []rejectedAlert
+	var details []string
+	for i, alert := range message.Alerts {
+		problems := alertProblems(alert, limits)
+		if len(problems) == 0 {
+			valid = append(valid, alert)
+			continue
+		}
+		for _, problem := range problems {
+			details = append(details, fmt.Sprintf("alerts[%d]: %s", i, problem))
+		}
+		result := alertResult{Alertname: sanitizeInput(alert.Labels["alertname"]), Fingerprint: alert.Fingerprint}
+		rejected = append(rejected, rejectedAlert{index: i, result: result.skipped("invalid alert: " + strings.Join(problems, ", "))})
+	}
+	if len(rejected) == 0 {
+		return true
+	}
+	if len(valid) == 0 {
+		writeWebhookError(ctx, httpwriter, http.StatusBadRequest, "invalid alerts", details...)
+		return false
+	}
+	log.FromContext(ctx).Warn("Invalid alerts are skipped", zap.Int("skipped", len(rejected)), zap.Strings("details", details))
+	message.Alerts = valid
+	message.rejected = rejected
+	return true
+}
+
+// withRejected inserts the results of the alerts removed by acceptHookMessage at their position in the message
+func withRejected(results []alertResult, rejected []rejectedAlert) []alertResult {
+	if len(rejected) == 0 {
+		return results
+	}
+	merged := make([]alertResult, 0, len(results)+len(rejected))
+	next := 0
+	for _, entry := range rejected {
+		for len(merged) < entry.index && next < len(results) {
+			merged = append(merged, results[next])
+			next++
+		}
+		merged = append(merged, entry.result)
+	}
+	return append(merged, results[next:]...)
+}
+
+// validateAlerts returns the problems of the alerts: a missing alertname, too many labels or too long labels
+func validateAlerts(alerts []alert, limits config.Limits) []string {
+	var problems []string
+	for i, alert := range alerts {
+		for _, problem := range alertProblems(alert, limits) {
+			problems = append(problems, fmt.Sprintf("alerts[%d]: %s", i, problem))
+		}
+	}
+	return problems
+}
+
+// alertProblems returns the problems of a single alert
+func alertProblems(alert alert, limits config.Limits) []string {
+	var problems []string
+	if alert.Labels["alertname"] == "" {
+		problems = append(problems, "label alertname is missing")
+	}
+	if len(alert.Labels) > limits.MaxLabels {
+		return append(problems, fmt.Sprintf("%d labels, at most %d are accepted", len(alert.Labels), limits.MaxLabels))
+	}
+	for _, name := range slices.Sorted(maps.Keys(alert.Labels)) {
+		if len(name) > limits.MaxLabelLength {
+			problems = append(problems, fmt.Sprintf("label name %.32s... exceeds %d bytes", name, limits.MaxLabelLength))
+		} else if len(alert.Labels[name]) > limits.MaxLabelLength {
+			problems = append(problems, fmt.Sprintf("value of label %s exceeds %d bytes", name, limits.MaxLabelLength))
+		}
+	}
+	return problems
+}
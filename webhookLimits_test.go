@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/OpenFero/openfero/pkg/config"
+)
+
+// testLimits are small limits to reach them with short payloads
+var testLimits = config.Limits{MaxBodyBytes: 4096, MaxAlerts: 3, MaxLabels: 4, MaxLabelLength: 16}
+
+// decodeWebhookError reads the JSON error of a rejected webhook
+func decodeWebhookError(t *testing.T, recorder *httptest.ResponseRecorder) webhookError {
+	t.Helper()
+	if contentType := recorder.Header().Get(contentType); contentType != applicationJSON {
+		t.Fatalf("Content-Type = %q, want %q", contentType, applicationJSON)
+	}
+	body := webhookError{}
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil || body.Error == "" {
+		t.Fatalf("body is no webhook error: %v", err)
+	}
+	return body
+}
+
+func TestDecodeWebhook(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantErr  string
+	}{
+		{name: "Valid", body: `{"status":"firing","alerts":[{"labels":{"alertname":"Test"}}]}`, wantCode: http.StatusOK},
+		{name: "Invalid JSON", body: `{"status":`, wantCode: http.StatusBadRequest, wantErr: "invalid request body"},
+		{name: "Unknown field", body: `{"status":"firing","state":"alerting"}`, wantCode: http.StatusBadRequest, wantErr: "unknown field"},
+		{name: "Wrong type", body: `{"alerts":{}}`, wantCode: http.StatusBadRequest, wantErr: "cannot unmarshal"},
+		{name: "Data after the message", body: `{"status":"firing"}{"status":"resolved"}`, wantCode: http.StatusBadRequest, wantErr: "unexpected data"},
+		{name: "Body too large", body: `{"groupKey":"` + strings.Repeat("x", 5000) + `"}`, wantCode: http.StatusRequestEntityTooLarge, wantErr: "exceeds 4096 bytes"},
+		{name: "Body too large after the message", body: `{"status":"firing"}` + strings.Repeat(" ", 5000), wantCode: http.StatusRequestEntityTooLarge, wantErr: "exceeds 4096 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/alerts", strings.NewReader(tt.body))
+			recorder := httptest.NewRecorder()
+			message := hookMessage{}
+
+			ok := decodeWebhook(context.Background(), recorder, req, &message, testLimits)
+
+			if ok != (tt.wantCode == http.StatusOK) || recorder.Code != tt.wantCode {
+				t.Fatalf("decodeWebhook() = %v with status %d, want status %d", ok, recorder.Code, tt.wantCode)
+			}
+			if tt.wantErr != "" {
+				if body := decodeWebhookError(t, recorder); !strings.Contains(body.Error, tt.wantErr) {
+					t.Errorf("error = %q, want %q", body.Error, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestAcceptHookMessage(t *testing.T) {
+	valid := alert{Labels: map[string]string{"alertname": "Test", "severity": "warning"}}
+
+	tests := []struct {
+		name         string
+		message      hookMessage
+		wantCode     int
+		wantDetails  []string
+		wantRejected []int
+	}{
+		{name: "Valid", message: hookMessage{Status: "firing", Alerts: []alert{valid, valid}}, wantCode: http.StatusOK},
+		{name: "Too many alerts", message: hookMessage{Status: "firing", Alerts: []alert{valid, valid, valid, valid}}, wantCode: http.StatusRequestEntityTooLarge},
+		{name: "Invalid status", message: hookMessage{Status: "pending", Alerts: []alert{valid}}, wantCode: http.StatusBadRequest},
+		{
+			name: "Missing alertname",
+			message: hookMessage{Status: "firing", Alerts: []alert{
+				valid,
+				{Labels: map[string]string{"severity": "warning"}},
+				{Labels: map[string]string{"alertname": ""}},
+			}},
+			wantCode:     http.StatusOK,
+			wantRejected: []int{1, 2},
+		},
+		{
+			name: "No valid alert",
+			message: hookMessage{Status: "firing", Alerts: []alert{
+				{Labels: map[string]string{"severity": "warning"}},
+				{Labels: map[string]string{"alertname": ""}},
+			}},
+			wantCode:    http.StatusBadRequest,
+			wantDetails: []string{"alerts[0]: label alertname is missing", "alerts[1]: label alertname is missing"},
+		},
+		{
+			name:        "Too many labels",
+			message:     hookMessage{Status: "firing", Alerts: []alert{{Labels: map[string]string{"alertname": "Test", "a": "1", "b": "2", "c": "3", "d": "4"}}}},
+			wantCode:    http.StatusBadRequest,
+			wantDetails: []string{"alerts[0]: 5 labels, at most 4 are accepted"},
+		},
+		{
+			name:        "Too long label",
+			message:     hookMessage{Status: "resolved", Alerts: []alert{{Labels: map[string]string{"alertname": "Test", "instance": strings.Repeat("x", 17)}}}},
+			wantCode:    http.StatusBadRequest,
+			wantDetails: []string{"alerts[0]: value of label instance exceeds 16 bytes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			ok := acceptHookMessage(context.Background(), recorder, &tt.message, testLimits)
+
+			if ok != (tt.wantCode == http.StatusOK) || recorder.Code != tt.wantCode {
+				t.Fatalf("acceptHookMessage() = %v with status %d, want status %d", ok, recorder.Code, tt.wantCode)
+			}
+			if tt.wantCode == http.StatusOK {
+				var rejected []int
+				for _, entry := range tt.message.rejected {
+					rejected = append(rejected, entry.index)
+					if !strings.HasPrefix(entry.result.Reason, "invalid alert: ") {
+						t.Errorf("reason = %q, want an invalid alert", entry.result.Reason)
+					}
+				}
+				if !slices.Equal(rejected, tt.wantRejected) {
+					t.Errorf("rejected = %v, want %v", rejected, tt.wantRejected)
+				}
+				if problems := validateAlerts(tt.message.Alerts, testLimits); len(problems) > 0 {
+					t.Errorf("accepted alerts are invalid: %v", problems)
+				}
+				return
+			}
+			body := decodeWebhookError(t, recorder)
+			if tt.wantDetails != nil && strings.Join(body.Details, "\n") != strings.Join(tt.wantDetails, "\n") {
+				t.Errorf("details = %q, want %q", body.Details, tt.wantDetails)
+			}
+		})
+	}
+}
+
+func TestWebhookErrorDetailsLimit(t *testing.T) {
+	details := make([]string, maxWebhookErrorDetails+5)
+	for i := range details {
+		details[i] = "problem"
+	}
+	recorder := httptest.NewRecorder()
+
+	writeWebhookError(context.Background(), recorder, http.StatusBadRequest, "invalid alerts", details...)
+
+	body := decodeWebhookError(t, recorder)
+	if len(body.Details) != maxWebhookErrorDetails+1 || body.Details[maxWebhookErrorDetails] != "and 5 more" {
+		t.Errorf("details = %q, want %d problems and a summary", body.Details, maxWebhookErrorDetails)
+	}
+}
+
+// checkWebhookResult verifies the invariants of the decoder path: rejected requests are answered with 400 or 413
+// and a JSON error, accepted messages hold only alerts within the limits
+func checkWebhookResult(t *testing.T, recorder *httptest.ResponseRecorder, accepted bool, message *hookMessage) {
+	t.Helper()
+	if !accepted {
+		if recorder.Code != http.StatusBadRequest && recorder.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("rejected with status %d, want 400 or 413", recorder.Code)
+		}
+		decodeWebhookError(t, recorder)
+		return
+	}
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("accepted message answered with status %d", recorder.Code)
+	}
+	if len(message.Alerts) > testLimits.MaxAlerts || !checkAlertStatus(message.Status) {
+		t.Fatalf("accepted message exceeds the limits: %+v", message)
+	}
+	if problems := validateAlerts(message.Alerts, testLimits); len(problems) > 0 {
+		t.Fatalf("accepted message holds invalid alerts: %v", problems)
+	}
+}
+
+// addFuzzSeeds adds the alert fixture and some malformed bodies to the corpus
+func addFuzzSeeds(f *testing.F) {
+	fixture, err := os.ReadFile("test/alerts.json")
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(fixture)
+	f.Add([]byte(`{"status":"firing","alerts":[{"labels":{"alertname":"Test"}}]}`))
+	f.Add([]byte(`{"status":"resolved","alerts":[{"labels":{}}]}`))
+	f.Add([]byte(`{"status":"firing","alerts":null}`))
+	f.Add([]byte(`{"status":"firing","unknown":1}`))
+	f.Add([]byte(`{"status":"firing"} []`))
+	f.Add([]byte(`[]`))
+	f.Add([]byte(`null`))
+	f.Add([]byte(``))
+}
+
+func FuzzAlertmanagerWebhook(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, body []byte) {
+		req := httptest.NewRequest("POST", "/alerts", strings.NewReader(string(body)))
+		recorder := httptest.NewRecorder()
+		message := hookMessage{}
+
+		accepted := decodeWebhook(context.Background(), recorder, req, &message, testLimits) &&
+			acceptHookMessage(context.Background(), recorder, &message, testLimits)
+
+		checkWebhookResult(t, recorder, accepted, &message)
+	})
+}
+
+func FuzzGrafanaWebhook(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Add([]byte(`{"state":"alerting","orgId":1,"alerts":[{"labels":{"alertname":"Test"},"values":{"A":1.5},"dashboardURL":"https://grafana"}]}`))
+	f.Fuzz(func(t *testing.T, body []byte) {
+		req := httptest.NewRequest("POST", "/alerts/grafana", strings.NewReader(string(body)))
+		recorder := httptest.NewRecorder()
+		message := grafanaMessage{}
+
+		if !decodeWebhook(context.Background(), recorder, req, &message, testLimits) {
+			checkWebhookResult(t, recorder, false, nil)
+			return
+		}
+		hook := message.toHookMessage()
+		accepted := acceptHookMessage(context.Background(), recorder, &hook, testLimits)
+		checkWebhookResult(t, recorder, accepted, &hook)
+	})
+}
+
+func FuzzHookPayload(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Add([]byte(`{"records":[{"event":"InstanceStopped","instance":"i-1"}]}`))
+	source := config.HookSource{
+		Alerts:    "{.records}",
+		Alertname: "{.event}",
+		Labels:    map[string]string{"instance": "{.instance}"},
+	}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		req := httptest.NewRequest("POST", "/hooks/ci", strings.NewReader(string(body)))
+		recorder := httptest.NewRecorder()
+		raw := json.RawMessage{}
+
+		if !decodeWebhook(context.Background(), recorder, req, &raw, testLimits) {
+			checkWebhookResult(t, recorder, false, nil)
+			return
+		}
+		payload, err := decodeHookPayload(raw)
+		if err != nil {
+			t.Fatalf("decoded body is no valid payload: %v", err)
+		}
+		message, err := mapHookPayload("ci", source, payload)
+		if err != nil {
+			return
+		}
+		accepted := acceptHookMessage(context.Background(), recorder, &message, testLimits)
+		checkWebhookResult(t, recorder, accepted, &message)
+	})
+}
+
+func FuzzCloudEvent(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Add([]byte(`{"specversion":"1.0","id":"1","source":"ci","type":"com.example.build","alertname":"Build","data":{"result":"failure"}}`))
+	f.Add([]byte(`{"specversion":"1.0","id":"1","source":"ci","type":"com.example.build","data":{"records":[{"event":"Stopped"}]}}`))
+	cfg := config.CloudEvents{Types: map[string]config.HookSource{"com.example.build": {
+		Alerts:    "{.data.records}",
+		Alertname: "{.event}",
+	}}}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		req := httptest.NewRequest("POST", "/cloudevents", strings.NewReader(string(body)))
+		req.Header.Set("Content-Type", "application/cloudevents+json")
+		recorder := httptest.NewRecorder()
+
+		message, accepted := decodeCloudEvent(context.Background(), recorder, req, cfg, testLimits)
+		if accepted {
+			accepted = acceptHookMessage(context.Background(), recorder, &message, testLimits)
+		}
+		checkWebhookResult(t, recorder, accepted, &message)
+	})
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+)
+
+// Results of the alerts of a webhook
+const (
+	// resultQueued is an alert whose job is created in the background
+	resultQueued = "queued"
+	// resultCreated is an alert for which a job was created or a workflow started
+	resultCreated = "created"
+	// resultPendingApproval is an alert whose job waits for approval
+	resultPendingApproval = "pendingApproval"
+	// resultSkipped is an alert for which no job is created on purpose, e.g. during maintenance
+	resultSkipped = "skipped"
+	// resultFailed is an alert whose job could not be created
+	resultFailed = "failed"
+)
+
+// syncQueryParameter overrides webhook.synchronous for a single request
+const syncQueryParameter = "sync"
+
+// syncResponseMargin is left of server.writeTimeout to write a synchronous response
+const syncResponseMargin = time.Second
+
+// @Description Response to a webhook with the result of every alert
+type webhookResponse struct {
+	// @Description Whether the jobs were created before answering
+	Synchronous bool `json:"synchronous"`
+	// @Description Results of the alerts in the order of the notification
+	Alerts []alertResult `json:"alerts"`
+}
+
+// @Description What happened to a single alert of a webhook
+type alertResult struct {
+	// @Description Alertname of the alert
+	Alertname string `json:"alertname"`
+	// @Description Fingerprint of the alert
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// @Description Name of the ConfigMap of the matched definition, empty if none matched or it was not looked up
+	Definition string `json:"definition,omitempty"`
+	// @Description Result of the alert
+	Result string `json:"result" enum:"queued,created,pendingApproval,skipped,failed" example:"created"`
+	// @Description Name of the created job, or of the job waiting for approval
+	Job string `json:"job,omitempty"`
+	// @Description ID of the started workflow, or of the workflow waiting for approval
+	Workflow string `json:"workflow,omitempty"`
+	// @Description ID of the approval the job waits for
+	Approval string `json:"approval,omitempty"`
+	// @Description Reason why no job was created
+	Reason string `json:"reason,omitempty"`
+}
+
+// indexedResult is the result of the alert at index of a notification
+type indexedResult struct {
+	index  int
+	result alertResult
+}
+
+func (result alertResult) skipped(reason string) alertResult {
+	result.Result = resultSkipped
+	result.Reason = reason
+	return result
+}
+
+func (result alertResult) failed(err error) alertResult {
+	result.Result = resultFailed
+	result.Reason = err.Error()
+	return result
+}
+
+// parked returns the result of an alert handed to parkForApproval
+func (result alertResult) parked(id string, parked bool) alertResult {
+	if !parked {
+		return result.skipped("job of the alert is already waiting for approval")
+	}
+	result.Result = resultPendingApproval
+	result.Approval = id
+	return result
+}
+
+// respondToWebhook processes the notification and answers with the result of every alert. Synchronous responses
+// hold the results of the job creation and are answered with 200, others with 202. Alerts whose jobs failed do
+// not change the status code, so Alertmanager does not resend a notification whose other jobs were created.
+func (server *clientsetStruct) respondToWebhook(ctx context.Context, httpwriter http.ResponseWriter, httprequest *http.Request, message *hookMessage) {
+	webhook := server.currentConfig().Webhook
+	synchronous, err := webhookSynchronous(httprequest, webhook)
+	if err != nil {
+		writeWebhookError(ctx, httpwriter, http.StatusBadRequest, "query parameter "+syncQueryParameter+" must be true or false")
+		return
+	}
+	if !synchronous {
+		results := server.processHookMessage(ctx, message, 0)
+		writeJSON(httpwriter, http.StatusAccepted, webhookResponse{Alerts: withRejected(results, message.rejected)})
+		return
+	}
+	results := server.processHookMessage(ctx, message, syncTimeout(server.currentConfig()))
+	writeJSON(httpwriter, http.StatusOK, webhookResponse{Synchronous: true, Alerts: withRejected(results, message.rejected)})
+}
+
+// syncTimeout returns how long a synchronous response waits for the jobs. It is capped below server.writeTimeout,
+// which is only validated against webhook.syncTimeout if synchronous mode is configured, not for sync=true.
+func syncTimeout(cfg *config.Config) time.Duration {
+	writeTimeout := cfg.Server.WriteTimeout.Duration()
+	limit := writeTimeout - syncResponseMargin
+	if limit <= 0 {
+		limit = writeTimeout / 2
+	}
+	return min(cfg.Webhook.SyncTimeout.Duration(), limit)
+}
+
+// webhookSynchronous returns if the jobs of the request are created before answering
+func webhookSynchronous(httprequest *http.Request, webhook config.Webhook) (bool, error) {
+	value := httprequest.URL.Query().Get(syncQueryParameter)
+	if value == "" {
+		return webhook.Synchronous, nil
+	}
+	return strconv.ParseBool(value)
+}
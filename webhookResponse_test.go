@@ -0,0 +1,278 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenFero/openfero/pkg/config"
+	"github.com/OpenFero/openfero/pkg/metadata"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+// waitForJobCreation waits until the jobs created in the background are done, so they do not outlive the test
+func waitForJobCreation(t *testing.T) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for testutil.ToFloat64(metadata.JobQueueDepth) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("jobs still in creation after 5s")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSyncTimeout(t *testing.T) {
+	tests := []struct {
+		name         string
+		syncTimeout  time.Duration
+		writeTimeout time.Duration
+		want         time.Duration
+	}{
+		{name: "Shorter than write timeout", syncTimeout: 5 * time.Second, writeTimeout: 10 * time.Second, want: 5 * time.Second},
+		{name: "Capped below write timeout", syncTimeout: 5 * time.Second, writeTimeout: 3 * time.Second, want: 2 * time.Second},
+		{name: "Write timeout within the margin", syncTimeout: 5 * time.Second, writeTimeout: time.Second, want: 500 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Webhook.SyncTimeout = config.Duration(tt.syncTimeout)
+			cfg.Server.WriteTimeout = config.Duration(tt.writeTimeout)
+			if got := syncTimeout(cfg); got != tt.want {
+				t.Errorf("syncTimeout() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertsPostHandlerResponse(t *testing.T) {
+	jobDefinition := `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: quota
+spec:
+  template:
+    spec:
+      containers:
+        - name: main
+          image: busybox
+      restartPolicy: Never
+`
+	fixture, err := os.ReadFile("test/alerts.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		settings    string
+		configure   func(*config.Config)
+		definition  bool
+		createDelay time.Duration
+		wantCode    int
+		wantSync    bool
+		wantResults []string
+		wantReason  string
+	}{
+		{name: "Queued by default", definition: true, wantCode: http.StatusAccepted, wantResults: []string{resultQueued, resultQueued, resultQueued}},
+		{name: "Synchronous by query", query: "?sync=true", definition: true, wantCode: http.StatusOK, wantSync: true, wantResults: []string{resultCreated, resultCreated, resultCreated}},
+		{
+			name:        "Synchronous by configuration",
+			configure:   func(cfg *config.Config) { cfg.Webhook.Synchronous = true },
+			definition:  true,
+			wantCode:    http.StatusOK,
+			wantSync:    true,
+			wantResults: []string{resultCreated, resultCreated, resultCreated},
+		},
+		{
+			name:        "Query overrides configuration",
+			query:       "?sync=false",
+			configure:   func(cfg *config.Config) { cfg.Webhook.Synchronous = true },
+			definition:  true,
+			wantCode:    http.StatusAccepted,
+			wantResults: []string{resultQueued, resultQueued, resultQueued},
+		},
+		{name: "Invalid query", query: "?sync=maybe", definition: true, wantCode: http.StatusBadRequest},
+		{
+			name:        "No definition",
+			query:       "?sync=true",
+			wantCode:    http.StatusOK,
+			wantSync:    true,
+			wantResults: []string{resultSkipped, resultSkipped, resultSkipped},
+			wantReason:  "no definition openfero-kubequotaalmostfull-firing",
+		},
+		{
+			name:        "Paused",
+			query:       "?sync=true",
+			configure:   func(cfg *config.Config) { cfg.Maintenance.Paused = true },
+			definition:  true,
+			wantCode:    http.StatusOK,
+			wantSync:    true,
+			wantResults: []string{resultSkipped, resultSkipped, resultSkipped},
+			wantReason:  "OpenFero is paused: maintenance.paused is set",
+		},
+		{
+			name:        "Per group",
+			query:       "?sync=true",
+			settings:    "mode: perGroup\n",
+			definition:  true,
+			wantCode:    http.StatusOK,
+			wantSync:    true,
+			wantResults: []string{resultCreated, resultSkipped, resultSkipped},
+			wantReason:  "handled by the job of alert 0 of the group",
+		},
+		{
+			name:        "Requires approval",
+			query:       "?sync=true",
+			settings:    "requiresApproval: true\n",
+			definition:  true,
+			wantCode:    http.StatusOK,
+			wantSync:    true,
+			wantResults: []string{resultPendingApproval, resultPendingApproval, resultPendingApproval},
+		},
+		{
+			name:        "Timeout",
+			query:       "?sync=true",
+			configure:   func(cfg *config.Config) { cfg.Webhook.SyncTimeout = config.Duration(10 * time.Millisecond) },
+			definition:  true,
+			createDelay: 200 * time.Millisecond,
+			wantCode:    http.StatusOK,
+			wantSync:    true,
+			wantResults: []string{resultQueued, resultQueued, resultQueued},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alertStore = make([]alertStoreEntry, 0, 10)
+			t.Cleanup(func() { waitForJobCreation(t) })
+			configMapStore := cache.NewStore(cache.MetaNamespaceKeyFunc)
+			if tt.definition {
+				data := map[string]string{"KubeQuotaAlmostFull": jobDefinition}
+				if tt.settings != "" {
+					data[definitionSettingsKey] = tt.settings
+				}
+				if err := configMapStore.Add(&v1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "openfero-kubequotaalmostfull-firing", Namespace: "default"},
+					Data:       data,
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			clientset := fake.NewClientset()
+			if tt.createDelay > 0 {
+				clientset.PrependReactor("create", "jobs", func(k8stesting.Action) (bool, runtime.Object, error) {
+					time.Sleep(tt.createDelay)
+					return false, nil, nil
+				})
+			}
+			server := &clientsetStruct{
+				clientset:               clientset,
+				configmapNamespace:      "default",
+				jobDestinationNamespace: "default",
+				configMapStore:          configMapStore,
+				jobStore:                cache.NewStore(cache.MetaNamespaceKeyFunc),
+				approvals:               newApprovalStore(10),
+			}
+			cfg := config.Default()
+			if tt.configure != nil {
+				tt.configure(cfg)
+			}
+			server.config.Store(cfg)
+
+			req := httptest.NewRequest("POST", "/alerts"+tt.query, strings.NewReader(string(fixture)))
+			responserecorder := httptest.NewRecorder()
+
+			server.alertsPostHandler(responserecorder, req)
+
+			if status := responserecorder.Code; status != tt.wantCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tt.wantCode)
+			}
+			if tt.wantResults == nil {
+				decodeWebhookError(t, responserecorder)
+				return
+			}
+			response := webhookResponse{}
+			if err := json.NewDecoder(responserecorder.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Synchronous != tt.wantSync || len(response.Alerts) != len(tt.wantResults) {
+				t.Fatalf("response = %+v, want %d results", response, len(tt.wantResults))
+			}
+			for i, result := range response.Alerts {
+				if result.Alertname != "KubeQuotaAlmostFull" || result.Fingerprint == "" || result.Result != tt.wantResults[i] {
+					t.Errorf("alerts[%d] = %+v, want result %s", i, result, tt.wantResults[i])
+				}
+				switch result.Result {
+				case resultCreated:
+					if !strings.HasPrefix(result.Job, "quota-") || result.Definition != "openfero-kubequotaalmostfull-firing" {
+						t.Errorf("alerts[%d] = %+v, want the created job and its definition", i, result)
+					}
+				case resultPendingApproval:
+					if result.Approval == "" || result.Job == "" {
+						t.Errorf("alerts[%d] = %+v, want the approval and the pending job", i, result)
+					}
+				case resultSkipped:
+					if result.Reason != tt.wantReason {
+						t.Errorf("alerts[%d] reason = %q, want %q", i, result.Reason, tt.wantReason)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAlertsPostHandlerInvalidAlert(t *testing.T) {
+	alertStore = make([]alertStoreEntry, 0, 10)
+	server := &clientsetStruct{
+		clientset:               fake.NewClientset(),
+		configmapNamespace:      "default",
+		jobDestinationNamespace: "default",
+		configMapStore:          cache.NewStore(cache.MetaNamespaceKeyFunc),
+		jobStore:                cache.NewStore(cache.MetaNamespaceKeyFunc),
+		approvals:               newApprovalStore(10),
+	}
+	server.config.Store(config.Default())
+	body := `{"status":"firing","alerts":[
+		{"labels":{"alertname":"First"},"fingerprint":"a"},
+		{"labels":{"severity":"warning"},"fingerprint":"b"},
+		{"labels":{"alertname":"Third"},"fingerprint":"c"}]}`
+
+	req := httptest.NewRequest("POST", "/alerts?sync=true", strings.NewReader(body))
+	responserecorder := httptest.NewRecorder()
+
+	server.alertsPostHandler(responserecorder, req)
+
+	if status := responserecorder.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	response := webhookResponse{}
+	if err := json.NewDecoder(responserecorder.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	want := []alertResult{
+		{Alertname: "First", Fingerprint: "a", Result: resultSkipped, Reason: "no definition openfero-first-firing"},
+		{Fingerprint: "b", Result: resultSkipped, Reason: "invalid alert: label alertname is missing"},
+		{Alertname: "Third", Fingerprint: "c", Result: resultSkipped, Reason: "no definition openfero-third-firing"},
+	}
+	if len(response.Alerts) != len(want) {
+		t.Fatalf("response = %+v, want %d results", response, len(want))
+	}
+	for i, result := range response.Alerts {
+		if result != want[i] {
+			t.Errorf("alerts[%d] = %+v, want %+v", i, result, want[i])
+		}
+	}
+}
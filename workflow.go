@@ -0,0 +1,566 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	log "github.com/OpenFero/openfero/pkg/logging"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Conditions on the dependencies of a workflow step
+const (
+	// whenSucceeded runs the step if all dependencies succeeded
+	whenSucceeded = "succeeded"
+	// whenFailed runs the step if any dependency failed
+	whenFailed = "failed"
+	// whenAlways runs the step once all dependencies finished
+	whenAlways = "always"
+)
+
+// stepEnvPrefix is the prefix of the environment variables passing the results and outputs of finished steps
+const stepEnvPrefix = envPrefix + "STEP_"
+
+// stepState is the state of a step of a workflow
+type stepState string
+
+const (
+	stepPending   stepState = "pending"
+	stepRunning   stepState = "running"
+	stepSucceeded stepState = "succeeded"
+	stepFailed    stepState = "failed"
+	stepSkipped   stepState = "skipped"
+)
+
+// workflowState is the overall state of a workflow
+type workflowState string
+
+const (
+	workflowRunning   workflowState = "running"
+	workflowSucceeded workflowState = "succeeded"
+	workflowFailed    workflowState = "failed"
+)
+
+// workflowSpec is a workflow of several jobs. Steps run in the listed order unless any step sets dependsOn,
+// then the steps form a DAG and steps without dependencies start right away.
+type workflowSpec struct {
+	Steps []workflowStep `json:"steps"`
+}
+
+// workflowStep is a job of a workflow
+type workflowStep struct {
+	// Name identifies the step in dependsOn and in the environment variables of later steps
+	Name string `json:"name"`
+	// Job is the data key of the definition ConfigMap holding the job definition of the step
+	Job string `json:"job"`
+	// DependsOn are the steps which must finish before this step
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// When is succeeded, failed or always, defaults to succeeded
+	When string `json:"when,omitempty"`
+}
+
+// dependencies returns the dependencies of every step, the previous step for workflows without dependsOn
+func (spec *workflowSpec) dependencies() map[string][]string {
+	dag := slices.ContainsFunc(spec.Steps, func(step workflowStep) bool { return len(step.DependsOn) > 0 })
+	dependencies := make(map[string][]string, len(spec.Steps))
+	for i, step := range spec.Steps {
+		switch {
+		case dag:
+			dependencies[step.Name] = step.DependsOn
+		case i > 0:
+			dependencies[step.Name] = []string{spec.Steps[i-1].Name}
+		}
+	}
+	return dependencies
+}
+
+// validate checks the steps, their dependencies and that their job definitions exist in data
+func (spec *workflowSpec) validate(data map[string]string) error {
+	if spec == nil {
+		return nil
+	}
+	if len(spec.Steps) == 0 {
+		return errors.New("workflow.steps must not be empty")
+	}
+	var errs []error
+	names := make(map[string]bool, len(spec.Steps))
+	for i, step := range spec.Steps {
+		path := fmt.Sprintf("workflow.steps[%d]", i)
+		for _, msg := range validation.IsDNS1123Label(step.Name) {
+			errs = append(errs, fmt.Errorf("%s.name: %s", path, msg))
+		}
+		if names[step.Name] {
+			errs = append(errs, fmt.Errorf("%s.name %s is not unique", path, step.Name))
+		}
+		names[step.Name] = true
+		if _, ok := data[step.Job]; !ok || step.Job == definitionSettingsKey {
+			errs = append(errs, fmt.Errorf("%s.job: no job definition with key %q", path, step.Job))
+		}
+		if step.When != "" && step.When != whenSucceeded && step.When != whenFailed && step.When != whenAlways {
+			errs = append(errs, fmt.Errorf("%s.when must be %s, %s or %s", path, whenSucceeded, whenFailed, whenAlways))
+		}
+	}
+	for i, step := range spec.Steps {
+		for _, dependency := range step.DependsOn {
+			if !names[dependency] || dependency == step.Name {
+				errs = append(errs, fmt.Errorf("workflow.steps[%d].dependsOn: unknown step %s", i, dependency))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if len(spec.order()) != len(spec.Steps) {
+		return errors.New("workflow.steps: dependsOn contains a cycle")
+	}
+	return nil
+}
+
+// order returns the steps in topological order, steps in a cycle are missing
+func (spec *workflowSpec) order() []string {
+	dependencies := spec.dependencies()
+	done := make(map[string]bool, len(spec.Steps))
+	var order []string
+	for changed := true; changed; {
+		changed = false
+		for _, step := range spec.Steps {
+			if done[step.Name] {
+				continue
+			}
+			if !slices.ContainsFunc(dependencies[step.Name], func(dependency string) bool { return !done[dependency] }) {
+				done[step.Name] = true
+				order = append(order, step.Name)
+				changed = true
+			}
+		}
+	}
+	return order
+}
+
+// @Description Status of a workflow and its steps
+type workflowStatus struct {
+	// @Description ID of the workflow, set as annotation openfero/workflow on its jobs
+	ID string `json:"id"`
+	// @Description Name of the ConfigMap of the definition
+	Definition string `json:"definition"`
+	// @Description Alertname of the definition
+	Alertname string `json:"alertname"`
+	// @Description Overall state, failed if any step failed
+	State workflowState `json:"state" enum:"running,succeeded,failed" example:"running"`
+	// @Description Time when the workflow started
+	StartedAt time.Time `json:"startedAt"`
+	// @Description Time when the last step finished
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	// @Description Steps in the order of the definition
+	Steps []stepStatus `json:"steps"`
+}
+
+// @Description Status of a step of a workflow
+type stepStatus struct {
+	// @Description Name of the step
+	Name string `json:"name"`
+	// @Description Name of the job of the step
+	Job string `json:"job,omitempty"`
+	// @Description State of the step
+	State stepState `json:"state" enum:"pending,running,succeeded,failed,skipped" example:"pending"`
+	// @Description Reason of a failure
+	Reason string `json:"reason,omitempty"`
+	// @Description Outputs written by the step to its termination message
+	Outputs map[string]string `json:"outputs,omitempty"`
+}
+
+// workflowRun is a workflow together with everything needed to start its steps
+type workflowRun struct {
+	status      workflowStatus
+	spec        *workflowSpec
+	definitions map[string]string
+	data        alertContext
+	settings    definitionSettings
+	// spanContext continues the trace of the alert in the jobs of later steps
+	spanContext trace.SpanContext
+	// approvedBy is the user who approved the workflow
+	approvedBy string
+}
+
+func (run *workflowRun) step(name string) *stepStatus {
+	for i := range run.status.Steps {
+		if run.status.Steps[i].Name == name {
+			return &run.status.Steps[i]
+		}
+	}
+	return nil
+}
+
+// workflowEngine starts the steps of workflows when their dependencies finished.
+// It is a job listener, so it is driven by the job informer. Workflows are kept in memory,
+// workflows running during a restart of OpenFero do not continue.
+type workflowEngine struct {
+	mu          sync.Mutex
+	runs        []*workflowRun
+	historySize int
+	server      *clientsetStruct
+	// steps maps the jobs of running steps to their workflow and step, so deleted jobs fail their step
+	steps map[types.UID]stepJob
+}
+
+// stepJob identifies the step a job was created for
+type stepJob struct {
+	workflow string
+	step     string
+	job      string
+}
+
+func newWorkflowEngine(server *clientsetStruct, historySize int) *workflowEngine {
+	return &workflowEngine{historySize: historySize, server: server, steps: make(map[types.UID]stepJob)}
+}
+
+// newWorkflowRun prepares a workflow for the definition, it is started by start
+func newWorkflowRun(ctx context.Context, configMap *v1.ConfigMap, data alertContext, definition string, alertname string, settings definitionSettings) *workflowRun {
+	run := &workflowRun{
+		status: workflowStatus{
+			ID:         newID(),
+			Definition: definition,
+			Alertname:  alertname,
+			State:      workflowRunning,
+		},
+		spec:        settings.Workflow,
+		definitions: make(map[string]string, len(settings.Workflow.Steps)),
+		data:        data,
+		settings:    settings,
+		spanContext: trace.SpanContextFromContext(ctx),
+	}
+	for _, step := range settings.Workflow.Steps {
+		run.status.Steps = append(run.status.Steps, stepStatus{Name: step.Name, State: stepPending})
+		run.definitions[step.Name] = configMap.Data[step.Job]
+	}
+	return run
+}
+
+// start starts the steps of the workflow without dependencies
+func (engine *workflowEngine) start(ctx context.Context, run *workflowRun) {
+	engine.mu.Lock()
+	run.status.StartedAt = time.Now()
+	engine.runs = append(engine.runs, run)
+	engine.mu.Unlock()
+
+	log.FromContext(ctx).Info("Workflow started", zap.String("workflow", run.status.ID), zap.Int("steps", len(run.spec.Steps)))
+	engine.advance(ctx, run)
+}
+
+// jobCreated remembers the job of a step, steps are marked running when they are started
+func (engine *workflowEngine) jobCreated(_ context.Context, created *jobRun) {
+	id := created.job.Annotations[workflowAnnotation]
+	name := created.job.Annotations[workflowStepAnnotation]
+	if id == "" || name == "" {
+		return
+	}
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+	engine.steps[created.job.UID] = stepJob{workflow: id, step: name, job: created.job.Name}
+}
+
+// jobFinished records the result and the outputs of a step and starts the steps depending on it
+func (engine *workflowEngine) jobFinished(finished *jobRun, outcome jobOutcome, reason string) {
+	id := finished.job.Annotations[workflowAnnotation]
+	name := finished.job.Annotations[workflowStepAnnotation]
+	if id == "" || name == "" {
+		return
+	}
+	engine.mu.Lock()
+	delete(engine.steps, finished.job.UID)
+	engine.mu.Unlock()
+
+	state := stepSucceeded
+	if outcome == jobOutcomeFailed {
+		state = stepFailed
+	}
+	engine.finishStep(stepJob{workflow: id, step: name, job: finished.job.Name}, state, reason, finished.outputs)
+}
+
+// jobDeleted fails the step of a job deleted before it finished, so the workflow does not wait for it forever
+func (engine *workflowEngine) jobDeleted(uid types.UID) {
+	engine.mu.Lock()
+	deleted, ok := engine.steps[uid]
+	delete(engine.steps, uid)
+	engine.mu.Unlock()
+	if ok {
+		engine.finishStep(deleted, stepFailed, "job deleted", nil)
+	}
+}
+
+// finishStep records the final state of a running step and starts the steps depending on it
+func (engine *workflowEngine) finishStep(finished stepJob, state stepState, reason string, outputs map[string]string) {
+	id, name := finished.workflow, finished.step
+	ctx := log.WithSubsystem(context.Background(), log.SubsystemJobs)
+	ctx = log.WithFields(ctx, zap.String("workflow", id), zap.String("step", name), zap.String(log.JobKey, finished.job))
+	logger := log.FromContext(ctx)
+
+	engine.mu.Lock()
+	run := engine.find(id)
+	var step *stepStatus
+	if run != nil {
+		step = run.step(name)
+	}
+	if step == nil || step.State != stepRunning {
+		engine.mu.Unlock()
+		logger.Debug("Job of unknown workflow step finished")
+		return
+	}
+	step.State = state
+	if state == stepFailed {
+		step.Reason = reason
+	}
+	step.Outputs = outputs
+	engine.mu.Unlock()
+
+	logger.Info("Workflow step finished", zap.String("state", string(state)))
+	if run.spanContext.IsValid() {
+		ctx = trace.ContextWithRemoteSpanContext(ctx, run.spanContext)
+	}
+	engine.advance(ctx, run)
+}
+
+// advance starts all steps whose dependencies finished and skips those whose condition is not met.
+// It finishes the workflow once no step is pending or running.
+func (engine *workflowEngine) advance(ctx context.Context, run *workflowRun) {
+	for {
+		engine.mu.Lock()
+		ready := engine.readySteps(run)
+		if len(ready) == 0 {
+			engine.finishIfDone(ctx, run)
+			engine.mu.Unlock()
+			return
+		}
+		env := stepEnvVars(run)
+		engine.mu.Unlock()
+
+		// Steps do not start while OpenFero is paused or the alert is in a maintenance window
+		skipReason, _ := engine.server.maintenanceSkipReason(engine.server.currentConfig(), run.data.alert.Labels, time.Now())
+		for _, name := range ready {
+			if skipReason != "" {
+				engine.mu.Lock()
+				step := run.step(name)
+				step.State = stepSkipped
+				step.Reason = skipReason
+				engine.mu.Unlock()
+				continue
+			}
+			job, err := engine.startStep(ctx, run, name, env)
+			engine.mu.Lock()
+			step := run.step(name)
+			if err != nil {
+				step.State = stepFailed
+				step.Reason = err.Error()
+			} else {
+				step.Job = job
+			}
+			engine.mu.Unlock()
+		}
+	}
+}
+
+// readySteps marks the pending steps whose dependencies finished as running or skipped
+// and returns the steps to start, must be called with the lock held
+func (engine *workflowEngine) readySteps(run *workflowRun) []string {
+	dependencies := run.spec.dependencies()
+	var ready []string
+	for changed := true; changed; {
+		changed = false
+		for _, spec := range run.spec.Steps {
+			step := run.step(spec.Name)
+			if step.State != stepPending {
+				continue
+			}
+			states := make([]stepState, 0, len(dependencies[spec.Name]))
+			for _, dependency := range dependencies[spec.Name] {
+				states = append(states, run.step(dependency).State)
+			}
+			if slices.Contains(states, stepPending) || slices.Contains(states, stepRunning) {
+				continue
+			}
+			changed = true
+			if !stepConditionMet(spec.When, states) {
+				step.State = stepSkipped
+				continue
+			}
+			step.State = stepRunning
+			ready = append(ready, spec.Name)
+		}
+	}
+	return ready
+}
+
+// stepConditionMet evaluates the condition of a step on the final states of its dependencies
+func stepConditionMet(when string, dependencies []stepState) bool {
+	switch when {
+	case whenFailed:
+		return slices.Contains(dependencies, stepFailed)
+	case whenAlways:
+		return true
+	default:
+		return !slices.ContainsFunc(dependencies, func(state stepState) bool { return state != stepSucceeded })
+	}
+}
+
+// finishIfDone sets the overall state once all steps finished, must be called with the lock held
+func (engine *workflowEngine) finishIfDone(ctx context.Context, run *workflowRun) {
+	if run.status.State != workflowRunning {
+		return
+	}
+	state := workflowSucceeded
+	for _, step := range run.status.Steps {
+		switch step.State {
+		case stepPending, stepRunning:
+			return
+		case stepFailed:
+			state = workflowFailed
+		}
+	}
+	now := time.Now()
+	run.status.State = state
+	run.status.FinishedAt = &now
+	log.FromContext(ctx).Info("Workflow finished", zap.String("workflow", run.status.ID), zap.String("state", string(state)))
+
+	// Drop the oldest finished workflows beyond the history size
+	finished := 0
+	for i := len(engine.runs) - 1; i >= 0; i-- {
+		if engine.runs[i].status.State == workflowRunning {
+			continue
+		}
+		finished++
+		if finished > engine.historySize {
+			engine.runs = slices.Delete(engine.runs, i, i+1)
+		}
+	}
+}
+
+// startStep creates the job of a step and returns its name
+func (engine *workflowEngine) startStep(ctx context.Context, run *workflowRun, name string, env []v1.EnvVar) (string, error) {
+	server := engine.server
+	ctx = log.WithFields(ctx, zap.String("workflow", run.status.ID), zap.String("step", name))
+	logger := log.FromContext(ctx)
+
+	jobObject, err := renderJob(ctx, run.definitions[name], run.data, run.status.Definition, run.status.Alertname, run.settings)
+	if err != nil {
+		logger.Error("error rendering job definition of step", zap.String("error", err.Error()))
+		return "", err
+	}
+	jobObject.Annotations[workflowAnnotation] = run.status.ID
+	jobObject.Annotations[workflowStepAnnotation] = name
+	if run.approvedBy != "" {
+		jobObject.Annotations[approvedByAnnotation] = run.approvedBy
+	}
+	containers := jobObject.Spec.Template.Spec.Containers
+	for i := range containers {
+		if len(run.settings.Injection.Containers) == 0 || slices.Contains(run.settings.Injection.Containers, containers[i].Name) {
+			containers[i].Env = append(containers[i].Env, env...)
+		}
+	}
+
+	file, err := newAlertFile(run.data, run.settings.Injection)
+	if err != nil {
+		logger.Error("error rendering alert file of step", zap.String("error", err.Error()))
+		return "", err
+	}
+	ctx = log.WithFields(ctx, zap.String(log.JobKey, jobObject.Name))
+	created, err := server.createRemediationJob(ctx, jobObject, file)
+	if err != nil {
+		return "", err
+	}
+	server.lifecycle.created(ctx, &jobRun{job: created, data: run.data, settings: &run.settings})
+	return created.Name, nil
+}
+
+// stepEnvVars passes the state of every finished step as OPENFERO_STEP_<STEP> and its outputs as OPENFERO_STEP_<STEP>_<OUTPUT>
+func stepEnvVars(run *workflowRun) []v1.EnvVar {
+	var env []v1.EnvVar
+	for _, step := range run.status.Steps {
+		if step.State != stepSucceeded && step.State != stepFailed && step.State != stepSkipped {
+			continue
+		}
+		name := stepEnvPrefix + envName(step.Name)
+		env = append(env, v1.EnvVar{Name: name, Value: string(step.State)})
+		env = mapEnvVars(env, name+"_", step.Outputs)
+	}
+	return env
+}
+
+// list returns the status of all workflows, the newest first
+func (engine *workflowEngine) list() []workflowStatus {
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+	statuses := make([]workflowStatus, 0, len(engine.runs))
+	for i := len(engine.runs) - 1; i >= 0; i-- {
+		status := engine.runs[i].status
+		status.Steps = slices.Clone(status.Steps)
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// find returns the workflow with the ID, must be called with the lock held
+func (engine *workflowEngine) find(id string) *workflowRun {
+	for _, run := range engine.runs {
+		if run.status.ID == id {
+			return run
+		}
+	}
+	return nil
+}
+
+// @Summary List workflows
+// @Description List running workflows and the latest finished ones with the state and outputs of their steps
+// @Tags workflows
+// @Produce json
+// @Success 200 {array} workflowStatus
+// @Router /api/workflows [get]
+func (server *clientsetStruct) workflowsGetHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, server.workflows.list())
+}
+
+// @Summary Get workflows UI page
+// @Description Get the page showing the state of workflows and their steps
+// @Tags ui
+// @Produce html
+// @Success 200 {string} string "HTML page"
+// @Failure 500 {string} string "Internal Server Error"
+// @Router /ui/workflows [get]
+func (server *clientsetStruct) workflowsUIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(contentType, "text/html")
+
+	tmpl, err := template.ParseFiles(
+		"web/templates/workflows.html.templ",
+		"web/templates/navbar.html.templ",
+	)
+	if err != nil {
+		log.Error("error parsing template", zap.String("error", err.Error()))
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Title      string
+		ShowSearch bool
+		Workflows  []workflowStatus
+	}{
+		Title:      "Workflows",
+		ShowSearch: false,
+		Workflows:  server.workflows.list(),
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		log.Error("error executing template", zap.String("error", err.Error()))
+		http.Error(w, "", http.StatusInternalServerError)
+	}
+}
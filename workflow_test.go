@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestWorkflowSpecValidate(t *testing.T) {
+	data := map[string]string{"diagnose": "job", "restart": "job", definitionSettingsKey: "settings"}
+	tests := []struct {
+		name    string
+		steps   []workflowStep
+		wantErr string
+	}{
+		{name: "Sequence", steps: []workflowStep{{Name: "diagnose", Job: "diagnose"}, {Name: "restart", Job: "restart", When: whenSucceeded}}},
+		{name: "DAG", steps: []workflowStep{{Name: "diagnose", Job: "diagnose"}, {Name: "restart", Job: "restart", DependsOn: []string{"diagnose"}, When: whenAlways}}},
+		{name: "No steps", wantErr: "must not be empty"},
+		{name: "Invalid name", steps: []workflowStep{{Name: "Diagnose", Job: "diagnose"}}, wantErr: "workflow.steps[0].name"},
+		{name: "Duplicate name", steps: []workflowStep{{Name: "a", Job: "diagnose"}, {Name: "a", Job: "restart"}}, wantErr: "is not unique"},
+		{name: "Unknown job", steps: []workflowStep{{Name: "a", Job: "verify"}}, wantErr: "no job definition"},
+		{name: "Settings as job", steps: []workflowStep{{Name: "a", Job: definitionSettingsKey}}, wantErr: "no job definition"},
+		{name: "Invalid when", steps: []workflowStep{{Name: "a", Job: "diagnose", When: "sometimes"}}, wantErr: "when must be"},
+		{name: "Unknown dependency", steps: []workflowStep{{Name: "a", Job: "diagnose", DependsOn: []string{"b"}}}, wantErr: "unknown step b"},
+		{name: "Self dependency", steps: []workflowStep{{Name: "a", Job: "diagnose", DependsOn: []string{"a"}}}, wantErr: "unknown step a"},
+		{
+			name:    "Cycle",
+			steps:   []workflowStep{{Name: "a", Job: "diagnose", DependsOn: []string{"b"}}, {Name: "b", Job: "restart", DependsOn: []string{"a"}}},
+			wantErr: "cycle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&workflowSpec{Steps: tt.steps}).validate(data)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validate() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStepConditionMet(t *testing.T) {
+	tests := []struct {
+		name         string
+		when         string
+		dependencies []stepState
+		want         bool
+	}{
+		{name: "First step", when: "", want: true},
+		{name: "Succeeded", when: whenSucceeded, dependencies: []stepState{stepSucceeded, stepSucceeded}, want: true},
+		{name: "Succeeded after failure", when: "", dependencies: []stepState{stepSucceeded, stepFailed}, want: false},
+		{name: "Succeeded after skipped", when: whenSucceeded, dependencies: []stepState{stepSkipped}, want: false},
+		{name: "Failed", when: whenFailed, dependencies: []stepState{stepSucceeded, stepFailed}, want: true},
+		{name: "Failed after success", when: whenFailed, dependencies: []stepState{stepSucceeded}, want: false},
+		{name: "Failed after skipped", when: whenFailed, dependencies: []stepState{stepSkipped}, want: false},
+		{name: "Always", when: whenAlways, dependencies: []stepState{stepSkipped, stepFailed}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stepConditionMet(tt.when, tt.dependencies); got != tt.want {
+				t.Errorf("stepConditionMet() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkflowRun(t *testing.T) {
+	stepJob := func(name string) string {
+		return `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: ` + name + `
+spec:
+  template:
+    spec:
+      containers:
+        - name: main
+          image: busybox
+      restartPolicy: Never
+`
+	}
+	alertStore = make([]alertStoreEntry, 0, 10)
+	configMapStore := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	if err := configMapStore.Add(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "openfero-kubequotaalmostfull-firing", Namespace: "default"},
+		Data: map[string]string{
+			"diagnose": stepJob("diagnose"),
+			"restart":  stepJob("restart"),
+			"verify":   stepJob("verify"),
+			"page":     stepJob("page"),
+			definitionSettingsKey: `workflow:
+  steps:
+    - name: diagnose
+      job: diagnose
+    - name: remediate
+      job: restart
+      dependsOn: [diagnose]
+    - name: escalate
+      job: page
+      dependsOn: [remediate]
+      when: failed
+    - name: verify
+      job: verify
+      dependsOn: [remediate]
+`,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	clientset := fake.NewClientset()
+	server := &clientsetStruct{
+		clientset:               clientset,
+		configmapNamespace:      "default",
+		jobDestinationNamespace: "default",
+		configMapStore:          configMapStore,
+		jobStore:                cache.NewStore(cache.MetaNamespaceKeyFunc),
+	}
+	server.workflows = newWorkflowEngine(server, 10)
+	server.lifecycle = newJobLifecycle(server.workflows)
+	server.lifecycle.readOutputs = server.jobOutputs
+
+	jsonFile, err := os.Open("test/alerts.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer jsonFile.Close()
+	message := hookMessage{}
+	if err := json.NewDecoder(jsonFile).Decode(&message); err != nil {
+		t.Fatal(err)
+	}
+	server.createResponseJob(context.Background(), &message, 0, "firing")
+
+	// waitForStep returns the job of the step once it was created
+	waitForStep := func(step string) *batchv1.Job {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			jobs, err := clientset.BatchV1().Jobs("default").List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range jobs.Items {
+				if jobs.Items[i].Annotations[workflowStepAnnotation] == step {
+					return &jobs.Items[i]
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("job of step %s was not created", step)
+		return nil
+	}
+	waitForState := func(want workflowState) workflowStatus {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if workflows := server.workflows.list(); len(workflows) == 1 && workflows[0].State == want {
+				return workflows[0]
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("workflow did not reach state %s: %+v", want, server.workflows.list())
+		return workflowStatus{}
+	}
+
+	diagnose := waitForStep("diagnose")
+	if _, err := clientset.CoreV1().Pods("default").Create(context.Background(), &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: diagnose.Name + "-abcde", Labels: map[string]string{batchv1.JobNameLabel: diagnose.Name}},
+		Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{
+			Name:  "main",
+			State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Message: `{"pod": "db-0", "restarts": 3}`}},
+		}}},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	server.lifecycle.finished(diagnose, jobOutcomeSucceeded, "")
+
+	// The outputs of the diagnosis are passed to the remediation
+	remediate := waitForStep("remediate")
+	env := map[string]string{}
+	for _, envVar := range remediate.Spec.Template.Spec.Containers[0].Env {
+		env[envVar.Name] = envVar.Value
+	}
+	want := map[string]string{"OPENFERO_STEP_DIAGNOSE": "succeeded", "OPENFERO_STEP_DIAGNOSE_POD": "db-0", "OPENFERO_STEP_DIAGNOSE_RESTARTS": "3"}
+	for key, value := range want {
+		if env[key] != value {
+			t.Errorf("%s = %q, want %q", key, env[key], value)
+		}
+	}
+	if remediate.Annotations[workflowAnnotation] != diagnose.Annotations[workflowAnnotation] {
+		t.Errorf("steps belong to different workflows: %s and %s", remediate.Annotations[workflowAnnotation], diagnose.Annotations[workflowAnnotation])
+	}
+
+	server.lifecycle.finished(remediate, jobOutcomeSucceeded, "")
+	verify := waitForStep("verify")
+	waitForState(workflowRunning)
+	server.lifecycle.finished(verify, jobOutcomeFailed, "BackoffLimitExceeded")
+
+	status := waitForState(workflowFailed)
+	states := map[string]stepState{}
+	for _, step := range status.Steps {
+		states[step.Name] = step.State
+	}
+	wantStates := map[string]stepState{"diagnose": stepSucceeded, "remediate": stepSucceeded, "escalate": stepSkipped, "verify": stepFailed}
+	for step, state := range wantStates {
+		if states[step] != state {
+			t.Errorf("step %s is %s, want %s", step, states[step], state)
+		}
+	}
+	if status.FinishedAt == nil || status.Steps[0].Outputs["pod"] != "db-0" {
+		t.Errorf("unexpected workflow status: %+v", status)
+	}
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/workflows", nil)
+	server.workflowsGetHandler(recorder, req)
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `"state":"failed"`) {
+		t.Errorf("GET /api/workflows returned %d: %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestWorkflowJobDeleted(t *testing.T) {
+	stepJob := `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: step
+spec:
+  template:
+    spec:
+      containers:
+        - name: main
+          image: busybox
+      restartPolicy: Never
+`
+	alertStore = make([]alertStoreEntry, 0, 10)
+	configMapStore := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	if err := configMapStore.Add(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "openfero-kubequotaalmostfull-firing", Namespace: "default"},
+		Data: map[string]string{
+			"diagnose": stepJob,
+			"page":     stepJob,
+			definitionSettingsKey: `workflow:
+  steps:
+    - name: diagnose
+      job: diagnose
+    - name: escalate
+      job: page
+      dependsOn: [diagnose]
+      when: failed
+`,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	clientset := fake.NewClientset()
+	// The API server assigns UIDs, which the deletion of jobs is tracked by
+	clientset.PrependReactor("create", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		job := action.(k8stesting.CreateAction).GetObject().(*batchv1.Job)
+		job.UID = types.UID(job.Name + "-uid")
+		return false, nil, nil
+	})
+	server := &clientsetStruct{
+		clientset:               clientset,
+		configmapNamespace:      "default",
+		jobDestinationNamespace: "default",
+		configMapStore:          configMapStore,
+		jobStore:                cache.NewStore(cache.MetaNamespaceKeyFunc),
+	}
+	server.workflows = newWorkflowEngine(server, 10)
+	server.lifecycle = newJobLifecycle(server.workflows)
+
+	message := hookMessage{Status: "firing", Alerts: []alert{{Labels: map[string]string{"alertname": "KubeQuotaAlmostFull"}}}}
+	server.createResponseJob(context.Background(), &message, 0, "firing")
+
+	workflows := server.workflows.list()
+	if len(workflows) != 1 || workflows[0].Steps[0].State != stepRunning || workflows[0].Steps[0].Job == "" {
+		t.Fatalf("workflow did not start the first step: %+v", workflows)
+	}
+	server.lifecycle.forget(types.UID(workflows[0].Steps[0].Job + "-uid"))
+
+	// The deleted step failed, so the escalation runs
+	workflows = server.workflows.list()
+	diagnose, escalate := workflows[0].Steps[0], workflows[0].Steps[1]
+	if diagnose.State != stepFailed || diagnose.Reason != "job deleted" {
+		t.Errorf("deleted step = %+v, want it failed", diagnose)
+	}
+	if escalate.State != stepRunning || escalate.Job == "" {
+		t.Fatalf("dependent step = %+v, want it running", escalate)
+	}
+	server.lifecycle.forget(types.UID(escalate.Job + "-uid"))
+	if workflows = server.workflows.list(); workflows[0].State != workflowFailed || workflows[0].FinishedAt == nil {
+		t.Errorf("workflow = %+v, want it finished as failed", workflows[0])
+	}
+	if len(server.workflows.steps) != 0 {
+		t.Errorf("steps = %v, want the jobs of the deleted steps forgotten", server.workflows.steps)
+	}
+}